@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"io"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// BackfillImages fills public_id, dimensions, bytes, format and dominant colour
+// of gallery images uploaded before those fields are stored, and perceptual hash with --hash
+func BackfillImages(args []string) error {
+	flags := flag.NewFlagSet("backfill-images", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "print changes without saving")
+	withHash := flags.Bool("hash", false, "download images to compute perceptual hash for duplicate detection")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	results, err := repositories.GalleryCollections.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer results.Close(ctx)
+
+	totalGalleries, totalImages, totalFailed := 0, 0, 0
+	for results.Next(ctx) {
+		var gallery models.GalleryModel
+		if err = results.Decode(&gallery); err != nil {
+			return err
+		}
+
+		changed := repositories.EnsureImageIds(gallery.Images)
+		for key := range gallery.Images {
+			image := &gallery.Images[key]
+			updated := false
+
+			// videos store their detail on upload and are not hashed
+			if image.Type == models.MediaVideo {
+				continue
+			}
+
+			if image.PublicID == "" || image.Width == 0 {
+				detail, err := backfillImageDetail(ctx, image)
+				if err != nil {
+					fmt.Printf("gallery %s image %s: %v\n", gallery.Id.Hex(), image.Url, err)
+					totalFailed++
+				} else if detail != nil {
+					image.PublicID = detail.PublicID
+					image.Width = detail.Width
+					image.Height = detail.Height
+					image.Bytes = detail.Bytes
+					image.Format = detail.Format
+					image.DominantColor = detail.DominantColor
+					updated = true
+				}
+			}
+
+			if *withHash && image.Hash == "" {
+				hash, err := downloadImageHash(ctx, image.Url)
+				if err != nil {
+					fmt.Printf("gallery %s image %s: %v\n", gallery.Id.Hex(), image.Url, err)
+					totalFailed++
+				} else {
+					image.Hash = hash
+					image.HashBands = utils.ImageHashBands(hash)
+					updated = true
+				}
+			}
+
+			if updated {
+				changed = true
+				totalImages++
+			}
+		}
+
+		if !changed {
+			continue
+		}
+		totalGalleries++
+
+		if *dryRun {
+			fmt.Printf("gallery %s: would update %d images\n", gallery.Id.Hex(), len(gallery.Images))
+			continue
+		}
+
+		// updated_on is not changed, backfill is not a content update
+		_, err = repositories.GalleryCollections.UpdateOne(ctx, bson.M{"_id": gallery.Id}, bson.M{"$set": bson.M{"images": gallery.Images}})
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Backfilled %d images on %d galleries, %d failed\n", totalImages, totalGalleries, totalFailed)
+	return nil
+}
+
+// backfillImageDetail gets stored image detail, nil when image is not hosted on the storage
+func backfillImageDetail(ctx context.Context, image *models.ImageModel) (*storage.Asset, error) {
+	publicID := image.PublicID
+	if publicID == "" {
+		publicID = utils.GetPublicIDFromURL(image.Url)
+	}
+	if publicID == "" {
+		return nil, nil
+	}
+
+	// keep each request short, Cloudinary admin api is rate limited
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	return utils.GetImageDetail(ctx, publicID)
+}
+
+// downloadImageHash downloads image then computes its perceptual hash
+func downloadImageHash(ctx context.Context, url string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, utils.ImageMaxBytes()))
+	if err != nil {
+		return "", err
+	}
+
+	return utils.ImageHash(data)
+}
@@ -0,0 +1,20 @@
+package commands
+
+import (
+	"fmt"
+)
+
+// Run runs one-off command by its name
+// ex: go run main.go backfill-images --dry-run
+func Run(name string, args []string) error {
+	switch name {
+	case "backfill-images":
+		return BackfillImages(args)
+	case "gc-media":
+		return GCMedia(args)
+	case "set-user-role":
+		return SetUserRole(args)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
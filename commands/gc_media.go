@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"follooow-be/repositories"
+	"follooow-be/utils"
+	"time"
+)
+
+// GCMedia deletes stored files not used by news, galleries or influencers once they are older than grace period
+// like the scheduled job it only reports orphans, unless --delete
+// ex: go run main.go gc-media --delete --grace 720h --folders /follooow,galleries
+func GCMedia(args []string) error {
+	flags := flag.NewFlagSet("gc-media", flag.ContinueOnError)
+	remove := flags.Bool("delete", false, "delete orphaned files, by default they are only printed")
+	grace := flags.Duration("grace", utils.OrphanGracePeriod(), "minimum age of deleted orphaned files")
+	folders := flags.String("folders", "", "comma separated storage folders, default ORPHAN_GC_FOLDERS")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	params := repositories.OrphanSweepParams{
+		Folders:     utils.OrphanFolders(),
+		GracePeriod: *grace,
+		DryRun:      !*remove,
+	}
+	if *folders != "" {
+		params.Folders = utils.SplitOrphanFolders(*folders)
+	}
+	if len(params.Folders) == 0 {
+		return fmt.Errorf("no folders to sweep")
+	}
+
+	sweep, err := repositories.SweepOrphanMedia(context.Background(), params)
+	if err != nil {
+		return err
+	}
+
+	expired := time.Now().Add(-params.GracePeriod).UnixNano() / int64(time.Millisecond)
+	for _, orphan := range sweep.Orphans {
+		status := "kept, within grace period"
+		switch {
+		case orphan.Deleted:
+			status = "deleted"
+		case orphan.Error != "":
+			status = "failed: " + orphan.Error
+		case orphan.CreatedOn < expired:
+			status = "would delete"
+		}
+		fmt.Printf("%s %s (%d bytes): %s\n", orphan.ResourceType, orphan.PublicID, orphan.Bytes, status)
+	}
+
+	fmt.Printf("scanned %d files, %d orphans (%d bytes), deleted %d, failed %d\n", sweep.Scanned, len(sweep.Orphans), sweep.OrphanBytes, sweep.Deleted, sweep.Failed)
+	if !*remove {
+		fmt.Println("dry run, nothing deleted")
+	}
+	return nil
+}
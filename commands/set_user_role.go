@@ -0,0 +1,29 @@
+package commands
+
+import (
+	"fmt"
+	"follooow-be/models"
+	"follooow-be/repositories"
+)
+
+// SetUserRole changes role of user, roles can't be changed by api
+// ex: go run main.go set-user-role jane contributor
+func SetUserRole(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: set-user-role <username> <admin|editor|contributor>")
+	}
+
+	username, role := args[0], args[1]
+	switch role {
+	case models.UserRoleAdmin, models.UserRoleEditor, models.UserRoleContributor:
+	default:
+		return fmt.Errorf("unknown role %q", role)
+	}
+
+	if err := repositories.SetUserRole(username, role); err != nil {
+		return fmt.Errorf("failed to set role of %s: %w", username, err)
+	}
+
+	fmt.Printf("%s is now %s\n", username, role)
+	return nil
+}
@@ -60,3 +60,318 @@ func EnvCloudinaryDir() string {
 
 	return os.Getenv("CLOUDINARY_DIR")
 }
+
+func EnvSocialFetcher() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("SOCIAL_FETCHER")
+}
+
+func EnvSocialFetcherFile() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("SOCIAL_FETCHER_FILE")
+}
+
+func EnvSocialSnapshotInterval() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("SOCIAL_SNAPSHOT_INTERVAL")
+}
+
+func EnvAuthSecret() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("AUTH_SECRET")
+}
+
+func EnvUploadConcurrency() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_CONCURRENCY")
+}
+
+func EnvUploadBudgetBase() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_BUDGET_BASE")
+}
+
+func EnvUploadBudgetPerFile() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_BUDGET_PER_FILE")
+}
+
+func EnvStorageBackend() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("STORAGE_BACKEND")
+}
+
+func EnvStorageLocalDir() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("STORAGE_LOCAL_DIR")
+}
+
+func EnvStorageLocalURL() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("STORAGE_LOCAL_URL")
+}
+
+func EnvImageMaxBytes() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("IMAGE_MAX_BYTES")
+}
+
+func EnvImageMaxDimension() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("IMAGE_MAX_DIMENSION")
+}
+
+func EnvImageMaxPixels() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("IMAGE_MAX_PIXELS")
+}
+
+func EnvImageKeepCopyright() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("IMAGE_KEEP_COPYRIGHT")
+}
+
+func EnvImageJPEGQuality() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("IMAGE_JPEG_QUALITY")
+}
+
+func EnvDuplicateImageMode() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("DUPLICATE_IMAGE_MODE")
+}
+
+func EnvDuplicateImageDistance() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("DUPLICATE_IMAGE_DISTANCE")
+}
+
+func EnvFFmpegPath() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("FFMPEG_PATH")
+}
+
+func EnvVideoMaxBytes() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("VIDEO_MAX_BYTES")
+}
+
+func EnvVideoMaxDuration() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("VIDEO_MAX_DURATION")
+}
+
+func EnvUploadBudgetPerVideo() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_BUDGET_PER_VIDEO")
+}
+
+func EnvUploadSessionDir() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_SESSION_DIR")
+}
+
+func EnvUploadSessionTTL() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_SESSION_TTL")
+}
+
+func EnvUploadChunkSize() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_CHUNK_SIZE")
+}
+
+func EnvUploadSessionCleanupInterval() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_SESSION_CLEANUP_INTERVAL")
+}
+
+func EnvOrphanGCInterval() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("ORPHAN_GC_INTERVAL")
+}
+
+func EnvOrphanGCGracePeriod() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("ORPHAN_GC_GRACE_PERIOD")
+}
+
+func EnvOrphanGCDryRun() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("ORPHAN_GC_DRY_RUN")
+}
+
+func EnvOrphanGCFolders() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("ORPHAN_GC_FOLDERS")
+}
+
+func EnvUploadNamespaces() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("UPLOAD_NAMESPACES")
+}
+
+func EnvGalleryImportMaxFiles() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("GALLERY_IMPORT_MAX_FILES")
+}
+
+func EnvGalleryImportMaxBytes() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("GALLERY_IMPORT_MAX_BYTES")
+}
+
+func EnvTrendingInterval() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("TRENDING_INTERVAL")
+}
+
+func EnvTrendingWindow() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("TRENDING_WINDOW")
+}
+
+func EnvTrendingHalfLife() string {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	return os.Getenv("TRENDING_HALF_LIFE")
+}
@@ -51,11 +51,18 @@ func ConnectDB() *mongo.Client {
 	return client
 }
 
-// Client instance
-var DB *mongo.Client = ConnectDB()
+// Client instance, connected by the first GetCollection
+// so packages which only read env, ex: tests of utils and storage, run without MongoDB
+var DB *mongo.Client
 
 // getting database collections
 func GetCollection(client *mongo.Client, collectionName string) *mongo.Collection {
+	if client == nil {
+		if DB == nil {
+			DB = ConnectDB()
+		}
+		client = DB
+	}
 	collection := client.Database(EnvMongoDB()).Collection(collectionName)
 	return collection
 }
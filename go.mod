@@ -3,7 +3,16 @@ module follooow-be
 go 1.17
 
 require (
-	github.com/cloudinary/cloudinary-go/v2 v2.14.1 // indirect
+	github.com/cloudinary/cloudinary-go/v2 v2.14.1
+	github.com/google/uuid v1.5.0
+	github.com/joho/godotenv v1.4.0
+	github.com/labstack/echo/v4 v4.9.0
+	go.mongodb.org/mongo-driver v1.10.2
+	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d
+	golang.org/x/image v0.0.0-20220902085622-e7cb96979f69
+)
+
+require (
 	github.com/creack/pty v1.1.18 // indirect
 	github.com/creasty/defaults v1.7.0 // indirect
 	github.com/fatih/color v1.13.0 // indirect
@@ -12,12 +21,9 @@ require (
 	github.com/go-playground/universal-translator v0.18.0 // indirect
 	github.com/go-playground/validator/v10 v10.11.1 // indirect
 	github.com/golang/snappy v0.0.1 // indirect
-	github.com/google/uuid v1.5.0 // indirect
 	github.com/gorilla/schema v1.4.1 // indirect
 	github.com/imdario/mergo v0.3.13 // indirect
-	github.com/joho/godotenv v1.4.0 // indirect
 	github.com/klauspost/compress v1.13.6 // indirect
-	github.com/labstack/echo/v4 v4.9.0 // indirect
 	github.com/labstack/gommon v0.3.1 // indirect
 	github.com/leodido/go-urn v1.2.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
@@ -31,8 +37,6 @@ require (
 	github.com/xdg-go/scram v1.1.1 // indirect
 	github.com/xdg-go/stringprep v1.0.3 // indirect
 	github.com/youmark/pkcs8 v0.0.0-20181117223130-1be2e3e5546d // indirect
-	go.mongodb.org/mongo-driver v1.10.2 // indirect
-	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d // indirect
 	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2 // indirect
 	golang.org/x/sync v0.0.0-20210220032951-036812b2e83c // indirect
 	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8 // indirect
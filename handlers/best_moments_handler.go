@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// handler of GET /influencers/:influencer_id/best-moments
+func ListBestMoments(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	influencer, err := repositories.GetInfluencerBestMoments(ctx, c.Param("influencer_id"))
+	if err != nil {
+		return bestMomentErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"best_moments": influencer.BestMoments}})
+}
+
+// handler of POST /influencers/:influencer_id/best-moments
+func AddBestMoment(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var payload models.PayloadBestMoment
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	if err := repositories.ValidateBestMomentStyle(payload.Style); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid style", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	influencer, err := repositories.GetInfluencerBestMoments(ctx, c.Param("influencer_id"))
+	if err != nil {
+		return bestMomentErrorResponse(c, err)
+	}
+
+	moment := models.InfluencerBestMoments{
+		Id:         primitive.NewObjectID().Hex(),
+		Text:       payload.Text,
+		Year:       payload.Year,
+		Background: payload.Background,
+		Style:      payload.Style,
+	}
+
+	moment.Image = payload.Image
+	uploaded, err := uploadBestMomentImage(ctx, influencer, moment.Id, payload.Image)
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
+	if uploaded != nil {
+		moment.Image = uploaded.URL
+	}
+
+	if err = repositories.AddBestMoment(ctx, influencer.Id, moment); err != nil {
+		discardBestMomentImage(uploaded)
+		return bestMomentErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success add best moment", Data: &echo.Map{"best_moment": moment}})
+}
+
+// handler of PUT /influencers/:influencer_id/best-moments/:moment_id
+// replace the moment, existing image is kept when image is empty
+func UpdateBestMoment(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	momentId := c.Param("moment_id")
+
+	var payload models.PayloadBestMoment
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	if err := repositories.ValidateBestMomentStyle(payload.Style); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid style", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	influencer, err := repositories.GetInfluencerBestMoments(ctx, c.Param("influencer_id"))
+	if err != nil {
+		return bestMomentErrorResponse(c, err)
+	}
+
+	var existing *models.InfluencerBestMoments
+	for key := range influencer.BestMoments {
+		if influencer.BestMoments[key].Id == momentId {
+			existing = &influencer.BestMoments[key]
+		}
+	}
+	if existing == nil {
+		return bestMomentErrorResponse(c, repositories.ErrBestMomentNotFound)
+	}
+
+	moment := models.InfluencerBestMoments{
+		Id:         momentId,
+		Image:      existing.Image,
+		Text:       payload.Text,
+		Year:       payload.Year,
+		Background: payload.Background,
+		Style:      payload.Style,
+	}
+
+	// new image gets unique name, so the existing image is kept when the moment is not saved
+	var uploaded *storage.Asset
+	if payload.Image != "" {
+		moment.Image = payload.Image
+		uploaded, err = uploadBestMomentImage(ctx, influencer, utils.UniqueFilename(momentId), payload.Image)
+		if err != nil {
+			return uploadErrorResponse(c, err)
+		}
+		if uploaded != nil {
+			moment.Image = uploaded.URL
+		}
+	}
+
+	if err = repositories.UpdateBestMoment(ctx, influencer.Id, moment); err != nil {
+		discardBestMomentImage(uploaded)
+		return bestMomentErrorResponse(c, err)
+	}
+
+	// remove replaced image unless it is reused, failure is ignored since the moment is already updated
+	if existing.Image != "" && existing.Image != moment.Image {
+		deleteUnusedMedia(ctx, existing.Image, "", models.MediaImage)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update best moment", Data: &echo.Map{"best_moment": moment}})
+}
+
+// handler of PUT /influencers/:influencer_id/best-moments/order
+// payload must contains all best moment ids on the new order
+func ReorderBestMoments(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadBestMomentsOrder
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	influencer, err := repositories.GetInfluencerBestMoments(ctx, c.Param("influencer_id"))
+	if err != nil {
+		return bestMomentErrorResponse(c, err)
+	}
+
+	moments := map[string]models.InfluencerBestMoments{}
+	for _, moment := range influencer.BestMoments {
+		moments[moment.Id] = moment
+	}
+
+	var ordered []models.InfluencerBestMoments
+	for _, id := range payload.Ids {
+		moment, ok := moments[id]
+		if !ok {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Unknown or duplicate best moment id", Data: &echo.Map{"id": id}})
+		}
+		delete(moments, id)
+		ordered = append(ordered, moment)
+	}
+
+	if len(moments) > 0 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "All best moment ids are required", Data: nil})
+	}
+
+	if err = repositories.SetBestMoments(ctx, influencer.Id, ordered); err != nil {
+		return bestMomentErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success reorder best moments", Data: &echo.Map{"best_moments": ordered}})
+}
+
+// handler of DELETE /influencers/:influencer_id/best-moments/:moment_id
+func DeleteBestMoment(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	momentId := c.Param("moment_id")
+
+	influencer, err := repositories.GetInfluencerBestMoments(ctx, c.Param("influencer_id"))
+	if err != nil {
+		return bestMomentErrorResponse(c, err)
+	}
+
+	if err = repositories.DeleteBestMoment(ctx, influencer.Id, momentId); err != nil {
+		return bestMomentErrorResponse(c, err)
+	}
+
+	// remove uploaded image unless it is reused, failure is ignored since the moment is already deleted
+	for _, moment := range influencer.BestMoments {
+		if moment.Id != momentId {
+			continue
+		}
+		deleteUnusedMedia(ctx, moment.Image, "", models.MediaImage)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success delete best moment", Data: nil})
+}
+
+// uploadBestMomentImage uploads base64 image to influencer best moments folder
+// nothing is uploaded when image is empty or url, so nil is returned
+func uploadBestMomentImage(ctx context.Context, influencer models.InfluencerModel, filename string, image string) (*storage.Asset, error) {
+	if image == "" || strings.HasPrefix(image, "http://") || strings.HasPrefix(image, "https://") {
+		return nil, nil
+	}
+
+	// Generate folder path: /follooow/influencers/slug/best-moments
+	folder := configs.EnvCloudinaryDir() + "/influencers/" + influencer.Code + "/best-moments"
+
+	result, err := utils.UploadImageFromBase64(ctx, image, folder, filename)
+	if err != nil {
+		return nil, err
+	}
+	recordMedia(ctx, result, repositories.RecordMediaParams{Directory: folder})
+
+	return result, nil
+}
+
+// discardBestMomentImage deletes uploaded image and its media record when the moment is not saved
+func discardBestMomentImage(uploaded *storage.Asset) {
+	if uploaded == nil {
+		return
+	}
+	utils.DeleteUploadedMedia([]*storage.Asset{uploaded})
+	repositories.DeleteMediaByPublicID(context.Background(), uploaded.PublicID)
+}
+
+func bestMomentErrorResponse(c echo.Context, err error) error {
+	if err == mongo.ErrNoDocuments {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Influencer not found", Data: nil})
+	}
+	if err == repositories.ErrBestMomentNotFound {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Best moment not found", Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /categories
+// categories tree for navigation, counts include news and galleries of sub categories
+func ListCategories(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	categories, err := repositories.GetCategoryTree(ctx, c.QueryParam("lang"))
+	if err != nil {
+		return categoryErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"categories": categories}})
+}
+
+// handler of GET /categories/:category_id
+// category can be found by its id or slug, breadcrumb is its parents from the top category
+func DetailCategory(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	category, breadcrumb, err := repositories.GetCategoryDetail(ctx, c.Param("category_id"), c.QueryParam("lang"))
+	if err != nil {
+		return categoryErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"category": category, "breadcrumb": breadcrumb}})
+}
+
+// handler of POST /categories
+func CreateCategory(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadCategory
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	category, err := repositories.CreateCategory(ctx, payload)
+	if err != nil {
+		return categoryErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success add category", Data: &echo.Map{"category": category}})
+}
+
+// handler of PUT /categories/:category_id
+// parent_id moves category with its sub categories
+func UpdateCategory(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadCategory
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	category, err := repositories.UpdateCategory(ctx, c.Param("category_id"), payload)
+	if err != nil {
+		return categoryErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update category", Data: &echo.Map{"category": category}})
+}
+
+// handler of DELETE /categories/:category_id
+func DeleteCategory(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := repositories.DeleteCategory(ctx, c.Param("category_id")); err != nil {
+		return categoryErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success delete category", Data: nil})
+}
+
+// categoryErrorResponse responds error of categories repository
+func categoryErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrCategoryNotFound):
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Category not found", Data: nil})
+	case errors.Is(err, repositories.ErrCategorySlugTaken), errors.Is(err, repositories.ErrCategoryInUse):
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: err.Error(), Data: nil})
+	case errors.Is(err, repositories.ErrCategoryName), errors.Is(err, repositories.ErrCategorySlug),
+		errors.Is(err, repositories.ErrCategoryParent), errors.Is(err, repositories.ErrContentCategory):
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: err.Error(), Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /collections
+func ListCollections(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	params := repositories.ListCollectionsParams{
+		Lang:   c.QueryParam("lang"),
+		Search: c.QueryParam("search"),
+		Limit:  6,
+		Page:   1,
+	}
+
+	// handling limit, by default 6
+	if c.QueryParam("limit") != "" {
+		i, err := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+		if err != nil || i < 1 {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+		}
+		params.Limit = i
+	}
+
+	// handling page, by default 1
+	if c.QueryParam("page") != "" {
+		i, err := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+		if err != nil || i < 1 {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid page", Data: nil})
+		}
+		params.Page = i
+	}
+
+	collections, total, err := repositories.ListCollections(ctx, params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	for key := range collections {
+		utils.SetCollectionRenditions(&collections[key])
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"collections": collections, "total": total}})
+}
+
+// handler of GET /collections/:collection_id
+// collection can be found by its id or slug, galleries and news are returned on collection order
+func DetailCollection(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	collection, err := repositories.GetCollection(ctx, c.Param("collection_id"))
+	if err != nil {
+		return collectionErrorResponse(c, err)
+	}
+
+	collection.Contents, err = repositories.GetCollectionContents(ctx, collection)
+	if err != nil {
+		return collectionErrorResponse(c, err)
+	}
+
+	// collection without cover uses cover of its first item
+	if collection.Cover == "" && len(collection.Contents) > 0 {
+		collection.Cover = collectionItemCover(collection.Contents[0])
+	}
+	utils.SetCollectionRenditions(&collection)
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"collection": collection}})
+}
+
+// handler of POST /collections
+func CreateCollection(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadCollection
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+	if payload.Title == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Title is required", Data: nil})
+	}
+	if payload.Lang == "" {
+		payload.Lang = "ID" // default language
+	}
+
+	collection, err := repositories.CreateCollection(ctx, payload)
+	if err != nil {
+		return collectionErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success add collection", Data: &echo.Map{"collection": collection}})
+}
+
+// handler of PUT /collections/:collection_id
+// items are kept when they are not sent
+func UpdateCollection(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadCollection
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+	if payload.Title == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Title is required", Data: nil})
+	}
+	if payload.Lang == "" {
+		payload.Lang = "ID" // default language
+	}
+
+	collection, err := repositories.UpdateCollection(ctx, c.Param("collection_id"), payload)
+	if err != nil {
+		return collectionErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update collection", Data: &echo.Map{"collection": collection}})
+}
+
+// handler of PUT /collections/:collection_id/items
+// replaces galleries and news of collection, the order of items is the order on the collection
+func SetCollectionItems(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadCollectionItems
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	collection, err := repositories.SetCollectionItems(ctx, c.Param("collection_id"), payload.Items)
+	if err != nil {
+		return collectionErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update collection items", Data: &echo.Map{"collection": collection}})
+}
+
+// handler of DELETE /collections/:collection_id
+func DeleteCollection(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := repositories.DeleteCollection(ctx, c.Param("collection_id")); err != nil {
+		return collectionErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success delete collection", Data: nil})
+}
+
+// collectionItemCover gets cover image of gallery or thumbnail of news
+func collectionItemCover(item models.TimelineItemModel) string {
+	if item.News != nil {
+		return item.News.Thumbnail
+	}
+	if item.Gallery == nil || len(item.Gallery.Images) < 1 {
+		return ""
+	}
+
+	for _, image := range item.Gallery.Images {
+		if image.IsCover {
+			return image.Url
+		}
+	}
+	return item.Gallery.Images[0].Url
+}
+
+// collectionErrorResponse responds error of collection repository
+func collectionErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrCollectionNotFound):
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Collection not found", Data: nil})
+	case errors.Is(err, repositories.ErrCollectionSlugTaken):
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: err.Error(), Data: nil})
+	case errors.Is(err, repositories.ErrCollectionSlug), errors.Is(err, repositories.ErrCollectionItem), errors.Is(err, repositories.ErrCollectionTooLarge):
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: err.Error(), Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
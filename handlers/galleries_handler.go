@@ -72,6 +72,24 @@ func ListGalleries(c echo.Context) error {
 		filterListData["influencers"] = bson.M{"$in": idsArr}
 	}
 
+	// handling filter by collection id or slug
+	if c.QueryParam("collection") != "" {
+		galleryIds, err := repositories.GetCollectionGalleryIds(ctx, c.QueryParam("collection"))
+		if err != nil {
+			return collectionErrorResponse(c, err)
+		}
+		filterListData["_id"] = bson.M{"$in": galleryIds}
+	}
+
+	// handling filter by category id or slug, including its sub categories
+	if c.QueryParam("category") != "" {
+		categoryFilter, err := repositories.CategoryContentsFilter(ctx, c.QueryParam("category"))
+		if err != nil {
+			return categoryErrorResponse(c, err)
+		}
+		filterListData["$or"] = categoryFilter["$or"]
+	}
+
 	// by default sortby last update [DONE]
 	if c.QueryParam("order_by") == "created_on" { //oldest created
 		optsListData = optsListData.SetSort(bson.D{{"created_on", 1}})
@@ -79,6 +97,8 @@ func ListGalleries(c echo.Context) error {
 		optsListData = optsListData.SetSort(bson.D{{"created_on", -1}})
 	} else if c.QueryParam("order_by") == "popular" {
 		optsListData = optsListData.SetSort(bson.D{{"views", -1}})
+	} else if c.QueryParam("order_by") == "trending" { // recent views, computed by trending job
+		optsListData = optsListData.SetSort(bson.D{{"trending_score", -1}, {"views", -1}})
 	} else {
 		optsListData = optsListData.SetSort(bson.D{{"updated_on", -1}})
 	}
@@ -170,6 +190,8 @@ func ListGalleries(c echo.Context) error {
 			fmt.Printf("DEBUG: No AuthorID found for gallery: %s\n", singleGallery.Id.Hex())
 		}
 
+		utils.SetGalleryRenditions(&singleGallery)
+		utils.SetGalleryImageLicenses(&singleGallery)
 		galleries = append(galleries, singleGallery)
 	}
 
@@ -216,6 +238,9 @@ func DetailGallery(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
 	}
 
+	// count view for trending, failure doesn't block reader
+	repositories.RecordView(ctx, models.TrendingGallery, galleryId, gallery.Lang)
+
 	// get influencers data
 
 	// convert influencers to data
@@ -280,6 +305,9 @@ func DetailGallery(c echo.Context) error {
 		fmt.Printf("DEBUG: DetailGallery - No AuthorID found for gallery: %s\n", gallery.Id.Hex())
 	}
 
+	utils.SetGalleryRenditions(&gallery)
+	utils.SetGalleryImageLicenses(&gallery)
+
 	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "OK", Data: &echo.Map{"gallery": gallery}})
 }
 
@@ -294,6 +322,21 @@ func CreateGallery(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
 	} else {
+		if err = utils.ValidateGalleryImageCredits(payload.Images); err != nil {
+			return imageCreditErrorResponse(c, err)
+		}
+		utils.SetImageHashBands(payload.Images)
+
+		// synonyms are saved with canonical tag name
+		payload.Tags, err = repositories.NormalizeTags(ctx, payload.Tags)
+		if err != nil {
+			return tagErrorResponse(c, err)
+		}
+
+		payload.CategoryID, payload.CategoryIDs, err = repositories.NormalizeContentCategories(ctx, payload.CategoryID, payload.CategoryIDs)
+		if err != nil {
+			return categoryErrorResponse(c, err)
+		}
 
 		// ref: https://stackoverflow.com/a/8689281/2780875
 		slug := strings.Replace(payload.Title, " ", "-", -1)
@@ -308,6 +351,8 @@ func CreateGallery(c echo.Context) error {
 			Lang:        payload.Lang,
 			Slug:        slug,
 			Tags:        payload.Tags,
+			CategoryID:  payload.CategoryID,
+			CategoryIDs: payload.CategoryIDs,
 		})
 
 		if errInsertGallery != nil {
@@ -319,6 +364,8 @@ func CreateGallery(c echo.Context) error {
 				"\nhttps://follooow.com/" + payload.Lang + "/gallery/" + slug + "-" + result.InsertedID.(primitive.ObjectID).Hex()
 			repositories.TelegramSendMessage(chatMessage)
 			// end of gallery news to telegram channel
+
+			refreshTagCounts(ctx, payload.Tags)
 			return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success create gallery", Data: nil})
 		}
 	}
@@ -326,9 +373,6 @@ func CreateGallery(c echo.Context) error {
 
 // handle of POST /galleries/upload - for creating gallery with image uploads
 func CreateGalleryWithUpload(c echo.Context) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	// Parse multipart form
 	form, err := c.MultipartForm()
 	if err != nil {
@@ -377,32 +421,48 @@ func CreateGalleryWithUpload(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "At least one image is required", Data: nil})
 	}
 
-	// Initialize Cloudinary if not already done
-	if configs.CloudinaryClient == nil {
-		configs.InitCloudinary()
+	credit, err := formImageCredit(c)
+	if err != nil {
+		return imageCreditErrorResponse(c, err)
+	}
+
+	// request time budget depends on number of files
+	ctx, cancel := context.WithTimeout(context.Background(), utils.UploadBudget(files))
+	defer cancel()
+
+	// synonyms are saved with canonical tag name
+	tags, err = repositories.NormalizeTags(ctx, tags)
+	if err != nil {
+		return tagErrorResponse(c, err)
+	}
+
+	categoryID, categoryIDs, err := repositories.NormalizeContentCategories(ctx, c.FormValue("category_id"), splitFormList(c.FormValue("category_ids")))
+	if err != nil {
+		return categoryErrorResponse(c, err)
 	}
 
 	// Upload images to Cloudinary
-	var images []models.ImageModel
-	for i, file := range files {
-		// Upload image
-		result, err := utils.UploadImageFromForm(ctx, file, "galleries")
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error uploading image", Data: &echo.Map{"error": err.Error()}})
-		}
+	uploaded, err := utils.UploadMediaFilesFromForm(ctx, files, "galleries")
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
 
+	var images []models.ImageModel
+	for i, result := range uploaded {
 		// Create image model
-		imageModel := models.ImageModel{
-			IsCover:   i == 0, // First image is cover
-			Url:       result.SecureURL,
-			Caption:   file.Filename,
-			CreatedOn: int(time.Now().Unix()),
-			UpdatedOn: int(time.Now().Unix()),
-		}
+		imageModel := newUploadedImage(result, files[i].Filename)
+		imageModel.ImageCreditModel = credit
+		imageModel.IsCover = i == 0 // First image is cover
 
 		images = append(images, imageModel)
 	}
 
+	// warn or reject images already uploaded to other galleries
+	duplicates, err := findUploadedDuplicates(ctx, images, uploaded, primitive.NilObjectID)
+	if err != nil {
+		return duplicateErrorResponse(c, duplicates, err)
+	}
+
 	// Generate slug
 	slug := strings.Replace(title, " ", "-", -1)
 	slug = strings.ToLower(slug)
@@ -417,16 +477,22 @@ func CreateGalleryWithUpload(c echo.Context) error {
 		Slug:        slug,
 		AuthorID:    authorID,
 		Tags:        tags,
+		CategoryID:  categoryID,
+		CategoryIDs: categoryIDs,
 	})
 
 	if err != nil {
+		utils.DeleteUploadedMedia(uploaded)
 		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error creating gallery", Data: &echo.Map{"error": err.Error()}})
 	}
 
+	recordUploadedMedia(ctx, uploaded, files, "galleries", authorID)
+	refreshTagCounts(ctx, tags)
+
 	// Post gallery to telegram channel
 	chatMessage := "New Gallery:\n" + title +
 		"\nhttps://follooow.com/" + lang + "/gallery/" + slug + "-" + result.InsertedID.(primitive.ObjectID).Hex()
 	repositories.TelegramSendMessage(chatMessage)
 
-	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success create gallery with images", Data: &echo.Map{"gallery_id": result.InsertedID}})
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success create gallery with images", Data: &echo.Map{"gallery_id": result.InsertedID, "duplicates": duplicates}})
 }
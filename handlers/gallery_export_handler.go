@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// characters not allowed on exported filename
+var exportFilenameRegex = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// handler of GET /galleries/:gallery_id/export
+// streams ZIP of original images with manifest.json, every image is copied from the storage to the response
+// so the archive is never kept in memory
+func ExportGallery(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// read only, export never gives ids to images created before images have id
+	gallery, err := repositories.GetGallery(ctx, c.Param("gallery_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	manifest, err := galleryExportManifest(ctx, gallery)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	videos := 0
+	for _, image := range gallery.Images {
+		if image.Type == models.MediaVideo {
+			videos++
+		}
+	}
+
+	// time budget depends on number of files, download stops when client is gone
+	exportCtx, exportCancel := context.WithTimeout(c.Request().Context(), utils.UploadBudgetOf(len(gallery.Images), videos))
+	defer exportCancel()
+
+	filename := exportFilename(gallery.Slug, gallery.Id.Hex())
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+	c.Response().WriteHeader(http.StatusOK)
+
+	archive := zip.NewWriter(c.Response())
+	for i, image := range gallery.Images {
+		file := exportImageFilename(i, image)
+		err = writeExportImage(exportCtx, archive, file, image)
+		if err == nil {
+			manifest.Images[i].File = file
+		} else {
+			manifest.Images[i].Error = err.Error()
+			fmt.Printf("Failed to export image %d of gallery %s: %v\n", i+1, gallery.Id.Hex(), err)
+		}
+
+		// broken entry can't be removed from the stream, so the export stops
+		if _, ok := err.(*exportWriteError); ok {
+			return err
+		}
+		if exportCtx.Err() != nil {
+			return exportCtx.Err()
+		}
+		c.Response().Flush()
+	}
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(manifest); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// exportWriteError is failure after image entry is started, the archive is broken
+type exportWriteError struct {
+	err error
+}
+
+func (e *exportWriteError) Error() string {
+	return e.err.Error()
+}
+
+// writeExportImage copies stored image to new entry of archive
+// images are already compressed, so they are stored without compression
+func writeExportImage(ctx context.Context, archive *zip.Writer, file string, image models.ImageModel) error {
+	publicID := image.PublicID
+	if publicID == "" {
+		publicID = utils.GetPublicIDFromURL(image.Url)
+	}
+	if publicID == "" {
+		return fmt.Errorf("image is not stored on the storage")
+	}
+
+	reader, err := storage.Default().Open(ctx, publicID, image.Type)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	entry, err := archive.CreateHeader(&zip.FileHeader{Name: file, Method: zip.Store, Modified: time.Unix(int64(image.CreatedOn), 0)})
+	if err != nil {
+		return &exportWriteError{err: err}
+	}
+	if _, err = io.Copy(entry, reader); err != nil {
+		return &exportWriteError{err: err}
+	}
+	return nil
+}
+
+// galleryExportManifest builds manifest of gallery with its author and influencer names
+func galleryExportManifest(ctx context.Context, gallery models.GalleryModel) (models.GalleryExportManifestModel, error) {
+	manifest := models.GalleryExportManifestModel{
+		Id:          gallery.Id.Hex(),
+		Title:       gallery.Title,
+		Description: gallery.Description,
+		Lang:        gallery.Lang,
+		Url:         "https://follooow.com/" + gallery.Lang + "/gallery/" + gallery.Slug + "-" + gallery.Id.Hex(),
+		Tags:        gallery.Tags,
+		Influencers: []string{},
+		Images:      []models.GalleryExportImageModel{},
+		ExportedOn:  time.Now().UnixNano() / int64(time.Millisecond),
+	}
+	if manifest.Tags == nil {
+		manifest.Tags = []string{}
+	}
+
+	// author of the gallery is its credit
+	if authorObjId, err := primitive.ObjectIDFromHex(gallery.AuthorID); err == nil {
+		if author, err := repositories.FindUserByID(authorObjId); err == nil {
+			manifest.Credit = author.Username
+		}
+	}
+
+	influencers, err := repositories.GetInfluencersSmallData(ctx, gallery.Influencers)
+	if err != nil {
+		return manifest, err
+	}
+	for _, influencerId := range gallery.Influencers {
+		if influencer, ok := influencers[influencerId]; ok {
+			manifest.Influencers = append(manifest.Influencers, influencer.Name)
+		}
+	}
+
+	for _, image := range gallery.Images {
+		utils.SetImageLicense(&image)
+
+		imageType := image.Type
+		if imageType == "" {
+			imageType = models.MediaImage
+		}
+
+		manifest.Images = append(manifest.Images, models.GalleryExportImageModel{
+			Id:      image.Id,
+			Type:    imageType,
+			Caption: image.Caption,
+			IsCover: image.IsCover,
+			Width:   image.Width,
+			Height:  image.Height,
+			Url:     image.Url,
+
+			ImageCreditModel: image.ImageCreditModel,
+		})
+	}
+
+	return manifest, nil
+}
+
+// exportImageFilename gets path of image inside the archive, numbered by gallery order
+// ex: images/001_summer_0b9e5f1c.jpg, image without id and public id falls back to its index, ex: images/002_image-2.jpg
+func exportImageFilename(index int, image models.ImageModel) string {
+	fallback := image.Id
+	if fallback == "" {
+		fallback = fmt.Sprintf("image-%d", index+1)
+	}
+
+	name := image.PublicID
+	if name == "" {
+		name = utils.GetPublicIDFromURL(image.Url)
+	}
+	if name == "" {
+		name = fallback
+	}
+	name = path.Base(name)
+	name = strings.TrimSuffix(name, path.Ext(name))
+
+	ext := image.Format
+	if ext == "" {
+		ext = strings.TrimPrefix(path.Ext(path.Base(image.Url)), ".")
+	}
+	if ext == "" {
+		ext = "jpg"
+	}
+
+	return fmt.Sprintf("images/%03d_%s.%s", index+1, exportFilename(name, fallback), exportFilename(ext, "jpg"))
+}
+
+// exportFilename keeps only a-z, 0-9, - and _ of name, fallback is used when nothing is left
+func exportFilename(name string, fallback string) string {
+	name = strings.Trim(exportFilenameRegex.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if name == "" {
+		return fallback
+	}
+	return name
+}
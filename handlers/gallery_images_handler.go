@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// handler of POST /galleries/:gallery_id/images
+// uploaded images appended at the end of gallery
+func AddGalleryImages(c echo.Context) error {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing multipart form", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	files := form.File["images"]
+	if len(files) == 0 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "At least one image is required", Data: nil})
+	}
+
+	credit, err := formImageCredit(c)
+	if err != nil {
+		return imageCreditErrorResponse(c, err)
+	}
+
+	// request time budget depends on number of files
+	ctx, cancel := context.WithTimeout(context.Background(), utils.UploadBudget(files))
+	defer cancel()
+
+	gallery, err := repositories.GetGalleryWithImageIds(ctx, c.Param("gallery_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	uploaded, err := utils.UploadMediaFilesFromForm(ctx, files, "galleries")
+	if err != nil {
+		return uploadErrorResponse(c, err)
+	}
+
+	var added []models.ImageModel
+	for i, result := range uploaded {
+		image := newUploadedImage(result, files[i].Filename)
+		image.ImageCreditModel = credit
+		added = append(added, image)
+	}
+
+	duplicates, err := findUploadedDuplicates(ctx, added, uploaded, primitive.NilObjectID)
+	if err != nil {
+		return duplicateErrorResponse(c, duplicates, err)
+	}
+
+	// first image become cover if gallery has no cover yet
+	isCover, err := repositories.AddGalleryImages(ctx, gallery.Id, added)
+	if err != nil {
+		utils.DeleteUploadedMedia(uploaded)
+		return galleryImageErrorResponse(c, err)
+	}
+	added[0].IsCover = isCover
+
+	recordUploadedMedia(ctx, uploaded, files, "galleries", "")
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success add gallery images", Data: &echo.Map{"images": added, "duplicates": duplicates}})
+}
+
+// handler of DELETE /galleries/:gallery_id/images/:image_id
+// image also removed from storage
+func DeleteGalleryImage(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gallery, err := repositories.GetGalleryWithImageIds(ctx, c.Param("gallery_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	// cover moves to first image when cover deleted
+	deleted, err := repositories.RemoveGalleryImage(ctx, gallery.Id, c.Param("image_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	// file reused by other content is kept
+	if err = deleteUnusedMedia(ctx, deleted.Url, deleted.PublicID, deleted.Type); err != nil {
+		// image already removed from gallery, report the failure only
+		return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Image removed from gallery, but failed to delete from storage", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success delete gallery image", Data: nil})
+}
+
+// handler of PUT /galleries/:gallery_id/images/order
+// payload must contains all image ids on the new order
+func ReorderGalleryImages(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadGalleryImagesOrder
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	gallery, err := repositories.GetGalleryWithImageIds(ctx, c.Param("gallery_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	images := map[string]bool{}
+	for _, image := range gallery.Images {
+		images[image.Id] = true
+	}
+
+	for _, id := range payload.Ids {
+		if !images[id] {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Unknown or duplicate image id", Data: &echo.Map{"id": id}})
+		}
+		delete(images, id)
+	}
+
+	if len(images) > 0 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "All image ids are required", Data: nil})
+	}
+
+	// images added or deleted since they were read make the order incomplete
+	ordered, err := repositories.ReorderGalleryImages(ctx, gallery.Id, payload.Ids)
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success reorder gallery images", Data: &echo.Map{"images": ordered}})
+}
+
+// handler of PUT /galleries/:gallery_id/images/:image_id
+func UpdateGalleryImage(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadGalleryImage
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	gallery, err := repositories.GetGalleryWithImageIds(ctx, c.Param("gallery_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	var image *models.ImageModel
+	for key := range gallery.Images {
+		if gallery.Images[key].Id == c.Param("image_id") {
+			image = &gallery.Images[key]
+		}
+	}
+	if image == nil {
+		return galleryImageErrorResponse(c, errGalleryImageNotFound)
+	}
+
+	// credit is validated with the fields which are not sent
+	credit := image.ImageCreditModel
+	if payload.Credit != nil {
+		credit.Credit = *payload.Credit
+	}
+	if payload.SourceUrl != nil {
+		credit.SourceUrl = *payload.SourceUrl
+	}
+	if payload.License != nil {
+		credit.License = *payload.License
+	}
+	if payload.LicenseExpiresOn != nil {
+		credit.LicenseExpiresOn = *payload.LicenseExpiresOn
+	}
+	utils.NormalizeImageCredit(&credit)
+	if err = utils.ValidateImageCredit(credit); err != nil {
+		return imageCreditErrorResponse(c, err)
+	}
+
+	// only fields of the image are written, so concurrent edits of other images are kept
+	fields := bson.M{
+		"credit":             credit.Credit,
+		"source_url":         credit.SourceUrl,
+		"license":            credit.License,
+		"license_expires_on": credit.LicenseExpiresOn,
+		"updatedon":          int(time.Now().Unix()),
+	}
+	if payload.Caption != nil {
+		fields["caption"] = *payload.Caption
+	}
+
+	updated, err := repositories.UpdateGalleryImage(ctx, gallery.Id, image.Id, fields)
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+	utils.SetImageLicense(&updated)
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update gallery image", Data: &echo.Map{"image": updated}})
+}
+
+// handler of PUT /galleries/:gallery_id/images/:image_id/cover
+func SetGalleryCover(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	gallery, err := repositories.GetGalleryWithImageIds(ctx, c.Param("gallery_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	if err = repositories.SetGalleryCover(ctx, gallery.Id, c.Param("image_id")); err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success set gallery cover", Data: nil})
+}
+
+// newUploadedImage creates gallery image of uploaded asset
+func newUploadedImage(result *storage.Asset, caption string) models.ImageModel {
+	image := models.ImageModel{
+		Id:            primitive.NewObjectID().Hex(),
+		PublicID:      result.PublicID,
+		Url:           result.URL,
+		Width:         result.Width,
+		Height:        result.Height,
+		Bytes:         result.Bytes,
+		Format:        result.Format,
+		DominantColor: result.DominantColor,
+		Type:          models.MediaImage,
+		Hash:          result.Hash,
+		HashBands:     utils.ImageHashBands(result.Hash),
+		Caption:       caption,
+		CreatedOn:     int(time.Now().Unix()),
+		UpdatedOn:     int(time.Now().Unix()),
+	}
+
+	if result.ResourceType == storage.ResourceVideo {
+		image.Type = models.MediaVideo
+		image.Duration = result.Duration
+		image.Poster = result.PosterURL
+	}
+
+	return image
+}
+
+// formImageCredit reads credit of uploaded images from form, every uploaded image gets the same credit
+func formImageCredit(c echo.Context) (models.ImageCreditModel, error) {
+	credit := models.ImageCreditModel{
+		Credit:    c.FormValue("credit"),
+		SourceUrl: c.FormValue("source_url"),
+		License:   c.FormValue("license"),
+	}
+
+	if value := c.FormValue("license_expires_on"); value != "" {
+		expiresOn, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return credit, utils.ErrImageLicenseDate
+		}
+		credit.LicenseExpiresOn = expiresOn
+	}
+
+	utils.NormalizeImageCredit(&credit)
+	return credit, utils.ValidateImageCredit(credit)
+}
+
+func imageCreditErrorResponse(c echo.Context, err error) error {
+	if utils.IsImageCreditError(err) {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid image credit", Data: &echo.Map{"error": err.Error()}})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
+
+// findUploadedDuplicates finds stored near duplicates of uploaded images
+// uploaded images are deleted when duplicate is found on reject mode
+func findUploadedDuplicates(ctx context.Context, images []models.ImageModel, uploaded []*storage.Asset, excludeGalleryId primitive.ObjectID) ([]models.DuplicateImageModel, error) {
+	duplicates := []models.DuplicateImageModel{}
+	if utils.DuplicateImageMode() == utils.DuplicateImageOff {
+		return duplicates, nil
+	}
+
+	for _, image := range images {
+		found, err := repositories.FindDuplicateImages(ctx, image.Hash, excludeGalleryId)
+		if err != nil {
+			utils.DeleteUploadedMedia(uploaded)
+			return nil, err
+		}
+		for key := range found {
+			found[key].DuplicateOf = image.Id
+		}
+		duplicates = append(duplicates, found...)
+	}
+
+	if len(duplicates) > 0 && utils.DuplicateImageMode() == utils.DuplicateImageReject {
+		utils.DeleteUploadedMedia(uploaded)
+		return duplicates, errDuplicateImage
+	}
+
+	return duplicates, nil
+}
+
+// duplicateErrorResponse responds error of findUploadedDuplicates
+func duplicateErrorResponse(c echo.Context, duplicates []models.DuplicateImageModel, err error) error {
+	if err == errDuplicateImage {
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: "Duplicate image already exists", Data: &echo.Map{"duplicates": duplicates}})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error checking duplicate images", Data: &echo.Map{"error": err.Error()}})
+}
+
+var errGalleryImageNotFound = repositories.ErrGalleryImageNotFound
+var errDuplicateImage = errors.New("duplicate image already exists")
+
+func galleryImageErrorResponse(c echo.Context, err error) error {
+	if err == mongo.ErrNoDocuments {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Gallery not found", Data: nil})
+	}
+	if err == errGalleryImageNotFound {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Image not found", Data: nil})
+	}
+	if err == repositories.ErrGalleryImagesChanged {
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: err.Error(), Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
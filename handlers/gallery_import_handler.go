@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handler of POST /galleries/import
+// creates gallery from images of ZIP archive, images are uploaded in background
+// progress is polled on GET /galleries/import/:import_id
+func ImportGallery(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	title := c.FormValue("title")
+	lang := c.FormValue("lang")
+	order := c.FormValue("order")
+
+	if title == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Title is required", Data: nil})
+	}
+	if lang == "" {
+		lang = "ID" // default language
+	}
+	if order == "" {
+		order = utils.ArchiveOrderFilename
+	}
+	if order != utils.ArchiveOrderFilename && order != utils.ArchiveOrderExif {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid order, allowed: filename, exif", Data: nil})
+	}
+
+	// every imported image gets the same credit
+	credit, err := formImageCredit(c)
+	if err != nil {
+		return imageCreditErrorResponse(c, err)
+	}
+
+	// synonyms are saved with canonical tag name
+	tags, err := repositories.NormalizeTags(ctx, splitFormList(c.FormValue("tags")))
+	if err != nil {
+		return tagErrorResponse(c, err)
+	}
+
+	categoryID, categoryIDs, err := repositories.NormalizeContentCategories(ctx, c.FormValue("category_id"), splitFormList(c.FormValue("category_ids")))
+	if err != nil {
+		return categoryErrorResponse(c, err)
+	}
+
+	fileHeader, err := c.FormFile("archive")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Archive is required", Data: nil})
+	}
+	if max := utils.GalleryImportMaxBytes(); fileHeader.Size > max {
+		return c.JSON(http.StatusRequestEntityTooLarge, responses.GlobalResponse{Status: http.StatusRequestEntityTooLarge, Message: utils.ErrArchiveTooLarge.Error(), Data: &echo.Map{"max_size": max}})
+	}
+
+	// form file is removed after the request, so the archive is copied for the background import
+	archivePath, err := saveImportArchive(fileHeader)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error saving archive", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	// entries are checked before responding, so invalid archive is rejected right away
+	archive, err := utils.OpenGalleryArchive(archivePath)
+	if err != nil {
+		os.Remove(archivePath)
+		return archiveErrorResponse(c, err)
+	}
+
+	job, err := repositories.CreateGalleryImport(ctx, fileHeader.Filename, title, order, len(archive.Images))
+	if err != nil {
+		archive.Close()
+		os.Remove(archivePath)
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	params := repositories.CreateGalleryParams{
+		Title:       title,
+		Description: c.FormValue("description"),
+		Influencers: splitFormList(c.FormValue("influencers")),
+		Lang:        lang,
+		Slug:        strings.ToLower(strings.Replace(title, " ", "-", -1)),
+		AuthorID:    c.FormValue("author_id"),
+		Tags:        tags,
+		CategoryID:  categoryID,
+		CategoryIDs: categoryIDs,
+	}
+	go importGallery(job, archive, archivePath, params, credit)
+
+	return c.JSON(http.StatusAccepted, responses.GlobalResponse{Status: http.StatusAccepted, Message: "Gallery import started", Data: &echo.Map{"import": job}})
+}
+
+// handler of GET /galleries/import/:import_id
+// status and progress of gallery import, gallery_id is set once it is completed
+func DetailGalleryImport(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job, err := repositories.GetGalleryImport(ctx, c.Param("import_id"))
+	if err != nil {
+		if err == repositories.ErrGalleryImportNotFound {
+			return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Import not found", Data: nil})
+		}
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"import": job}})
+}
+
+// importGallery uploads images of archive then creates the gallery, it runs after the request is responded
+// images failed to upload are skipped, the import fails when no image is uploaded
+func importGallery(job models.GalleryImportModel, archive *utils.GalleryArchive, archivePath string, params repositories.CreateGalleryParams, credit models.ImageCreditModel) {
+	defer os.Remove(archivePath)
+	defer archive.Close()
+
+	// keep server alive if import panic
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Gallery import %s panic: %v\n", job.Id.Hex(), r)
+			failGalleryImport(job.Id, fmt.Sprintf("%v", r), nil)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), utils.UploadBudgetOf(len(archive.Images), 0))
+	defer cancel()
+
+	if err := repositories.SetGalleryImportStatus(ctx, job.Id, models.GalleryImportRunning); err != nil {
+		fmt.Printf("Failed to start gallery import %s: %v\n", job.Id.Hex(), err)
+	}
+
+	archive.SortImages(job.Order)
+	results := uploadArchiveImages(ctx, job.Id, archive)
+
+	// budget exceeded, gallery with part of the images is not created
+	if ctx.Err() != nil {
+		utils.DeleteUploadedMedia(results)
+		failGalleryImport(job.Id, fmt.Sprintf("upload budget exceeded: %v", ctx.Err()), nil)
+		return
+	}
+
+	var uploaded []*storage.Asset
+	var names []string
+	var images []models.ImageModel
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+
+		name := archive.Images[i].Name
+		caption := archive.Caption(archive.Images[i])
+		if caption == "" {
+			caption = name
+		}
+
+		image := newUploadedImage(result, caption)
+		image.ImageCreditModel = credit
+		image.IsCover = len(images) == 0 // First image is cover
+
+		uploaded = append(uploaded, result)
+		names = append(names, name)
+		images = append(images, image)
+	}
+	if len(images) == 0 {
+		failGalleryImport(job.Id, "no image was imported", nil)
+		return
+	}
+
+	// warn or reject images already uploaded to other galleries
+	duplicates, err := findUploadedDuplicates(ctx, images, uploaded, primitive.NilObjectID)
+	if err != nil {
+		failGalleryImport(job.Id, err.Error(), duplicates)
+		return
+	}
+
+	params.Images = images
+	result, err := repositories.CreateGallery(ctx, params)
+	if err != nil {
+		utils.DeleteUploadedMedia(uploaded)
+		failGalleryImport(job.Id, "Error creating gallery: "+err.Error(), nil)
+		return
+	}
+	galleryId := result.InsertedID.(primitive.ObjectID).Hex()
+
+	for i, asset := range uploaded {
+		recordMedia(ctx, asset, repositories.RecordMediaParams{Directory: "galleries", Filename: names[i], UploaderID: params.AuthorID})
+	}
+	refreshTagCounts(ctx, params.Tags)
+
+	// Post gallery to telegram channel
+	chatMessage := "New Gallery:\n" + params.Title +
+		"\nhttps://follooow.com/" + params.Lang + "/gallery/" + params.Slug + "-" + galleryId
+	repositories.TelegramSendMessage(chatMessage)
+
+	if err = repositories.CompleteGalleryImport(ctx, job.Id, galleryId, duplicates); err != nil {
+		fmt.Printf("Failed to complete gallery import %s: %v\n", job.Id.Hex(), err)
+	}
+}
+
+// uploadArchiveImages uploads images of archive concurrently, results have the same order as the images
+// result of failed image is nil, progress is saved after every image
+func uploadArchiveImages(ctx context.Context, importId primitive.ObjectID, archive *utils.GalleryArchive) []*storage.Asset {
+	results := make([]*storage.Asset, len(archive.Images))
+
+	sem := make(chan struct{}, utils.UploadConcurrency())
+	var wg sync.WaitGroup
+
+	for i := range archive.Images {
+		sem <- struct{}{}
+
+		// skip remaining images when budget is exceeded
+		if ctx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			image := archive.Images[i]
+			result, err := uploadArchiveImage(ctx, archive, image)
+
+			var failure *models.ImportFailureModel
+			if err != nil {
+				failure = &models.ImportFailureModel{Filename: image.Name, Error: err.Error()}
+			} else {
+				results[i] = result
+			}
+
+			if err = repositories.AddGalleryImportProgress(ctx, importId, failure); err != nil {
+				fmt.Printf("Failed to save gallery import %s progress: %v\n", importId.Hex(), err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func uploadArchiveImage(ctx context.Context, archive *utils.GalleryArchive, image *utils.ArchiveImage) (*storage.Asset, error) {
+	reader, err := archive.Open(image)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return utils.UploadImageFromReader(ctx, reader, image.Name, "galleries")
+}
+
+// failGalleryImport marks import as failed, it has its own timeout since the import context may already be expired
+func failGalleryImport(importId primitive.ObjectID, reason string, duplicates []models.DuplicateImageModel) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := repositories.FailGalleryImport(ctx, importId, reason, duplicates); err != nil {
+		fmt.Printf("Failed to save gallery import %s failure: %v\n", importId.Hex(), err)
+	}
+}
+
+// saveImportArchive copies uploaded archive to temp file, returns path of the file
+func saveImportArchive(fileHeader *multipart.FileHeader) (string, error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "follooow-import-*.zip")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+// splitFormList splits comma separated form value, ex: influencers or tags
+func splitFormList(value string) []string {
+	if value == "" {
+		return nil
+	}
+
+	list := strings.Split(value, ",")
+	for i, item := range list {
+		list[i] = strings.TrimSpace(item)
+	}
+	return list
+}
+
+// archiveErrorResponse responds error of opening gallery archive
+// 413 for too large archive or image, 400 for invalid archive
+func archiveErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, utils.ErrArchiveTooLarge), errors.Is(err, utils.ErrArchiveTooManyFiles), errors.Is(err, utils.ErrImageTooLarge):
+		return c.JSON(http.StatusRequestEntityTooLarge, responses.GlobalResponse{Status: http.StatusRequestEntityTooLarge, Message: err.Error(), Data: nil})
+	case errors.Is(err, utils.ErrArchiveInvalid), errors.Is(err, utils.ErrArchiveUnsafePath),
+		errors.Is(err, utils.ErrArchiveNoImages), errors.Is(err, utils.ErrArchiveCaptions):
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: err.Error(), Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
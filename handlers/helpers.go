@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// queryInt parses integer query param, fallback to default value if empty
+func queryInt(c echo.Context, name string, fallback int64) (int64, error) {
+	if c.QueryParam(name) == "" {
+		return fallback, nil
+	}
+
+	return strconv.ParseInt(c.QueryParam(name), 10, 64)
+}
+
+// uploadErrorResponse reports failed files of batch upload
+func uploadErrorResponse(c echo.Context, err error) error {
+	status := imageErrorStatus(err)
+
+	var batchErr *utils.UploadBatchError
+	if errors.As(err, &batchErr) {
+		return c.JSON(status, responses.GlobalResponse{Status: status, Message: "Error uploading file", Data: &echo.Map{"error": err.Error(), "files": batchErr.Failures}})
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return c.JSON(http.StatusGatewayTimeout, responses.GlobalResponse{Status: http.StatusGatewayTimeout, Message: "Upload time budget exceeded", Data: &echo.Map{"error": err.Error()}})
+	}
+	return c.JSON(status, responses.GlobalResponse{Status: status, Message: "Error uploading file", Data: &echo.Map{"error": err.Error()}})
+}
+
+// imageErrorStatus gets http status of upload error
+// 413 for too large file, dimensions or duration, 415 for not allowed or unreadable image or video
+func imageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, utils.ErrImageTooLarge), errors.Is(err, utils.ErrImageDimensions),
+		errors.Is(err, utils.ErrVideoTooLarge), errors.Is(err, utils.ErrVideoDuration):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, utils.ErrImageType), errors.Is(err, utils.ErrImageUnreadable),
+		errors.Is(err, utils.ErrMediaType), errors.Is(err, utils.ErrVideoUnreadable):
+		return http.StatusUnsupportedMediaType
+	default:
+		return http.StatusInternalServerError
+	}
+}
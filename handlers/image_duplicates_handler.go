@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /admin/images/duplicates
+// every near duplicate gallery images grouped as cluster, largest cluster first
+func DuplicateImagesReport(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	clusters, err := repositories.GetDuplicateClusters(ctx)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"clusters": clusters, "total": len(clusters)}})
+}
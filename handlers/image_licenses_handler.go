@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /admin/images/licenses
+// gallery images which license expires within days (default 30), expired images are included to be taken down
+func ExpiringImageLicensesReport(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// handling days, by default 30
+	days := 30
+	if c.QueryParam("days") != "" {
+		i, err := strconv.Atoi(c.QueryParam("days"))
+		if err != nil || i < 0 || i > 3650 {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid days", Data: nil})
+		}
+		days = i
+	}
+
+	images, err := repositories.FindExpiringImageLicenses(ctx, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	expired := 0
+	for _, image := range images {
+		if image.LicenseExpired {
+			expired++
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"images": images, "total": len(images), "expired": expired}})
+}
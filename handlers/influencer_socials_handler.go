@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// max accounts of social leaderboard
+const socialLeaderboardMaxLimit = 50
+
+// handler of GET /influencers/:influencer_id/socials/growth
+func GrowthInfluencerSocials(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	influencerId := c.Param("influencer_id")
+
+	// handling period in days, by default 30
+	days, err := queryInt(c, "days", 30)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	since := time.Now().AddDate(0, 0, -int(days)).UnixNano() / int64(time.Millisecond)
+
+	growths, err := repositories.GetSocialGrowth(ctx, influencerId, since)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"socials": growths, "since": since}})
+}
+
+// handler of GET /influencers/socials/leaderboard
+func LeaderboardInfluencerSocials(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// handling period in days, by default 7
+	days, err := queryInt(c, "days", 7)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	// handling limit, by default 10
+	limit, err := queryInt(c, "limit", 10)
+	if err != nil || limit < 1 || limit > socialLeaderboardMaxLimit {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+	}
+
+	leaderboard, err := repositories.GetSocialLeaderboard(ctx, repositories.SocialLeaderboardParams{
+		Type:    c.QueryParam("type"),
+		Since:   time.Now().AddDate(0, 0, -int(days)).UnixNano() / int64(time.Millisecond),
+		Limit:   limit,
+		OrderBy: c.QueryParam("order_by"),
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"leaderboard": leaderboard}})
+}
+
+// handler of POST /influencers/:influencer_id/socials/refresh
+func RefreshInfluencerSocials(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fetcher, err := utils.NewSocialFetcher()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+	if fetcher == nil {
+		return c.JSON(http.StatusServiceUnavailable, responses.GlobalResponse{Status: http.StatusServiceUnavailable, Message: "Social fetcher is not configured", Data: nil})
+	}
+
+	total, err := repositories.RefreshInfluencerSocialSnapshots(ctx, fetcher, c.Param("influencer_id"))
+	if err == repositories.ErrInfluencerNotFound {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Influencer not found", Data: nil})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"total": total}})
+}
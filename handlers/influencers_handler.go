@@ -93,6 +93,8 @@ func ListInfluencers(c echo.Context) error {
 		optsListData = optsListData.SetSort(bson.D{{"created_on", -1}})
 	} else if c.QueryParam("order_by") == "popular" {
 		optsListData = optsListData.SetSort(bson.D{{"visits", -1}})
+	} else if c.QueryParam("order_by") == "trending" { // recent views, computed by trending job
+		optsListData = optsListData.SetSort(bson.D{{"trending_score", -1}, {"visits", -1}})
 	} else {
 		optsListData = optsListData.SetSort(bson.D{{"updated_on", -1}})
 	}
@@ -122,6 +124,7 @@ func ListInfluencers(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
 		}
 
+		utils.SetInfluencerRenditions(&singleInfluencer)
 		influencers = append(influencers, singleInfluencer)
 	}
 
@@ -152,6 +155,8 @@ func DetailInfluencers(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
 	}
 
+	utils.SetInfluencerRenditions(&result)
+
 	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "OK", Data: &echo.Map{"influencer": result}})
 }
 
@@ -196,6 +201,7 @@ func QuickFindInfluencers(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
 		}
 
+		singleInfluencer.AvatarRenditions = utils.ImageRenditions(singleInfluencer.Avatar, 0, 0)
 		influencers = append(influencers, singleInfluencer)
 	}
 
@@ -216,6 +222,14 @@ func AddInfluencer(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: err.Error(), Data: nil})
 	}
 
+	// validate best moments and give each moment an id
+	for key := range payload.BestMoments {
+		if err := repositories.ValidateBestMomentStyle(payload.BestMoments[key].Style); err != nil {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid best moment style", Data: &echo.Map{"error": err.Error()}})
+		}
+		payload.BestMoments[key].Id = primitive.NewObjectID().Hex()
+	}
+
 	// Initialize Cloudinary if not already done
 	if configs.CloudinaryClient == nil {
 		configs.InitCloudinary()
@@ -230,9 +244,10 @@ func AddInfluencer(c echo.Context) error {
 
 		result, err := utils.UploadImageFromBase64(ctx, payload.Avatar, folder, filename)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error uploading avatar", Data: &echo.Map{"error": err.Error()}})
+			return c.JSON(imageErrorStatus(err), responses.GlobalResponse{Status: imageErrorStatus(err), Message: "Error uploading avatar", Data: &echo.Map{"error": err.Error()}})
 		}
-		avatarURL = result.SecureURL
+		recordMedia(ctx, result, repositories.RecordMediaParams{Directory: folder})
+		avatarURL = result.URL
 	}
 
 	new_data := bson.D{
@@ -299,6 +314,7 @@ func UpdateInfluencer(c echo.Context) error {
 
 	// Handle avatar upload if base64 is provided
 	var avatarURL string
+	var avatarPublicID string
 	if avatarData, ok := payload["avatar"].(string); ok && avatarData != "" {
 		// Get slug for folder path, use existing slug if not provided
 		slug := ""
@@ -314,9 +330,11 @@ func UpdateInfluencer(c echo.Context) error {
 
 		result, err := utils.UploadImageFromBase64(ctx, avatarData, folder, filename)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error uploading avatar", Data: &echo.Map{"error": err.Error()}})
+			return c.JSON(imageErrorStatus(err), responses.GlobalResponse{Status: imageErrorStatus(err), Message: "Error uploading avatar", Data: &echo.Map{"error": err.Error()}})
 		}
-		avatarURL = result.SecureURL
+		recordMedia(ctx, result, repositories.RecordMediaParams{Directory: folder})
+		avatarURL = result.URL
+		avatarPublicID = result.PublicID
 	} else {
 		// Use existing avatar if no new avatar provided
 		avatarURL = influencer.Avatar
@@ -333,9 +351,43 @@ func UpdateInfluencer(c echo.Context) error {
 		{"updated_on", time.Now().UnixNano() / int64(time.Millisecond)},
 		{"nationality", payload["nationality"]},
 		{"gender", payload["gender"]},
-		{"socials", payload["socials"]},
 		{"label", payload["label"]},
-		{"best_moments", payload["best_moments"]},
+	}
+
+	// best moments replace the list when sent, like /influencers/:influencer_id/best-moments they are validated
+	// moment keeps its id when it is an existing moment, otherwise it gets a new id
+	if _, ok := payload["best_moments"]; ok {
+		var moments []models.InfluencerBestMoments
+		raw, _ := json.Marshal(payload["best_moments"])
+		if err = json.Unmarshal(raw, &moments); err != nil {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid best moments", Data: &echo.Map{"error": err.Error()}})
+		}
+
+		existing := map[string]bool{}
+		for _, moment := range influencer.BestMoments {
+			existing[moment.Id] = true
+		}
+		for key := range moments {
+			if err := repositories.ValidateBestMomentStyle(moments[key].Style); err != nil {
+				return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid best moment style", Data: &echo.Map{"error": err.Error()}})
+			}
+			if moments[key].Id == "" || !existing[moments[key].Id] {
+				moments[key].Id = primitive.NewObjectID().Hex()
+			}
+			// id of duplicated moment is used once
+			delete(existing, moments[key].Id)
+		}
+		new_data = append(new_data, bson.E{"best_moments", moments})
+	}
+
+	// socials are kept when not sent, their followers stats are kept from the social snapshots job
+	if _, ok := payload["socials"]; ok {
+		var socials []models.InfluencerSocial
+		raw, _ := json.Marshal(payload["socials"])
+		if err = json.Unmarshal(raw, &socials); err != nil {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid socials", Data: &echo.Map{"error": err.Error()}})
+		}
+		new_data = append(new_data, bson.E{"socials", utils.KeepSocialStats(influencer.Socials, socials)})
 	}
 
 	update := bson.D{{"$set", new_data}}
@@ -344,6 +396,11 @@ func UpdateInfluencer(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error update database", Data: &echo.Map{"error": err.Error()}})
 	} else {
+		// replaced avatar is removed unless it is reused, failure is ignored since the influencer is already updated
+		// avatar uploaded with the same slug overwrites the old file, so it is kept
+		if avatarPublicID != "" && utils.GetPublicIDFromURL(influencer.Avatar) != avatarPublicID {
+			deleteUnusedMedia(ctx, influencer.Avatar, "", models.MediaImage)
+		}
 		return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update influencer", Data: nil})
 	}
 }
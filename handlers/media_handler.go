@@ -2,13 +2,15 @@ package handlers
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net/http"
-	"strings"
 	"time"
 
 	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/repositories"
 	"follooow-be/responses"
+	"follooow-be/storage"
 	"follooow-be/utils"
 
 	"github.com/labstack/echo/v4"
@@ -16,11 +18,12 @@ import (
 
 // MediaUploadPayload represents the request payload for media upload
 type MediaUploadPayload struct {
-	File      string `json:"file" validate:"required"`
-	Directory string `json:"directory" validate:"required"`
+	File      string   `json:"file" validate:"required"`
+	Directory string   `json:"directory" validate:"required"`
+	Tags      []string `json:"tags,omitempty"`
 }
 
-// UploadMedia handles single file upload from base64 data
+// UploadMedia handles single image or video upload from base64 data
 func UploadMedia(c echo.Context) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -51,37 +54,98 @@ func UploadMedia(c echo.Context) error {
 		})
 	}
 
-	// Initialize Cloudinary client if not already done
-	if configs.CloudinaryClient == nil {
-		configs.InitCloudinary()
+	userId, role := currentUser(c)
+
+	// directory must be inside namespace allowed for the user role
+	directory, err := utils.AuthorizeUploadDirectory(role, payload.Directory)
+	if err != nil {
+		return uploadDirectoryErrorResponse(c, err)
 	}
 
 	// Construct the full directory path
-	fullDirectory := configs.EnvCloudinaryDir() + "/" + strings.TrimPrefix(payload.Directory, "/")
+	fullDirectory := mediaDirectory(directory)
 
 	// Generate unique filename
-	filename := fmt.Sprintf("media_%d", time.Now().Unix())
+	filename := utils.UniqueFilename("media")
 
-	// Upload the base64 image to Cloudinary
-	result, err := utils.UploadImageFromBase64(ctx, payload.File, fullDirectory, filename)
+	// Upload the base64 image or video to the storage
+	result, err := utils.UploadMediaFromBase64(ctx, payload.File, fullDirectory, filename)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Error uploading file to Cloudinary",
+		status := imageErrorStatus(err)
+		return c.JSON(status, responses.GlobalResponse{
+			Status:  status,
+			Message: "Error uploading file",
 			Data:    &echo.Map{"error": err.Error()},
 		})
 	}
 
+	media := uploadedMedia(result, fullDirectory)
+	media.MediaID = recordMedia(ctx, result, repositories.RecordMediaParams{Directory: fullDirectory, UploaderID: userId, Tags: payload.Tags})
+
+	data := uploadedMediaData(media)
+
 	// Return success response with CDN URL
 	return c.JSON(http.StatusOK, responses.GlobalResponse{
 		Status:  http.StatusOK,
 		Message: "File uploaded successfully",
-		Data: &echo.Map{
-			"url":       result.SecureURL,
-			"public_id": result.PublicID,
-			"format":    result.Format,
-			"size":      result.Bytes,
-			"directory": fullDirectory,
-		},
+		Data:    &data,
 	})
 }
+
+// mediaDirectory gets storage folder of normalized media directory, ex: news -> /follooow/news
+func mediaDirectory(directory string) string {
+	return configs.EnvCloudinaryDir() + "/" + directory
+}
+
+// currentUser gets id and role of user logged in by UserAuth middleware
+func currentUser(c echo.Context) (string, string) {
+	userId, _ := c.Get("user_id").(string)
+	role, _ := c.Get("user_role").(string)
+	return userId, role
+}
+
+// uploadDirectoryErrorResponse responds error of utils.AuthorizeUploadDirectory
+func uploadDirectoryErrorResponse(c echo.Context, err error) error {
+	if errors.Is(err, utils.ErrUploadForbidden) {
+		return c.JSON(http.StatusForbidden, responses.GlobalResponse{Status: http.StatusForbidden, Message: "Upload to this directory is not allowed", Data: &echo.Map{"error": err.Error()}})
+	}
+	return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid directory", Data: &echo.Map{"error": err.Error()}})
+}
+
+// uploadedMedia is detail of uploaded media returned to client
+func uploadedMedia(result *storage.Asset, directory string) models.UploadedMediaModel {
+	media := models.UploadedMediaModel{
+		Type:      models.MediaImage,
+		Url:       result.URL,
+		PublicID:  result.PublicID,
+		Format:    result.Format,
+		Size:      result.Bytes,
+		Directory: directory,
+	}
+	if result.ResourceType == storage.ResourceVideo {
+		media.Type = models.MediaVideo
+		media.Duration = result.Duration
+		media.Poster = result.PosterURL
+		media.Playback = utils.VideoPlayback(result.URL)
+	}
+	return media
+}
+
+// uploadedMediaData is response data of uploaded media, video fields are only added to video
+func uploadedMediaData(media models.UploadedMediaModel) echo.Map {
+	data := echo.Map{
+		"media_id":  media.MediaID,
+		"type":      media.Type,
+		"url":       media.Url,
+		"public_id": media.PublicID,
+		"format":    media.Format,
+		"size":      media.Size,
+		"directory": media.Directory,
+	}
+	if media.Type == models.MediaVideo {
+		data["duration"] = media.Duration
+		data["poster"] = media.Poster
+		data["playback"] = media.Playback
+	}
+	return data
+}
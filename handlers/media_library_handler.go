@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// handler of GET /api/media
+// search media library to reuse uploaded file, newest first
+func ListMedia(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	params := repositories.ListMediaParams{
+		Search:     c.QueryParam("search"),
+		Type:       c.QueryParam("type"),
+		Tag:        c.QueryParam("tag"),
+		UploaderID: c.QueryParam("uploader_id"),
+		Limit:      6,
+		Page:       1,
+	}
+	if c.QueryParam("directory") != "" {
+		directory, err := utils.NormalizeUploadDirectory(c.QueryParam("directory"))
+		if err != nil {
+			return uploadDirectoryErrorResponse(c, err)
+		}
+		params.Directory = mediaDirectory(directory)
+	}
+
+	// handling limit, by default 6
+	if c.QueryParam("limit") != "" {
+		i, err := strconv.ParseInt(c.QueryParam("limit"), 10, 64)
+		if err != nil || i < 1 {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+		}
+		params.Limit = i
+	}
+
+	// handling page, by default 1
+	if c.QueryParam("page") != "" {
+		i, err := strconv.ParseInt(c.QueryParam("page"), 10, 64)
+		if err != nil || i < 1 {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid page", Data: nil})
+		}
+		params.Page = i
+	}
+
+	medias, total, err := repositories.ListMedia(ctx, params)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	for key := range medias {
+		utils.SetMediaRenditions(&medias[key])
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"media": medias, "total": total}})
+}
+
+// handler of GET /api/media/:media_id
+// media is returned with its uploader and contents using it
+func DetailMedia(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	media, err := repositories.GetMedia(ctx, c.Param("media_id"))
+	if err != nil {
+		return mediaErrorResponse(c, err)
+	}
+
+	media.Usages, err = repositories.FindMediaUsages(ctx, media)
+	if err != nil {
+		return mediaErrorResponse(c, err)
+	}
+
+	// get uploader information if uploader_id exists
+	if uploaderObjId, err := primitive.ObjectIDFromHex(media.UploaderID); err == nil {
+		var uploader models.UserModel
+		if err = repositories.UsersCollections.FindOne(ctx, bson.M{"_id": uploaderObjId}).Decode(&uploader); err == nil {
+			media.Uploader = &models.AuthorModel{ID: uploader.ID.Hex(), Username: uploader.Username}
+		}
+	}
+
+	utils.SetMediaRenditions(&media)
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"media": media}})
+}
+
+// handler of PUT /api/media/:media_id
+// replaces tags of media
+func UpdateMedia(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	media, err := repositories.GetMedia(ctx, c.Param("media_id"))
+	if err != nil {
+		return mediaErrorResponse(c, err)
+	}
+
+	var payload models.PayloadMedia
+	if err = c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing request body", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	media, err = repositories.UpdateMediaTags(ctx, media.Id, payload.Tags)
+	if err != nil {
+		return mediaErrorResponse(c, err)
+	}
+
+	utils.SetMediaRenditions(&media)
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update media", Data: &echo.Map{"media": media}})
+}
+
+// handler of DELETE /api/media/:media_id
+// media still used is not deleted unless force=true, the stored file is deleted too
+// only uploader of the media or admin can delete it
+func DeleteMedia(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	media, err := repositories.GetMedia(ctx, c.Param("media_id"))
+	if err != nil {
+		return mediaErrorResponse(c, err)
+	}
+
+	userId, role := currentUser(c)
+	if role != models.UserRoleAdmin && (media.UploaderID == "" || media.UploaderID != userId) {
+		return c.JSON(http.StatusForbidden, responses.GlobalResponse{Status: http.StatusForbidden, Message: "Only uploader of the media or admin can delete it", Data: nil})
+	}
+
+	usages, err := repositories.FindMediaUsages(ctx, media)
+	if err != nil {
+		return mediaErrorResponse(c, err)
+	}
+	if len(usages) > 0 && c.QueryParam("force") != "true" {
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: repositories.ErrMediaInUse.Error(), Data: &echo.Map{"usages": usages}})
+	}
+
+	if err = utils.DeleteMedia(ctx, media.PublicID, media.Type); err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error deleting file", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	if err = repositories.DeleteMedia(ctx, media.Id); err != nil {
+		return mediaErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success delete media", Data: &echo.Map{"usages": usages}})
+}
+
+// handler of POST /galleries/:gallery_id/images/media
+// adds media of the library to gallery without uploading them again
+func AddGalleryMedia(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadGalleryMedia
+	if err := c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing request body", Data: &echo.Map{"error": err.Error()}})
+	}
+	if len(payload.MediaIds) == 0 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "At least one media is required", Data: nil})
+	}
+
+	gallery, err := repositories.GetGalleryWithImageIds(ctx, c.Param("gallery_id"))
+	if err != nil {
+		return galleryImageErrorResponse(c, err)
+	}
+
+	medias, err := repositories.GetMediaByIds(ctx, payload.MediaIds)
+	if err != nil {
+		return mediaErrorResponse(c, err)
+	}
+	if len(medias) != len(payload.MediaIds) {
+		return mediaErrorResponse(c, repositories.ErrMediaNotFound)
+	}
+
+	existing := map[string]bool{}
+	for _, image := range gallery.Images {
+		existing[image.PublicID] = true
+	}
+
+	added := []models.ImageModel{}
+	for _, media := range medias {
+		// media already on gallery is not added twice
+		if existing[media.PublicID] {
+			continue
+		}
+		existing[media.PublicID] = true
+		added = append(added, newLibraryImage(media))
+	}
+
+	if len(added) > 0 {
+		// first image become cover if gallery has no cover yet
+		isCover, err := repositories.AddGalleryImages(ctx, gallery.Id, added)
+		if err != nil {
+			return galleryImageErrorResponse(c, err)
+		}
+		added[0].IsCover = isCover
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success add gallery images", Data: &echo.Map{"images": added}})
+}
+
+// newLibraryImage creates gallery image of media library item, the file is shared with the library
+func newLibraryImage(media models.MediaModel) models.ImageModel {
+	return models.ImageModel{
+		Id:            primitive.NewObjectID().Hex(),
+		PublicID:      media.PublicID,
+		Url:           media.Url,
+		Width:         media.Width,
+		Height:        media.Height,
+		Bytes:         media.Bytes,
+		Format:        media.Format,
+		DominantColor: media.DominantColor,
+		Type:          media.Type,
+		Duration:      media.Duration,
+		Poster:        media.Poster,
+		Hash:          media.Hash,
+		HashBands:     utils.ImageHashBands(media.Hash),
+		Caption:       media.Filename,
+		CreatedOn:     int(time.Now().Unix()),
+		UpdatedOn:     int(time.Now().Unix()),
+	}
+}
+
+// recordMedia adds uploaded file to media library, failure is only logged since the file is already uploaded
+// returns id of the media, empty when it is not recorded
+func recordMedia(ctx context.Context, result *storage.Asset, params repositories.RecordMediaParams) string {
+	media, err := repositories.RecordMedia(ctx, result, params)
+	if err != nil {
+		fmt.Printf("Failed to record media %s: %v\n", result.PublicID, err)
+		return ""
+	}
+	return media.Id.Hex()
+}
+
+// recordUploadedMedia adds files uploaded from form to media library, original filename is kept to search them
+func recordUploadedMedia(ctx context.Context, uploaded []*storage.Asset, files []*multipart.FileHeader, directory string, uploaderID string) {
+	for i, result := range uploaded {
+		recordMedia(ctx, result, repositories.RecordMediaParams{Directory: directory, Filename: files[i].Filename, UploaderID: uploaderID})
+	}
+}
+
+// deleteUnusedMedia deletes file removed from content, ex: deleted gallery image
+// file still used by other content is kept, since it may be picked from media library
+func deleteUnusedMedia(ctx context.Context, url string, publicID string, mediaType string) error {
+	if publicID == "" {
+		publicID = utils.GetPublicIDFromURL(url)
+	}
+	if publicID == "" {
+		return nil
+	}
+
+	usages, err := repositories.FindMediaUsages(ctx, models.MediaModel{Url: url, PublicID: publicID})
+	if err != nil {
+		return err
+	}
+	if len(usages) > 0 {
+		return nil
+	}
+
+	if err = utils.DeleteMedia(ctx, publicID, mediaType); err != nil {
+		return err
+	}
+	return repositories.DeleteMediaByPublicID(ctx, publicID)
+}
+
+func mediaErrorResponse(c echo.Context, err error) error {
+	if err == repositories.ErrMediaNotFound {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Media not found", Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
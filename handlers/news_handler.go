@@ -8,6 +8,7 @@ import (
 	"follooow-be/models"
 	"follooow-be/repositories"
 	"follooow-be/responses"
+	"follooow-be/utils"
 	"net/http"
 	"strconv"
 	"strings"
@@ -75,21 +76,47 @@ func ListNews(c echo.Context) error {
 	// handling filter by influencer id keyword [DONE]
 	if c.QueryParam("influencer_ids") != "" {
 		idsArr := strings.Split(c.QueryParam("influencer_ids"), ",")
+
+		// include news of group members, ex: news of Lisa on BLACKPINK news
+		if c.QueryParam("expand_groups") == "true" {
+			expanded, err := repositories.ExpandGroupMembers(ctx, idsArr)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+			}
+			idsArr = expanded
+		}
+
 		filterListData["influencers"] = bson.M{"$in": idsArr}
 	}
 
+	// handling filter by category id or slug, including its sub categories
+	if c.QueryParam("category") != "" {
+		categoryFilter, err := repositories.CategoryContentsFilter(ctx, c.QueryParam("category"))
+		if err != nil {
+			return categoryErrorResponse(c, err)
+		}
+		filterListData["$or"] = categoryFilter["$or"]
+	}
+
 	// by default sortby last update [DONE]
 	if c.QueryParam("order_by") == "created_on" { // oldeset created
 		optsListData = optsListData.SetSort(bson.D{{"created_on", 1}})
 	} else if c.QueryParam("order_by") == "created_on_new" { // latest created
 		optsListData = optsListData.SetSort(bson.D{{"created_on", -1}})
+	} else if c.QueryParam("order_by") == "trending" { // recent views, computed by trending job
+		optsListData = optsListData.SetSort(bson.D{{"trending_score", -1}, {"views", -1}})
 	} else {
 		optsListData = optsListData.SetSort(bson.D{{"updated_on", -1}})
 	}
 
 	// handling filter by tags [DONE]
+	// synonyms are replaced with canonical tag name, ex: kpop -> K-Pop
 	if c.QueryParam("tags") != "" {
-		filterListData["tags"] = bson.M{"$in": strings.Split(c.QueryParam("tags"), ",")}
+		tags, err := repositories.NormalizeTags(ctx, strings.Split(c.QueryParam("tags"), ","))
+		if err != nil {
+			return tagErrorResponse(c, err)
+		}
+		filterListData["tags"] = bson.M{"$in": tags}
 	}
 
 	// get data from database
@@ -171,6 +198,7 @@ func ListNews(c echo.Context) error {
 			}
 		}
 
+		utils.SetNewsRenditions(&singleNews)
 		news = append(news, singleNews)
 	}
 
@@ -206,6 +234,8 @@ func DetailNews(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
 	}
 
+	utils.SetNewsRenditions(&result)
+
 	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "OK", Data: &echo.Map{"news": result}})
 
 }
@@ -223,6 +253,16 @@ func CreateNews(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
 	} else {
+		// synonyms are saved with canonical tag name
+		payload.Tags, err = repositories.NormalizeTags(ctx, payload.Tags)
+		if err != nil {
+			return tagErrorResponse(c, err)
+		}
+
+		payload.CategoryID, payload.CategoryIDs, err = repositories.NormalizeContentCategories(ctx, payload.CategoryID, payload.CategoryIDs)
+		if err != nil {
+			return categoryErrorResponse(c, err)
+		}
 
 		stringTitle := fmt.Sprintf("%v", payload.Title)
 		// ref: https://stackoverflow.com/a/8689281/2780875
@@ -240,6 +280,8 @@ func CreateNews(c echo.Context) error {
 			{"influencers", payload.Influencers},
 			{"lang", payload.Lang},
 			{"slug", slug},
+			{"category_id", payload.CategoryID},
+			{"category_ids", payload.CategoryIDs},
 		}
 
 		// insert new data to db
@@ -252,7 +294,9 @@ func CreateNews(c echo.Context) error {
 			// post news to telegram channel
 			tags := ""
 			for _, n := range payload.Tags {
-				tags = "#" + strings.ReplaceAll(n, " ", "") + " " + tags
+				if hashtag := utils.Hashtag(n); hashtag != "" {
+					tags = hashtag + " " + tags
+				}
 			}
 			chatMessage := "New Update:\n" + payload.Title +
 				"\nhttps://follooow.com/" + payload.Lang + "/news/" + slug + "-" + result.InsertedID.(primitive.ObjectID).Hex() +
@@ -272,6 +316,8 @@ func CreateNews(c echo.Context) error {
 
 			_, err = newsInfluencersCollection.UpdateMany(ctx, bson.D{{"_id", bson.M{"$in": idsObjId}}}, bson.D{{"$set", bson.D{{"updated_on", now}}}})
 
+			refreshTagCounts(ctx, payload.Tags)
+
 			return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success create news", Data: nil})
 		}
 	}
@@ -303,6 +349,17 @@ func UpdateNews(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
 	} else {
+		// synonyms are saved with canonical tag name
+		payload.Tags, err = repositories.NormalizeTags(ctx, payload.Tags)
+		if err != nil {
+			return tagErrorResponse(c, err)
+		}
+
+		payload.CategoryID, payload.CategoryIDs, err = repositories.NormalizeContentCategories(ctx, payload.CategoryID, payload.CategoryIDs)
+		if err != nil {
+			return categoryErrorResponse(c, err)
+		}
+
 		new_data := bson.D{
 			{"title", payload.Title},
 			{"updated_on", now},
@@ -311,6 +368,8 @@ func UpdateNews(c echo.Context) error {
 			{"tags", payload.Tags},
 			{"influencers", payload.Influencers},
 			{"lang", payload.Lang},
+			{"category_id", payload.CategoryID},
+			{"category_ids", payload.CategoryIDs},
 		}
 
 		filter := bson.D{{"_id", objId}}
@@ -334,6 +393,9 @@ func UpdateNews(c echo.Context) error {
 
 			_, err = newsInfluencersCollection.UpdateMany(ctx, bson.D{{"_id", bson.M{"$in": idsObjId}}}, bson.D{{"$set", bson.D{{"updated_on", now}}}})
 
+			// removed tags are recounted too
+			refreshTagCounts(ctx, news.Tags, payload.Tags)
+
 			return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success update news", Data: nil})
 		}
 	}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /admin/media/orphans
+// dry run of orphaned files sweep, files are only deleted by gc-media command or scheduled job
+func OrphanMediaReport(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	sweep, err := repositories.SweepOrphanMedia(ctx, repositories.OrphanSweepParams{
+		Folders:     utils.OrphanFolders(),
+		GracePeriod: utils.OrphanGracePeriod(),
+		DryRun:      true,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"sweep": sweep}})
+}
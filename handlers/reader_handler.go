@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reader login valid for 30 days
+const readerTokenTTL = 30 * 24 * time.Hour
+
+// feed cached per reader, cleared when reader follow or unfollow influencer
+var readerFeedCache = utils.NewCache(time.Minute)
+
+// handler of POST /api/readers
+func CreateReader(c echo.Context) error {
+	var reader models.CreateReaderModel
+	if err := c.Bind(&reader); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{
+			Status:  http.StatusBadRequest,
+			Message: "error",
+			Data:    &echo.Map{"error": err.Error()},
+		})
+	}
+
+	// Validate input
+	if reader.Username == "" || reader.Password == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{
+			Status:  http.StatusBadRequest,
+			Message: "error",
+			Data:    &echo.Map{"error": "username and password are required"},
+		})
+	}
+
+	// Hash password
+	hashedPassword, err := utils.HashPassword(reader.Password)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "error",
+			Data:    &echo.Map{"error": "failed to hash password"},
+		})
+	}
+	reader.Password = hashedPassword
+
+	newReader, err := repositories.CreateReader(reader)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "error",
+			Data:    &echo.Map{"error": err.Error()},
+		})
+	}
+
+	if newReader == nil {
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{
+			Status:  http.StatusConflict,
+			Message: "error",
+			Data:    &echo.Map{"error": "username already exists"},
+		})
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{
+		Status:  http.StatusCreated,
+		Message: "success",
+		Data:    &echo.Map{"reader": readerResponse(newReader)},
+	})
+}
+
+// handler of POST /api/readers/login
+func LoginReader(c echo.Context) error {
+	var loginReq models.LoginRequest
+	if err := c.Bind(&loginReq); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{
+			Status:  http.StatusBadRequest,
+			Message: "error",
+			Data:    &echo.Map{"error": err.Error()},
+		})
+	}
+
+	// Validate input
+	if loginReq.Username == "" || loginReq.Password == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{
+			Status:  http.StatusBadRequest,
+			Message: "error",
+			Data:    &echo.Map{"error": "username and password are required"},
+		})
+	}
+
+	reader, err := repositories.FindReaderByUsername(loginReq.Username)
+	if err != nil || !utils.CheckPasswordHash(loginReq.Password, reader.Password) {
+		return c.JSON(http.StatusUnauthorized, responses.GlobalResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "error",
+			Data:    &echo.Map{"error": "invalid username or password"},
+		})
+	}
+
+	token, err := utils.GenerateToken(utils.TokenReader, reader.ID.Hex(), readerTokenTTL)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "error",
+			Data:    &echo.Map{"error": err.Error()},
+		})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    &echo.Map{"login": models.ReaderLoginResponse{Reader: readerResponse(reader), Token: token}},
+	})
+}
+
+// handler of GET /me
+func GetMe(c echo.Context) error {
+	objId, _ := primitive.ObjectIDFromHex(c.Get("reader_id").(string))
+
+	reader, err := repositories.FindReaderByID(objId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{
+			Status:  http.StatusNotFound,
+			Message: "error",
+			Data:    &echo.Map{"error": "reader not found"},
+		})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    &echo.Map{"reader": readerResponse(reader)},
+	})
+}
+
+// handler of GET /me/follows
+func ListFollows(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	influencerIds, err := repositories.GetFollowedInfluencerIds(ctx, c.Get("reader_id").(string))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	influencersData, err := repositories.GetInfluencersSmallData(ctx, influencerIds)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	// keep latest follow first
+	influencers := []models.InfluencerSmallDataModel{}
+	for _, id := range influencerIds {
+		if influencer, ok := influencersData[id]; ok {
+			influencers = append(influencers, influencer)
+		}
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"influencers": influencers}})
+}
+
+// handler of POST /me/follows/:influencer_id
+func FollowInfluencer(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	readerId := c.Get("reader_id").(string)
+	influencerId := c.Param("influencer_id")
+
+	influencers, err := repositories.GetInfluencersSmallData(ctx, []string{influencerId})
+	if err != nil || len(influencers) < 1 {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Influencer not found", Data: nil})
+	}
+
+	followed, err := repositories.FollowInfluencer(ctx, readerId, influencerId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	readerFeedCache.DeletePrefix(readerId + "|")
+
+	if !followed {
+		return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Influencer already followed", Data: nil})
+	}
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success follow influencer", Data: nil})
+}
+
+// handler of DELETE /me/follows/:influencer_id
+func UnfollowInfluencer(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	readerId := c.Get("reader_id").(string)
+
+	unfollowed, err := repositories.UnfollowInfluencer(ctx, readerId, c.Param("influencer_id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	readerFeedCache.DeletePrefix(readerId + "|")
+
+	if !unfollowed {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Influencer not followed", Data: nil})
+	}
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success unfollow influencer", Data: nil})
+}
+
+// handler of GET /me/feed
+func ReaderFeed(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	readerId := c.Get("reader_id").(string)
+
+	// handling limit, by default 10, max 50
+	limit, err := queryInt(c, "limit", 10)
+	if err != nil || limit < 1 || limit > 50 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+	}
+
+	cursor, err := utils.DecodeCursor(c.QueryParam("cursor"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	cacheKey := readerId + "|" + c.QueryParam("lang") + "|" + c.QueryParam("cursor") + "|" + strconv.FormatInt(limit, 10)
+	if cached, ok := readerFeedCache.Get(cacheKey); ok {
+		return c.JSON(http.StatusOK, cached)
+	}
+
+	influencerIds, err := repositories.GetFollowedInfluencerIds(ctx, readerId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	feed, nextCursor, err := repositories.GetReaderFeed(ctx, repositories.ReaderFeedParams{
+		InfluencerIDs: influencerIds,
+		Lang:          c.QueryParam("lang"),
+		Cursor:        cursor,
+		Limit:         limit,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	response := responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"feed": feed, "next_cursor": nextCursor}}
+	readerFeedCache.Set(cacheKey, response)
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// readerResponse removes password from reader
+func readerResponse(reader *models.ReaderModel) models.ReaderResponse {
+	return models.ReaderResponse{
+		ID:          reader.ID,
+		Username:    reader.Username,
+		DisplayName: reader.DisplayName,
+		CreatedAt:   reader.CreatedAt,
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /influencers/:influencer_id/relationships
+func ListInfluencerRelationships(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	relationships, err := repositories.ListRelationships(ctx, c.Param("influencer_id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"relationships": relationships}})
+}
+
+// handler of POST /influencers/:influencer_id/relationships
+func AddInfluencerRelationship(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	influencerId := c.Param("influencer_id")
+
+	var payload models.PayloadRelationship
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	if message := validateRelationship(ctx, influencerId, payload); message != "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: message, Data: nil})
+	}
+
+	result, err := repositories.CreateRelationship(ctx, influencerId, payload)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error insert data", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success add relationship", Data: &echo.Map{"relationship_id": result.InsertedID}})
+}
+
+// handler of PUT /influencers/:influencer_id/relationships/:relationship_id
+func UpdateInfluencerRelationship(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	influencerId := c.Param("influencer_id")
+
+	var payload models.PayloadRelationship
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	if message := validateRelationship(ctx, influencerId, payload); message != "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: message, Data: nil})
+	}
+
+	result, err := repositories.UpdateRelationship(ctx, influencerId, c.Param("relationship_id"), payload)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error update database", Data: &echo.Map{"error": err.Error()}})
+	}
+	if result.MatchedCount < 1 {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Relationship not found", Data: nil})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update relationship", Data: nil})
+}
+
+// handler of DELETE /influencers/:influencer_id/relationships/:relationship_id
+func DeleteInfluencerRelationship(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := repositories.DeleteRelationship(ctx, c.Param("influencer_id"), c.Param("relationship_id"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "Error delete data", Data: &echo.Map{"error": err.Error()}})
+	}
+	if result.DeletedCount < 1 {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Relationship not found", Data: nil})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success delete relationship", Data: nil})
+}
+
+// validateRelationship returns error message of invalid payload, empty if payload is valid
+func validateRelationship(ctx context.Context, influencerId string, payload models.PayloadRelationship) string {
+	if !repositories.ValidRelationshipType(payload.Type) {
+		return "Invalid relationship type"
+	}
+	if payload.RelatedID == "" || payload.RelatedID == influencerId {
+		return "Invalid related influencer"
+	}
+	if payload.EndOn != 0 && payload.EndOn < payload.StartOn {
+		return "end_on must be after start_on"
+	}
+
+	// both side of relationship must be exists
+	influencers, err := repositories.GetInfluencersSmallData(ctx, []string{influencerId, payload.RelatedID})
+	if err != nil || len(influencers) < 2 {
+		return "Influencer not found"
+	}
+
+	return ""
+}
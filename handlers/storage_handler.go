@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"follooow-be/responses"
+	"follooow-be/storage"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /uploads/_renditions/:rendition/*
+// resized image of local storage, resized on first request then served from cache
+func LocalRendition(c echo.Context) error {
+	local, ok := storage.Default().(*storage.LocalStorage)
+	if !ok {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Image not found", Data: nil})
+	}
+
+	path, err := local.Rendition(c.Param("rendition"), c.Param("*"))
+	if err == storage.ErrNotFound {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Image not found", Data: nil})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.File(path)
+}
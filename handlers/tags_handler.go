@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// handler of GET /tags
+// most used tags first, label is name of tag on lang
+func ListTags(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// handling limit, by default 20
+	limit, err := queryInt(c, "limit", 20)
+	if err != nil || limit < 1 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+	}
+
+	// handling page, by default 1
+	page, err := queryInt(c, "page", 1)
+	if err != nil || page < 1 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid page", Data: nil})
+	}
+
+	tags, total, err := repositories.ListTags(ctx, repositories.ListTagsParams{
+		Search: c.QueryParam("search"),
+		Lang:   c.QueryParam("lang"),
+		Limit:  limit,
+		Page:   page,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"tags": tags, "total": total}})
+}
+
+// handler of GET /tags/:slug
+// tag page, news and galleries of the tag as one feed sorted by date
+func DetailTag(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// handling limit, by default 10, max 50
+	limit, err := queryInt(c, "limit", 10)
+	if err != nil || limit < 1 || limit > 50 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+	}
+
+	cursor, err := utils.DecodeCursor(c.QueryParam("cursor"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	tag, contents, nextCursor, err := repositories.GetTagFeed(ctx, repositories.TagFeedParams{
+		Slug:   c.Param("slug"),
+		Lang:   c.QueryParam("lang"),
+		Cursor: cursor,
+		Limit:  limit,
+	})
+	if err != nil {
+		return tagErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"tag": tag, "contents": contents, "next_cursor": nextCursor}})
+}
+
+// handler of POST /tags
+func CreateTag(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadTag
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	tag, err := repositories.CreateTag(ctx, payload)
+	if err != nil {
+		return tagErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success add tag", Data: &echo.Map{"tag": tag}})
+}
+
+// handler of PUT /tags/:slug
+func UpdateTag(c echo.Context) error {
+	// renamed tag rewrites news and galleries like merge, so it has longer timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var payload models.PayloadTag
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	tag, err := repositories.UpdateTag(ctx, c.Param("slug"), payload)
+	if err != nil {
+		return tagErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success update tag", Data: &echo.Map{"tag": tag}})
+}
+
+// handler of POST /tags/:slug/merge
+// merged tags become synonyms of the tag, news and galleries using them are rewritten to the tag name
+func MergeTags(c echo.Context) error {
+	// every news and gallery with merged tags is rewritten, so it has longer timeout
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var payload models.PayloadTagMerge
+	if err := json.NewDecoder(c.Request().Body).Decode(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing json", Data: nil})
+	}
+
+	tag, rewritten, err := repositories.MergeTags(ctx, c.Param("slug"), payload.Tags)
+	if err != nil {
+		return tagErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success merge tags", Data: &echo.Map{"tag": tag, "rewritten": rewritten}})
+}
+
+// refreshTagCounts recounts usage of tags removed from or added to news or gallery
+// content is already saved, so failure is only logged
+func refreshTagCounts(ctx context.Context, tags ...[]string) {
+	var all []string
+	for _, list := range tags {
+		all = append(all, list...)
+	}
+
+	if err := repositories.RefreshTagCounts(ctx, all); err != nil {
+		fmt.Printf("Failed to refresh tag counts: %v\n", err)
+	}
+}
+
+// tagErrorResponse responds error of tags repository
+func tagErrorResponse(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrTagNotFound):
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Tag not found", Data: nil})
+	case errors.Is(err, repositories.ErrTagConflict):
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: err.Error(), Data: nil})
+	case errors.Is(err, repositories.ErrTagName), errors.Is(err, repositories.ErrTagMerge):
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: err.Error(), Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// handler of GET /influencers/:influencer_id/timeline
+func TimelineInfluencer(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// handling limit, by default 10, max 50
+	limit, err := queryInt(c, "limit", 10)
+	if err != nil || limit < 1 || limit > 50 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+	}
+
+	// handling filter by year
+	year, err := queryInt(c, "year", 0)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid year", Data: nil})
+	}
+
+	cursor, err := utils.DecodeCursor(c.QueryParam("cursor"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	timeline, nextCursor, err := repositories.GetInfluencerTimeline(ctx, repositories.TimelineParams{
+		InfluencerID: c.Param("influencer_id"),
+		Lang:         c.QueryParam("lang"),
+		Year:         int(year),
+		Cursor:       cursor,
+		Limit:        limit,
+	})
+
+	if err == mongo.ErrNoDocuments {
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Influencer not found", Data: nil})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"timeline": timeline, "next_cursor": nextCursor}})
+}
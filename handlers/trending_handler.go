@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// max trending items of each type
+const trendingMaxLimit = 50
+
+// handler of GET /trending
+// top news, galleries and influencers of the language by trending score, scores are computed by trending job
+func ListTrending(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// handling language, by default id
+	lang := "id"
+	if c.QueryParam("lang") != "" {
+		lang = c.QueryParam("lang")
+	}
+
+	// handling limit of each type, by default 10
+	limit, err := queryInt(c, "limit", 10)
+	if err != nil || limit < 1 || limit > trendingMaxLimit {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid limit", Data: nil})
+	}
+
+	// handling filter by type, by default all types
+	keys := map[string]string{
+		models.TrendingNews:       "news",
+		models.TrendingGallery:    "galleries",
+		models.TrendingInfluencer: "influencers",
+	}
+	types := []string{models.TrendingNews, models.TrendingGallery, models.TrendingInfluencer}
+	if c.QueryParam("type") != "" {
+		types = strings.Split(c.QueryParam("type"), ",")
+	}
+
+	data := echo.Map{}
+	for _, itemType := range types {
+		itemType = strings.TrimSpace(itemType)
+		key, ok := keys[itemType]
+		if !ok {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid type", Data: nil})
+		}
+
+		items, err := repositories.GetTrending(ctx, itemType, lang, limit)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+		}
+		data[key] = items
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &data})
+}
@@ -3,9 +3,10 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"follooow-be/configs"
 	"follooow-be/models"
+	"follooow-be/repositories"
 	"follooow-be/responses"
+	"follooow-be/storage"
 	"follooow-be/utils"
 	"net/http"
 	"strings"
@@ -92,6 +93,11 @@ func UpdateGallery(c echo.Context) error {
 	}
 
 	if payload.Images != nil {
+		if err = utils.ValidateGalleryImageCredits(payload.Images); err != nil {
+			return imageCreditErrorResponse(c, err)
+		}
+		repositories.EnsureImageIds(payload.Images)
+		utils.SetImageHashBands(payload.Images)
 		updateData["images"] = payload.Images
 	}
 
@@ -100,9 +106,32 @@ func UpdateGallery(c echo.Context) error {
 	}
 
 	if payload.Tags != nil {
+		// synonyms are saved with canonical tag name
+		payload.Tags, err = repositories.NormalizeTags(ctx, payload.Tags)
+		if err != nil {
+			return tagErrorResponse(c, err)
+		}
 		updateData["tags"] = payload.Tags
 	}
 
+	// category which is not sent is kept
+	if payload.CategoryID != "" || payload.CategoryIDs != nil {
+		categoryID, categoryIDs := existingGallery.CategoryID, existingGallery.CategoryIDs
+		if payload.CategoryID != "" {
+			categoryID = payload.CategoryID
+		}
+		if payload.CategoryIDs != nil {
+			categoryIDs = payload.CategoryIDs
+		}
+
+		categoryID, categoryIDs, err = repositories.NormalizeContentCategories(ctx, categoryID, categoryIDs)
+		if err != nil {
+			return categoryErrorResponse(c, err)
+		}
+		updateData["category_id"] = categoryID
+		updateData["category_ids"] = categoryIDs
+	}
+
 	// Update gallery in database
 	_, err = galleryCollection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateData})
 	if err != nil {
@@ -113,6 +142,11 @@ func UpdateGallery(c echo.Context) error {
 		})
 	}
 
+	// removed tags are recounted too
+	if payload.Tags != nil {
+		refreshTagCounts(ctx, existingGallery.Tags, payload.Tags)
+	}
+
 	return c.JSON(http.StatusOK, responses.GlobalResponse{
 		Status:  http.StatusOK,
 		Message: "Gallery updated successfully",
@@ -122,9 +156,6 @@ func UpdateGallery(c echo.Context) error {
 
 // UpdateGalleryWithUpload handles updating gallery with image uploads
 func UpdateGalleryWithUpload(c echo.Context) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
 	galleryID := c.Param("gallery_id")
 	if galleryID == "" {
 		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{
@@ -154,6 +185,16 @@ func UpdateGalleryWithUpload(c echo.Context) error {
 		})
 	}
 
+	credit, err := formImageCredit(c)
+	if err != nil {
+		return imageCreditErrorResponse(c, err)
+	}
+
+	// request time budget depends on number of uploaded files
+	files := form.File["images"]
+	ctx, cancel := context.WithTimeout(context.Background(), utils.UploadBudget(files))
+	defer cancel()
+
 	// Get existing gallery
 	var existingGallery models.GalleryModel
 	err = galleryCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&existingGallery)
@@ -211,50 +252,70 @@ func UpdateGalleryWithUpload(c echo.Context) error {
 	}
 
 	// Parse tags if provided
+	var tags []string
 	if tagsStr != "" {
-		tags := strings.Split(tagsStr, ",")
+		tags = strings.Split(tagsStr, ",")
 		for i, tag := range tags {
 			tags[i] = strings.TrimSpace(tag)
 		}
+
+		// synonyms are saved with canonical tag name
+		tags, err = repositories.NormalizeTags(ctx, tags)
+		if err != nil {
+			return tagErrorResponse(c, err)
+		}
 		updateData["tags"] = tags
 	}
 
+	// category which is not sent is kept
+	if c.FormValue("category_id") != "" || c.FormValue("category_ids") != "" {
+		categoryID, categoryIDs := existingGallery.CategoryID, existingGallery.CategoryIDs
+		if c.FormValue("category_id") != "" {
+			categoryID = c.FormValue("category_id")
+		}
+		if c.FormValue("category_ids") != "" {
+			categoryIDs = splitFormList(c.FormValue("category_ids"))
+		}
+
+		categoryID, categoryIDs, err = repositories.NormalizeContentCategories(ctx, categoryID, categoryIDs)
+		if err != nil {
+			return categoryErrorResponse(c, err)
+		}
+		updateData["category_id"] = categoryID
+		updateData["category_ids"] = categoryIDs
+	}
+
 	// Handle image uploads if provided
-	files := form.File["images"]
+	var uploaded []*storage.Asset
+	duplicates := []models.DuplicateImageModel{}
 	if len(files) > 0 {
-		// Initialize Cloudinary if not already done
-		if configs.CloudinaryClient == nil {
-			configs.InitCloudinary()
+		uploaded, err = utils.UploadMediaFilesFromForm(ctx, files, "galleries")
+		if err != nil {
+			return uploadErrorResponse(c, err)
 		}
 
 		var images []models.ImageModel
-		for i, file := range files {
-			result, err := utils.UploadImageFromForm(ctx, file, "galleries")
-			if err != nil {
-				return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{
-					Status:  http.StatusInternalServerError,
-					Message: "Error uploading image",
-					Data:    &echo.Map{"error": err.Error()},
-				})
-			}
-
-			imageModel := models.ImageModel{
-				IsCover:   i == 0,
-				Url:       result.SecureURL,
-				Caption:   file.Filename,
-				CreatedOn: int(time.Now().Unix()),
-				UpdatedOn: int(time.Now().Unix()),
-			}
+		for i, result := range uploaded {
+			imageModel := newUploadedImage(result, files[i].Filename)
+			imageModel.ImageCreditModel = credit
+			imageModel.IsCover = i == 0
 
 			images = append(images, imageModel)
 		}
 
+		// existing images are replaced, so they are not duplicates
+		duplicates, err = findUploadedDuplicates(ctx, images, uploaded, objID)
+		if err != nil {
+			return duplicateErrorResponse(c, duplicates, err)
+		}
+
 		updateData["images"] = images
 	}
 
 	// Update gallery in database
 	_, err = galleryCollection.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": updateData})
 	if err != nil {
+		utils.DeleteUploadedMedia(uploaded)
 		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{
 			Status:  http.StatusInternalServerError,
 			Message: "Error updating gallery",
@@ -262,9 +323,23 @@ func UpdateGalleryWithUpload(c echo.Context) error {
 		})
 	}
 
+	recordUploadedMedia(ctx, uploaded, files, "galleries", "")
+
+	// replaced images are removed unless they are reused, failure is ignored since the gallery is already updated
+	if len(uploaded) > 0 {
+		for _, image := range existingGallery.Images {
+			deleteUnusedMedia(ctx, image.Url, image.PublicID, image.Type)
+		}
+	}
+
+	// removed tags are recounted too
+	if tags != nil {
+		refreshTagCounts(ctx, existingGallery.Tags, tags)
+	}
+
 	return c.JSON(http.StatusOK, responses.GlobalResponse{
 		Status:  http.StatusOK,
 		Message: "Gallery updated successfully with images",
-		Data:    &echo.Map{"gallery_id": objID},
+		Data:    &echo.Map{"gallery_id": objID, "duplicates": duplicates},
 	})
 }
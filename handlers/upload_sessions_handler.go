@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"context"
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// header of chunk sha256 checksum, hex encoded
+const chunkChecksumHeader = "X-Chunk-Sha256"
+
+// handler of POST /api/media/uploads
+// starts chunked upload, chunks are sent to PUT /api/media/uploads/:upload_id/chunks/:index
+func CreateUploadSession(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payload models.PayloadUploadSession
+	if err := c.Bind(&payload); err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error parsing request body", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	if payload.Filename == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Filename is required", Data: nil})
+	}
+	if payload.Directory == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Directory is required", Data: nil})
+	}
+	if payload.Size < 1 {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Size is required", Data: nil})
+	}
+	if max := utils.UploadMaxBytes(); payload.Size > max {
+		return c.JSON(http.StatusRequestEntityTooLarge, responses.GlobalResponse{Status: http.StatusRequestEntityTooLarge, Message: "File is too large", Data: &echo.Map{"max_size": max}})
+	}
+
+	userId, role := currentUser(c)
+
+	// directory must be inside namespace allowed for the user role
+	directory, err := utils.AuthorizeUploadDirectory(role, payload.Directory)
+	if err != nil {
+		return uploadDirectoryErrorResponse(c, err)
+	}
+	payload.Directory = mediaDirectory(directory)
+
+	session, err := repositories.CreateUploadSession(ctx, payload, userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusCreated, responses.GlobalResponse{Status: http.StatusCreated, Message: "Success create upload", Data: &echo.Map{"upload": session}})
+}
+
+// handler of GET /api/media/uploads/:upload_id
+// received chunks are used to resume upload after disconnect
+func DetailUploadSession(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := getUserUploadSession(ctx, c)
+	if err != nil {
+		return uploadSessionErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "success", Data: &echo.Map{"upload": session, "missing": missingChunks(session)}})
+}
+
+// handler of PUT /api/media/uploads/:upload_id/chunks/:index
+// body is raw chunk, X-Chunk-Sha256 header is its checksum
+func UploadSessionChunk(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := getUserUploadSession(ctx, c)
+	if err != nil {
+		return uploadSessionErrorResponse(c, err)
+	}
+	if session.Status != models.UploadSessionPending {
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: "Upload is already completed", Data: &echo.Map{"upload": session}})
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		index = -1
+	}
+	offset, expected, err := utils.UploadChunkRange(index, session.ChunkSize, session.Size)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Invalid chunk index", Data: &echo.Map{"total_chunks": session.TotalChunks}})
+	}
+
+	checksum := c.Request().Header.Get(chunkChecksumHeader)
+	if checksum == "" {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: chunkChecksumHeader + " header is required", Data: nil})
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request().Body, expected+1))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: "Error reading chunk", Data: &echo.Map{"error": err.Error()}})
+	}
+	if int64(len(data)) != expected {
+		return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: utils.ErrChunkSize.Error(), Data: &echo.Map{"expected": expected, "received": len(data)}})
+	}
+
+	// status read above may be stale, the write is counted only when the session is still pending
+	if err = repositories.StartUploadSessionChunk(ctx, session.Id); err != nil {
+		return uploadSessionErrorResponse(c, err)
+	}
+
+	writeErr := utils.WriteUploadChunk(session.Id.Hex(), offset, data, checksum)
+
+	// finished even when request is cancelled, otherwise the session can't be completed
+	session, err = repositories.FinishUploadSessionChunk(context.Background(), session.Id, index, writeErr == nil)
+	if writeErr != nil {
+		if writeErr == utils.ErrChunkChecksum {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: writeErr.Error(), Data: nil})
+		}
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": writeErr.Error()}})
+	}
+	if err != nil {
+		return uploadSessionErrorResponse(c, err)
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success upload chunk", Data: &echo.Map{"upload": session, "missing": missingChunks(session)}})
+}
+
+// handler of POST /api/media/uploads/:upload_id/complete
+// uploads assembled file to the storage, completing again returns the same media
+func CompleteUploadSession(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := getUserUploadSession(ctx, c)
+	if err != nil {
+		return uploadSessionErrorResponse(c, err)
+	}
+	if session.Status == models.UploadSessionPending && len(session.Received) < session.TotalChunks {
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: "Upload has missing chunks", Data: &echo.Map{"missing": missingChunks(session)}})
+	}
+
+	// file type is not known yet, so time budget has room for a video
+	uploadCtx, uploadCancel := context.WithTimeout(context.Background(), utils.UploadBudgetOf(1, 1))
+	defer uploadCancel()
+
+	session, err = repositories.ClaimUploadSession(uploadCtx, session.Id)
+	if err != nil {
+		return uploadSessionErrorResponse(c, err)
+	}
+	if session.Status == models.UploadSessionCompleted && session.Result != nil {
+		data := uploadedMediaData(*session.Result)
+		return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "File uploaded successfully", Data: &data})
+	}
+
+	result, err := uploadSessionFile(uploadCtx, session)
+	if err != nil {
+		repositories.ReleaseUploadSession(context.Background(), session.Id)
+		if err == utils.ErrUploadChecksum {
+			return c.JSON(http.StatusBadRequest, responses.GlobalResponse{Status: http.StatusBadRequest, Message: err.Error(), Data: nil})
+		}
+		return uploadErrorResponse(c, err)
+	}
+
+	media := uploadedMedia(result, session.Directory)
+	media.MediaID = recordMedia(uploadCtx, result, repositories.RecordMediaParams{Directory: session.Directory, Filename: session.Filename, UploaderID: session.UploaderID, Tags: session.Tags})
+	if err = repositories.CompleteUploadSession(uploadCtx, session.Id, media); err != nil {
+		utils.DeleteUploadedMedia([]*storage.Asset{result})
+		repositories.DeleteMediaByPublicID(context.Background(), result.PublicID)
+		repositories.ReleaseUploadSession(context.Background(), session.Id)
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	// chunks are not needed anymore, session is kept to answer retried complete
+	utils.RemoveUploadFile(session.Id.Hex())
+
+	data := uploadedMediaData(media)
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "File uploaded successfully", Data: &data})
+}
+
+// handler of DELETE /api/media/uploads/:upload_id
+// cancels upload, media of completed upload is kept
+func DeleteUploadSession(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	session, err := getUserUploadSession(ctx, c)
+	if err != nil {
+		return uploadSessionErrorResponse(c, err)
+	}
+	if session.Status == models.UploadSessionCompleting {
+		return uploadSessionErrorResponse(c, repositories.ErrUploadSessionBusy)
+	}
+
+	if err = repositories.DeleteUploadSession(ctx, session.Id); err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+	}
+
+	return c.JSON(http.StatusOK, responses.GlobalResponse{Status: http.StatusOK, Message: "Success delete upload", Data: nil})
+}
+
+// getUserUploadSession gets upload session of logged in user, upload of other user is not found except for admin
+func getUserUploadSession(ctx context.Context, c echo.Context) (models.UploadSessionModel, error) {
+	session, err := repositories.GetUploadSession(ctx, c.Param("upload_id"))
+	if err != nil {
+		return session, err
+	}
+
+	userId, role := currentUser(c)
+	if session.UploaderID != userId && role != models.UserRoleAdmin {
+		return models.UploadSessionModel{}, repositories.ErrUploadSessionNotFound
+	}
+	return session, nil
+}
+
+// uploadSessionFile verifies checksum of assembled file then uploads it
+func uploadSessionFile(ctx context.Context, session models.UploadSessionModel) (*storage.Asset, error) {
+	file, err := utils.OpenUploadFile(session.Id.Hex(), session.Sha256)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return utils.UploadMediaFromFile(ctx, file, session.Size, session.Filename, session.Directory)
+}
+
+// missingChunks gets indexes of chunks not received yet
+func missingChunks(session models.UploadSessionModel) []int {
+	received := map[int]bool{}
+	for _, index := range session.Received {
+		received[index] = true
+	}
+
+	missing := []int{}
+	for index := 0; index < session.TotalChunks; index++ {
+		if !received[index] {
+			missing = append(missing, index)
+		}
+	}
+	return missing
+}
+
+func uploadSessionErrorResponse(c echo.Context, err error) error {
+	switch err {
+	case repositories.ErrUploadSessionNotFound:
+		return c.JSON(http.StatusNotFound, responses.GlobalResponse{Status: http.StatusNotFound, Message: "Upload not found", Data: nil})
+	case repositories.ErrUploadSessionBusy:
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: "Upload is being completed", Data: nil})
+	case repositories.ErrUploadSessionClosed:
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: "Upload is already completed", Data: nil})
+	case repositories.ErrUploadSessionWriting:
+		return c.JSON(http.StatusConflict, responses.GlobalResponse{Status: http.StatusConflict, Message: "Chunk is being written, retry complete", Data: nil})
+	}
+	return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{Status: http.StatusInternalServerError, Message: "error", Data: &echo.Map{"error": err.Error()}})
+}
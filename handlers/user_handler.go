@@ -13,6 +13,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+const userTokenTTL = 7 * 24 * time.Hour
+
 func CreateUser(c echo.Context) error {
 	_, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -70,6 +72,7 @@ func CreateUser(c echo.Context) error {
 	userResponse := models.UserResponse{
 		ID:        newUser.ID,
 		Username:  newUser.Username,
+		Role:      newUser.Role,
 		CreatedAt: newUser.CreatedAt,
 		UpdatedAt: newUser.UpdatedAt,
 	}
@@ -109,6 +112,7 @@ func GetUserByID(c echo.Context) error {
 	userResponse := models.UserResponse{
 		ID:        user.ID,
 		Username:  user.Username,
+		Role:      user.Role,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
@@ -158,10 +162,22 @@ func LoginUser(c echo.Context) error {
 		})
 	}
 
+	// token is sent as "Authorization: Bearer <token>" to upload media
+	token, err := utils.GenerateToken(utils.TokenUser, user.ID.Hex(), userTokenTTL)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, responses.GlobalResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "error",
+			Data:    &echo.Map{"error": err.Error()},
+		})
+	}
+
 	// Prepare login response
 	loginResponse := models.LoginResponse{
 		UserID:   user.ID.Hex(),
 		Username: user.Username,
+		Role:     user.Role,
+		Token:    token,
 		Message:  "Login successful",
 	}
 
@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"follooow-be/repositories"
+	"time"
+)
+
+// FailInterruptedGalleryImports fails gallery imports left running by previous server, so they are not polled forever
+func FailInterruptedGalleryImports() {
+	run("gallery-imports-recovery", 10*time.Second, func(ctx context.Context) error {
+		total, err := repositories.FailInterruptedGalleryImports(ctx)
+		if total > 0 {
+			fmt.Printf("Failed %d interrupted gallery imports\n", total)
+		}
+		return err
+	})
+}
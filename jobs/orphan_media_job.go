@@ -0,0 +1,25 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/repositories"
+	"follooow-be/utils"
+	"time"
+)
+
+// StartOrphanMediaSweep schedules removal of stored files not used by any content
+// by default the job only reports orphans, ORPHAN_GC_DRY_RUN=false deletes them
+func StartOrphanMediaSweep() {
+	interval := duration(configs.EnvOrphanGCInterval(), 24*time.Hour)
+	Every("orphan-media-sweep", interval, func(ctx context.Context) error {
+		sweep, err := repositories.SweepOrphanMedia(ctx, repositories.OrphanSweepParams{
+			Folders:     utils.OrphanFolders(),
+			GracePeriod: utils.OrphanGracePeriod(),
+			DryRun:      utils.OrphanDryRun(),
+		})
+		fmt.Printf("Scanned %d stored files, %d orphans, deleted %d, failed %d, dry run %t\n", sweep.Scanned, len(sweep.Orphans), sweep.Deleted, sweep.Failed, sweep.DryRun)
+		return err
+	})
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Every runs job on background every interval
+// each run has context with interval as timeout, so runs never overlap
+func Every(name string, interval time.Duration, job func(ctx context.Context) error) {
+	if interval <= 0 {
+		fmt.Printf("Job %s disabled: invalid interval %s\n", name, interval)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			run(name, interval, job)
+		}
+	}()
+
+	fmt.Printf("Job %s scheduled every %s\n", name, interval)
+}
+
+func run(name string, timeout time.Duration, job func(ctx context.Context) error) {
+	// keep server alive if job panic
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Job %s panic: %v\n", name, r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := job(ctx); err != nil {
+		fmt.Printf("Job %s failed after %s: %v\n", name, time.Since(start), err)
+		return
+	}
+	fmt.Printf("Job %s done in %s\n", name, time.Since(start))
+}
+
+// duration parses duration from env value, fallback to default value if empty or invalid
+func duration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		fmt.Printf("Invalid duration %q, using %s\n", value, fallback)
+		return fallback
+	}
+
+	return parsed
+}
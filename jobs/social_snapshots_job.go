@@ -0,0 +1,30 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/repositories"
+	"follooow-be/utils"
+	"time"
+)
+
+// StartSocialSnapshots schedules follower snapshots of all influencers socials
+// skipped when SOCIAL_FETCHER is not configured
+func StartSocialSnapshots() {
+	fetcher, err := utils.NewSocialFetcher()
+	if err != nil {
+		fmt.Println("Social snapshots disabled:", err)
+		return
+	}
+	if fetcher == nil {
+		return
+	}
+
+	interval := duration(configs.EnvSocialSnapshotInterval(), 6*time.Hour)
+	Every("social-snapshots", interval, func(ctx context.Context) error {
+		total, err := repositories.RefreshSocialSnapshots(ctx, fetcher)
+		fmt.Printf("Recorded %d social snapshots\n", total)
+		return err
+	})
+}
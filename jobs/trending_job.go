@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/repositories"
+	"time"
+)
+
+// StartTrendingScores schedules trending scores of news, galleries and influencers from their recent views
+func StartTrendingScores() {
+	interval := duration(configs.EnvTrendingInterval(), 15*time.Minute)
+	window := duration(configs.EnvTrendingWindow(), 7*24*time.Hour)
+	halfLife := duration(configs.EnvTrendingHalfLife(), 24*time.Hour)
+	if halfLife <= 0 {
+		halfLife = 24 * time.Hour
+	}
+
+	Every("trending-scores", interval, func(ctx context.Context) error {
+		result, err := repositories.ComputeTrending(ctx, repositories.TrendingParams{
+			Window:   window,
+			HalfLife: halfLife,
+			Now:      time.Now(),
+		})
+		fmt.Printf("Computed %d trending scores, removed %d old view buckets\n", result.Items, result.Removed)
+		return err
+	})
+}
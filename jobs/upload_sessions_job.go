@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/repositories"
+	"time"
+)
+
+// StartUploadSessionsCleanup schedules removal of abandoned chunked uploads and their files
+func StartUploadSessionsCleanup() {
+	interval := duration(configs.EnvUploadSessionCleanupInterval(), time.Hour)
+	Every("upload-sessions-cleanup", interval, func(ctx context.Context) error {
+		total, err := repositories.CleanupUploadSessions(ctx)
+		fmt.Printf("Removed %d expired upload sessions\n", total)
+		return err
+	})
+}
@@ -1,27 +1,55 @@
 package main
 
 import (
+	"follooow-be/commands"
 	"follooow-be/configs"
+	"follooow-be/jobs"
 	"follooow-be/routes"
+	"follooow-be/storage"
+
+	"log"
+	"os"
 
 	"github.com/labstack/echo/v4"
 )
 
 func main() {
+	// run one-off command instead of server, ex: go run main.go backfill-images --dry-run
+	if len(os.Args) > 1 {
+		if err := commands.Run(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	e := echo.New()
 
 	// run database
 	configs.ConnectDB()
 
-	// initialize Cloudinary
-	configs.InitCloudinary()
+	// initialize storage of uploaded files
+	storage.Default()
 
 	// routes
 	routes.InfluencerRoute(e)
 	routes.NewsRoute(e)
 	routes.GalleriesRoute(e)
+	routes.CollectionsRoute(e)
+	routes.TagsRoute(e)
+	routes.CategoriesRoute(e)
+	routes.TrendingRoute(e)
 	routes.UserRoute(e)
 	routes.MediaRoute(e)
+	routes.ReaderRoute(e)
+	routes.AdminRoute(e)
+	routes.StorageRoute(e)
+
+	// background jobs
+	jobs.StartSocialSnapshots()
+	jobs.StartUploadSessionsCleanup()
+	jobs.StartOrphanMediaSweep()
+	jobs.StartTrendingScores()
+	jobs.FailInterruptedGalleryImports()
 
 	e.Logger.Fatal(e.Start(":20223"))
 }
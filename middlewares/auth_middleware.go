@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"follooow-be/models"
+	"follooow-be/repositories"
+	"follooow-be/responses"
+	"follooow-be/utils"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReaderAuth allows request with valid reader token only
+// reader id is available on handler by c.Get("reader_id")
+func ReaderAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		readerId, err := utils.ParseToken(utils.TokenReader, bearerToken(c))
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, responses.GlobalResponse{
+				Status:  http.StatusUnauthorized,
+				Message: "error",
+				Data:    &echo.Map{"error": "invalid or expired token"},
+			})
+		}
+
+		c.Set("reader_id", readerId)
+		return next(c)
+	}
+}
+
+// UserAuth allows request with valid user token only, ex: editor uploading media
+// user id and role are available on handler by c.Get("user_id") and c.Get("user_role")
+// role is read on every request, so changed role applies to issued tokens
+func UserAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		userId, err := utils.ParseToken(utils.TokenUser, bearerToken(c))
+		if err != nil {
+			return unauthorized(c)
+		}
+
+		objId, err := primitive.ObjectIDFromHex(userId)
+		if err != nil {
+			return unauthorized(c)
+		}
+
+		// deleted user can't use its token anymore
+		user, err := repositories.FindUserByID(objId)
+		if err != nil {
+			return unauthorized(c)
+		}
+
+		c.Set("user_id", userId)
+		c.Set("user_role", user.Role)
+		return next(c)
+	}
+}
+
+// AdminAuth allows request of admin user only, ex: admin reports
+func AdminAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return UserAuth(func(c echo.Context) error {
+		if role, _ := c.Get("user_role").(string); role != models.UserRoleAdmin {
+			return c.JSON(http.StatusForbidden, responses.GlobalResponse{
+				Status:  http.StatusForbidden,
+				Message: "error",
+				Data:    &echo.Map{"error": "admin role is required"},
+			})
+		}
+		return next(c)
+	})
+}
+
+// UploadAuth allows request of user whose role may upload on directory, ex: UploadAuth("galleries")
+func UploadAuth(directory string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return UserAuth(func(c echo.Context) error {
+			role, _ := c.Get("user_role").(string)
+			if _, err := utils.AuthorizeUploadDirectory(role, directory); err != nil {
+				return c.JSON(http.StatusForbidden, responses.GlobalResponse{
+					Status:  http.StatusForbidden,
+					Message: "Upload to this directory is not allowed",
+					Data:    &echo.Map{"error": err.Error()},
+				})
+			}
+			return next(c)
+		})
+	}
+}
+
+func unauthorized(c echo.Context) error {
+	return c.JSON(http.StatusUnauthorized, responses.GlobalResponse{
+		Status:  http.StatusUnauthorized,
+		Message: "error",
+		Data:    &echo.Map{"error": "invalid or expired token"},
+	})
+}
+
+// bearerToken gets token from "Authorization: Bearer <token>" header
+func bearerToken(c echo.Context) string {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if !strings.HasPrefix(header, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+}
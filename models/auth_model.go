@@ -8,6 +8,7 @@ type LoginRequest struct {
 type LoginResponse struct {
 	UserID    string `json:"user_id"`
 	Username  string `json:"username"`
+	Role      string `json:"role"`
 	Token     string `json:"token,omitempty"`
 	Message   string `json:"message"`
 }
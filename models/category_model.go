@@ -0,0 +1,35 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// editorial section of news and galleries, ex: Music, Drama, Fashion or Sport
+// category without parent is top section, siblings are sorted by order then name
+type CategoryModel struct {
+	Id             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name           string             `json:"name" bson:"name"`
+	Slug           string             `json:"slug" bson:"slug"`
+	Description    string             `json:"description" bson:"description"`
+	Labels         map[string]string  `json:"labels" bson:"labels"`
+	ParentID       string             `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	Order          int                `json:"order" bson:"order"`
+	Label          string             `json:"label,omitempty" bson:"-"`
+	NewsCount      int64              `json:"news_count" bson:"-"`
+	GalleriesCount int64              `json:"galleries_count" bson:"-"`
+	Count          int64              `json:"count" bson:"-"`
+	Children       []CategoryModel    `json:"children,omitempty" bson:"-"`
+	CreatedOn      int64              `json:"created_on" bson:"created_on"`
+	UpdatedOn      int64              `json:"updated_on" bson:"updated_on"`
+}
+
+// labels is name of category by language, ex: {"ID": "Musik", "EN": "Music"}
+// slug is generated from name when it is empty
+type PayloadCategory struct {
+	Name        string            `json:"name,omitempty"`
+	Slug        string            `json:"slug,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	ParentID    string            `json:"parent_id,omitempty"`
+	Order       int               `json:"order,omitempty"`
+}
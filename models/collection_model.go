@@ -0,0 +1,41 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// group of galleries and news of one event, ex: fashion week or award show
+// items are ordered by editor, item type is the same as timeline item type (gallery or news)
+type CollectionModel struct {
+	Id              primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	Title           string                `json:"title" bson:"title"`
+	Slug            string                `json:"slug" bson:"slug"`
+	Description     string                `json:"description" bson:"description"`
+	Cover           string                `json:"cover" bson:"cover"`
+	Lang            string                `json:"lang" bson:"lang"`
+	Items           []CollectionItemModel `json:"items" bson:"items"`
+	Contents        []TimelineItemModel   `json:"contents,omitempty" bson:"-"`
+	CoverRenditions ImageRenditionsModel  `json:"cover_renditions,omitempty" bson:"-"`
+	CreatedOn       int64                 `json:"created_on" bson:"created_on"`
+	UpdatedOn       int64                 `json:"updated_on" bson:"updated_on"`
+}
+
+// member of collection, id is gallery id or news id
+type CollectionItemModel struct {
+	Type string `json:"type" bson:"type"`
+	Id   string `json:"id" bson:"id"`
+}
+
+// slug is generated from title when it is empty
+type PayloadCollection struct {
+	Title       string                `json:"title,omitempty"`
+	Slug        string                `json:"slug,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Cover       string                `json:"cover,omitempty"`
+	Lang        string                `json:"lang,omitempty"`
+	Items       []CollectionItemModel `json:"items,omitempty"`
+}
+
+type PayloadCollectionItems struct {
+	Items []CollectionItemModel `json:"items"`
+}
@@ -0,0 +1,32 @@
+package models
+
+// manifest.json of exported gallery ZIP, written after every image so failed images are listed too
+type GalleryExportManifestModel struct {
+	Id          string                    `json:"id"`
+	Title       string                    `json:"title"`
+	Description string                    `json:"description,omitempty"`
+	Lang        string                    `json:"lang"`
+	Url         string                    `json:"url"`
+	Tags        []string                  `json:"tags"`
+	Credit      string                    `json:"credit,omitempty"`
+	Influencers []string                  `json:"influencers"`
+	Images      []GalleryExportImageModel `json:"images"`
+	ExportedOn  int64                     `json:"exported_on"`
+}
+
+// image of exported gallery, file is its path inside the ZIP, empty when it could not be exported
+// credit of the image is shown instead of gallery credit when it is set
+type GalleryExportImageModel struct {
+	File    string `json:"file,omitempty"`
+	Id      string `json:"id"`
+	Type    string `json:"type"`
+	Caption string `json:"caption"`
+	IsCover bool   `json:"is_cover"`
+	Width   int    `json:"width,omitempty"`
+	Height  int    `json:"height,omitempty"`
+	Url     string `json:"url"`
+	Error   string `json:"error,omitempty"`
+
+	// credit and license of the image
+	ImageCreditModel
+}
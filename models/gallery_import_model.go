@@ -0,0 +1,37 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status of gallery import
+const (
+	GalleryImportQueued    = "queued"
+	GalleryImportRunning   = "running"
+	GalleryImportCompleted = "completed"
+	GalleryImportFailed    = "failed"
+)
+
+// import of gallery from ZIP archive, images are uploaded in background and progress is polled by its id
+// gallery is created once every image is processed, images failed to upload are skipped and reported
+type GalleryImportModel struct {
+	Id         primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	Filename   string                `json:"filename" bson:"filename"`
+	Title      string                `json:"title" bson:"title"`
+	Order      string                `json:"order" bson:"order"`
+	Status     string                `json:"status" bson:"status"`
+	Total      int                   `json:"total" bson:"total"`
+	Processed  int                   `json:"processed" bson:"processed"`
+	Failures   []ImportFailureModel  `json:"failures" bson:"failures"`
+	Duplicates []DuplicateImageModel `json:"duplicates,omitempty" bson:"duplicates,omitempty"`
+	GalleryID  string                `json:"gallery_id,omitempty" bson:"gallery_id,omitempty"`
+	Error      string                `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedOn  int64                 `json:"created_on" bson:"created_on"`
+	UpdatedOn  int64                 `json:"updated_on" bson:"updated_on"`
+}
+
+// image of archive which is not imported
+type ImportFailureModel struct {
+	Filename string `json:"filename" bson:"filename"`
+	Error    string `json:"error" bson:"error"`
+}
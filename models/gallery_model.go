@@ -4,12 +4,55 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// type of gallery media, images stored before videos are supported have no type
+const (
+	MediaImage = "image"
+	MediaVideo = "video"
+)
+
+// license type of gallery image, own is shot by our team
+const (
+	ImageLicenseOwn             = "own"
+	ImageLicenseEditorial       = "editorial"
+	ImageLicenseRightsManaged   = "rights-managed"
+	ImageLicenseRoyaltyFree     = "royalty-free"
+	ImageLicenseCreativeCommons = "creative-commons"
+	ImageLicensePress           = "press"
+)
+
+// photo credit and licensing of gallery image, shown next to the image
+// license expires on is in milliseconds, image with expired license must be taken down
+type ImageCreditModel struct {
+	Credit           string `json:"credit,omitempty" bson:"credit,omitempty"`
+	SourceUrl        string `json:"source_url,omitempty" bson:"source_url,omitempty"`
+	License          string `json:"license,omitempty" bson:"license,omitempty"`
+	LicenseExpiresOn int64  `json:"license_expires_on,omitempty" bson:"license_expires_on,omitempty"`
+	LicenseExpired   bool   `json:"license_expired,omitempty" bson:"-"`
+}
+
 type ImageModel struct {
-	IsCover   bool   `json:"is_cover, omitempty" validate:"required"`
-	Url       string `json:"url, omitempty" validate:"required"`
-	Caption   string `json:"caption, omitempty" validate:"required"`
-	CreatedOn int    `json:"created_on, omitempty" validate:"required"`
-	UpdatedOn int    `json:"updated_on, omitempty" validate:"required"`
+	Id            string               `json:"id,omitempty" bson:"id,omitempty"`
+	PublicID      string               `json:"public_id,omitempty" bson:"public_id,omitempty"`
+	Width         int                  `json:"width,omitempty" bson:"width,omitempty"`
+	Height        int                  `json:"height,omitempty" bson:"height,omitempty"`
+	Bytes         int                  `json:"bytes,omitempty" bson:"bytes,omitempty"`
+	Format        string               `json:"format,omitempty" bson:"format,omitempty"`
+	DominantColor string               `json:"dominant_color,omitempty" bson:"dominant_color,omitempty"`
+	Type          string               `json:"type" bson:"type,omitempty"`
+	Duration      float64              `json:"duration,omitempty" bson:"duration,omitempty"`
+	Poster        string               `json:"poster,omitempty" bson:"poster,omitempty"`
+	Hash          string               `json:"hash,omitempty" bson:"hash,omitempty"`
+	HashBands     []string             `json:"-" bson:"hash_bands,omitempty"`
+	IsCover       bool                 `json:"is_cover, omitempty" validate:"required"`
+	Url           string               `json:"url, omitempty" validate:"required"`
+	Caption       string               `json:"caption, omitempty" validate:"required"`
+	CreatedOn     int                  `json:"created_on, omitempty" validate:"required"`
+	UpdatedOn     int                  `json:"updated_on, omitempty" validate:"required"`
+	Renditions    ImageRenditionsModel `json:"renditions,omitempty" bson:"-"`
+	Playback      map[string]string    `json:"playback,omitempty" bson:"-"`
+
+	// credit and license of the image
+	ImageCreditModel `bson:",inline"`
 }
 
 type GalleryModel struct {
@@ -23,8 +66,11 @@ type GalleryModel struct {
 	InfluencersData []InfluencerSmallDataModel `json:"influencers_data,omitempty"  validate:"required"`
 	Lang            string                     `json:"lang, omitempty"  validate:"required"`
 	Views           int                        `json:"views, omitempty"  validate:"required"`
+	TrendingScore   float64                    `json:"trending_score,omitempty" bson:"trending_score,omitempty"`
 	Slug            string                     `json:"slug, omitempty"  validate:"required"`
 	Tags            []string                   `json:"tags,omitempty" bson:"tags,omitempty"`
+	CategoryID      string                     `json:"category_id,omitempty" bson:"category_id,omitempty"`
+	CategoryIDs     []string                   `json:"category_ids,omitempty" bson:"category_ids,omitempty"`
 	AuthorID        string                     `json:"author_id,omitempty" bson:"author_id,omitempty"`
 	Author          *AuthorModel               `json:"author,omitempty" bson:"-"`
 }
@@ -36,4 +82,19 @@ type PayloadGallery struct {
 	Influencers []string     `json:"influencers, omitempty"`
 	Lang        string       `json:"lang,omitempty"`
 	Tags        []string     `json:"tags,omitempty"`
+	CategoryID  string       `json:"category_id,omitempty"`
+	CategoryIDs []string     `json:"category_ids,omitempty"`
+}
+
+// fields which are not sent are kept, empty value removes them
+type PayloadGalleryImage struct {
+	Caption          *string `json:"caption,omitempty"`
+	Credit           *string `json:"credit,omitempty"`
+	SourceUrl        *string `json:"source_url,omitempty"`
+	License          *string `json:"license,omitempty"`
+	LicenseExpiresOn *int64  `json:"license_expires_on,omitempty"`
+}
+
+type PayloadGalleryImagesOrder struct {
+	Ids []string `json:"ids,omitempty"`
 }
@@ -0,0 +1,20 @@
+package models
+
+// gallery image with near duplicate perceptual hash
+type DuplicateImageModel struct {
+	GalleryID    string `json:"gallery_id"`
+	GalleryTitle string `json:"gallery_title"`
+	GalleryURL   string `json:"gallery_url"`
+	ImageID      string `json:"image_id"`
+	Url          string `json:"url"`
+	Hash         string `json:"hash"`
+	Distance     int    `json:"distance"`
+	// id of uploaded image this image is duplicate of
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+}
+
+// images which are near duplicate of each other
+type DuplicateClusterModel struct {
+	Images    []DuplicateImageModel `json:"images"`
+	Galleries int                   `json:"galleries"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// gallery image which license expires soon or is already expired, it must be taken down once expired
+type ExpiringImageLicenseModel struct {
+	GalleryID        string `json:"gallery_id"`
+	GalleryTitle     string `json:"gallery_title"`
+	GalleryURL       string `json:"gallery_url"`
+	ImageID          string `json:"image_id"`
+	Url              string `json:"url"`
+	Caption          string `json:"caption"`
+	Credit           string `json:"credit"`
+	SourceUrl        string `json:"source_url,omitempty"`
+	License          string `json:"license"`
+	LicenseExpiresOn int64  `json:"license_expires_on"`
+	LicenseExpired   bool   `json:"license_expired"`
+}
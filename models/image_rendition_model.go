@@ -0,0 +1,13 @@
+package models
+
+// resized image, webp and avif are empty when the storage can't serve them
+type ImageRenditionModel struct {
+	Url    string `json:"url"`
+	Webp   string `json:"webp,omitempty"`
+	Avif   string `json:"avif,omitempty"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// renditions by its name: thumb, card, full and og-image
+type ImageRenditionsModel map[string]ImageRenditionModel
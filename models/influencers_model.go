@@ -6,20 +6,24 @@ import (
 )
 
 type InfluencerModel struct {
-	Id          primitive.ObjectID      `json:"id,omitempty" bson:"_id,omitempty" validate:"required"`
-	Name        string                  `json:"name,omitempty" validate:"required"`
-	Avatar      string                  `json:"avatar,omitempty"`
-	Bio         string                  `json:"bio,omitempty"`
-	UpdatedOn   int                     `json:"updated_on,omitempty" bson:"updated_on,omitempty"`
-	Nationality interface{}             `json:"nationality,omitempty"`
-	Gender      string                  `json:"gender,omitempty"`
-	Visits      int                     `json:"visits"`
-	Socials     []InfluencerSocial      `json:"socials,omitempty"`
-	Label       []string                `json:"label,omitempty"`
-	Views       int                     `json:"views"`
-	Code        string                  `json:"code,omitempty"`
-	BestMoments []InfluencerBestMoments `json:"best_moments,omitempty" bson:"best_moments,omitempty"`
-	Stats       StatsInfluencerModel    `json:"stats,omitempty" `
+	Id               primitive.ObjectID         `json:"id,omitempty" bson:"_id,omitempty" validate:"required"`
+	Name             string                     `json:"name,omitempty" validate:"required"`
+	Avatar           string                     `json:"avatar,omitempty"`
+	Bio              string                     `json:"bio,omitempty"`
+	UpdatedOn        int                        `json:"updated_on,omitempty" bson:"updated_on,omitempty"`
+	Nationality      interface{}                `json:"nationality,omitempty"`
+	Gender           string                     `json:"gender,omitempty"`
+	Visits           int                        `json:"visits"`
+	Socials          []InfluencerSocial         `json:"socials,omitempty"`
+	Label            []string                   `json:"label,omitempty"`
+	Views            int                        `json:"views"`
+	TrendingScore    float64                    `json:"trending_score,omitempty" bson:"trending_score,omitempty"`
+	Followers        int                        `json:"followers" bson:"followers"`
+	Code             string                     `json:"code,omitempty"`
+	BestMoments      []InfluencerBestMoments    `json:"best_moments,omitempty" bson:"best_moments,omitempty"`
+	Stats            StatsInfluencerModel       `json:"stats,omitempty" `
+	Members          []InfluencerSmallDataModel `json:"members,omitempty" bson:"-"`
+	AvatarRenditions ImageRenditionsModel       `json:"avatar_renditions,omitempty" bson:"-"`
 }
 
 type StatsInfluencerModel struct {
@@ -43,18 +47,24 @@ type InsertInfluencerModel struct {
 }
 
 type InfluencerSmallDataModel struct {
-	Id     primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty" validate:"required"`
-	Name   string             `json:"name,omitempty" validate:"required"`
-	Avatar string             `json:"avatar,omitempty"`
+	Id               primitive.ObjectID   `json:"id,omitempty" bson:"_id,omitempty" validate:"required"`
+	Name             string               `json:"name,omitempty" validate:"required"`
+	Avatar           string               `json:"avatar,omitempty"`
+	AvatarRenditions ImageRenditionsModel `json:"avatar_renditions,omitempty" bson:"-"`
 }
 
 type InfluencerSocial struct {
-	Link  string `json:"link,omitempty"`
-	Type  string `json:"type,omitempty"`
-	Title string `json:"title,omitempty"`
+	Link               string `json:"link,omitempty"`
+	Type               string `json:"type,omitempty"`
+	Title              string `json:"title,omitempty"`
+	Handle             string `json:"handle,omitempty" bson:"handle,omitempty"`
+	Followers          int64  `json:"followers,omitempty" bson:"followers,omitempty"`
+	IsVerified         bool   `json:"is_verified,omitempty" bson:"is_verified,omitempty"`
+	FollowersUpdatedOn int64  `json:"followers_updated_on,omitempty" bson:"followers_updated_on,omitempty"`
 }
 
 type InfluencerBestMoments struct {
+	Id         string `json:"id,omitempty" bson:"id,omitempty"`
 	Image      string `json:"image,omitempty"`
 	Text       string `json:"text,omitempty"`
 	Year       string `json:"year,omitempty"`
@@ -62,9 +72,19 @@ type InfluencerBestMoments struct {
 	Style      bson.M `json:"style,omitempty"`
 }
 
-// type InfluencerBestMomentsStyle struct {
-// 	Margin string `json:"margin,omitempty"`
-// }
+// payload of best moment sub resource
+// image can be base64 data or url of uploaded image
+type PayloadBestMoment struct {
+	Image      string `json:"image,omitempty"`
+	Text       string `json:"text,omitempty"`
+	Year       string `json:"year,omitempty"`
+	Background string `json:"background,omitempty"`
+	Style      bson.M `json:"style,omitempty"`
+}
+
+type PayloadBestMomentsOrder struct {
+	Ids []string `json:"ids,omitempty"`
+}
 
 type PayloadInfluencer struct {
 	Name        string                  `json:"name, omitempty"`
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// type of content using media of the library
+const (
+	MediaUsageGallery    = "gallery"
+	MediaUsageNews       = "news"
+	MediaUsageInfluencer = "influencer"
+	MediaUsageCollection = "collection"
+)
+
+// uploaded image or video of the media library, every upload is recorded once by its public id
+// usages are found on read, so they are never outdated when content is edited
+type MediaModel struct {
+	Id            primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Type          string               `json:"type" bson:"type"`
+	PublicID      string               `json:"public_id" bson:"public_id"`
+	Url           string               `json:"url" bson:"url"`
+	Directory     string               `json:"directory" bson:"directory"`
+	Filename      string               `json:"filename,omitempty" bson:"filename,omitempty"`
+	Width         int                  `json:"width,omitempty" bson:"width,omitempty"`
+	Height        int                  `json:"height,omitempty" bson:"height,omitempty"`
+	Bytes         int                  `json:"bytes,omitempty" bson:"bytes,omitempty"`
+	Format        string               `json:"format,omitempty" bson:"format,omitempty"`
+	DominantColor string               `json:"dominant_color,omitempty" bson:"dominant_color,omitempty"`
+	Duration      float64              `json:"duration,omitempty" bson:"duration,omitempty"`
+	Poster        string               `json:"poster,omitempty" bson:"poster,omitempty"`
+	Hash          string               `json:"hash,omitempty" bson:"hash,omitempty"`
+	Tags          []string             `json:"tags" bson:"tags,omitempty"`
+	UploaderID    string               `json:"uploader_id,omitempty" bson:"uploader_id,omitempty"`
+	Uploader      *AuthorModel         `json:"uploader,omitempty" bson:"-"`
+	Usages        []MediaUsageModel    `json:"usages,omitempty" bson:"-"`
+	Renditions    ImageRenditionsModel `json:"renditions,omitempty" bson:"-"`
+	Playback      map[string]string    `json:"playback,omitempty" bson:"-"`
+	CreatedOn     int64                `json:"created_on" bson:"created_on"`
+	UpdatedOn     int64                `json:"updated_on" bson:"updated_on"`
+}
+
+// content using media, ex: gallery with media as one of its images
+type MediaUsageModel struct {
+	Type  string `json:"type"`
+	Id    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type PayloadMedia struct {
+	Tags []string `json:"tags"`
+}
+
+type PayloadGalleryMedia struct {
+	MediaIds []string `json:"media_ids"`
+}
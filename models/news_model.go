@@ -10,19 +10,24 @@ type AuthorModel struct {
 }
 
 type NewsModel struct {
-	Id              primitive.ObjectID         `json:"id,omitempty" bson:"_id,omitempty" validate:"required"`
-	Title           string                     `json:"title,omitempty" validate:"required"`
-	Thumbnail       string                     `json:"thumbnail,omitempty" validate:"required"`
-	Content         string                     `json:"content,omitempty" validate:"required"`
-	Views           int                        `json:"views,omitempty" validate:"required"`
-	CreatedOn       int                        `json:"created_on,omitempty" bson:"created_on,omitempty" validate:"required"`
-	UpdatedOn       int                        `json:"updated_on,omitempty" bson:"updated_on,omitempty" validate:"required"`
-	Tags            []string                   `json:"tags,omitempty" validate:"required"`
-	Influencers     []string                   `json:"influencers,omitempty"`
-	InfluencersData []InfluencerSmallDataModel `json:"influencers_data,omitempty"`
-	Slug            string                     `json:"slug,omitempty"`
-	AuthorID        string                     `json:"author_id,omitempty" bson:"author_id,omitempty"`
-	Author          *AuthorModel               `json:"author,omitempty" bson:"-"`
+	Id                  primitive.ObjectID         `json:"id,omitempty" bson:"_id,omitempty" validate:"required"`
+	Title               string                     `json:"title,omitempty" validate:"required"`
+	Thumbnail           string                     `json:"thumbnail,omitempty" validate:"required"`
+	Content             string                     `json:"content,omitempty" validate:"required"`
+	Views               int                        `json:"views,omitempty" validate:"required"`
+	TrendingScore       float64                    `json:"trending_score,omitempty" bson:"trending_score,omitempty"`
+	CreatedOn           int                        `json:"created_on,omitempty" bson:"created_on,omitempty" validate:"required"`
+	UpdatedOn           int                        `json:"updated_on,omitempty" bson:"updated_on,omitempty" validate:"required"`
+	Tags                []string                   `json:"tags,omitempty" validate:"required"`
+	Influencers         []string                   `json:"influencers,omitempty"`
+	InfluencersData     []InfluencerSmallDataModel `json:"influencers_data,omitempty"`
+	Slug                string                     `json:"slug,omitempty"`
+	Lang                string                     `json:"lang,omitempty" bson:"lang,omitempty"`
+	CategoryID          string                     `json:"category_id,omitempty" bson:"category_id,omitempty"`
+	CategoryIDs         []string                   `json:"category_ids,omitempty" bson:"category_ids,omitempty"`
+	AuthorID            string                     `json:"author_id,omitempty" bson:"author_id,omitempty"`
+	Author              *AuthorModel               `json:"author,omitempty" bson:"-"`
+	ThumbnailRenditions ImageRenditionsModel       `json:"thumbnail_renditions,omitempty" bson:"-"`
 }
 
 type PayloadNews struct {
@@ -32,4 +37,6 @@ type PayloadNews struct {
 	Influencers []string `json:"influencers,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
 	Lang        string   `json:"lang,omitempty"`
+	CategoryID  string   `json:"category_id,omitempty"`
+	CategoryIDs []string `json:"category_ids,omitempty"`
 }
@@ -0,0 +1,24 @@
+package models
+
+// stored file not used by news, galleries or influencers
+// orphan younger than grace period is kept, it may be used soon, ex: just uploaded to media library
+type OrphanMediaModel struct {
+	PublicID     string `json:"public_id"`
+	ResourceType string `json:"resource_type"`
+	Bytes        int    `json:"bytes"`
+	CreatedOn    int64  `json:"created_on"`
+	Deleted      bool   `json:"deleted"`
+	Error        string `json:"error,omitempty"`
+}
+
+// result of orphaned files sweep, dry run only reports orphans
+type OrphanSweepModel struct {
+	DryRun      bool               `json:"dry_run"`
+	GracePeriod string             `json:"grace_period"`
+	Folders     []string           `json:"folders"`
+	Scanned     int                `json:"scanned"`
+	Orphans     []OrphanMediaModel `json:"orphans"`
+	OrphanBytes int64              `json:"orphan_bytes"`
+	Deleted     int                `json:"deleted"`
+	Failed      int                `json:"failed"`
+}
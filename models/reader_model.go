@@ -0,0 +1,41 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// reader is public account which follows influencers, separated from editor users
+type ReaderModel struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Username    string             `json:"username,omitempty" bson:"username,omitempty"`
+	DisplayName string             `json:"display_name,omitempty" bson:"display_name,omitempty"`
+	Password    string             `json:"password,omitempty" bson:"password,omitempty"`
+	CreatedAt   int64              `json:"created_at,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt   int64              `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
+}
+
+type CreateReaderModel struct {
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Password    string `json:"password,omitempty"`
+}
+
+type ReaderResponse struct {
+	ID          primitive.ObjectID `json:"id,omitempty"`
+	Username    string             `json:"username,omitempty"`
+	DisplayName string             `json:"display_name,omitempty"`
+	CreatedAt   int64              `json:"created_at,omitempty"`
+}
+
+type ReaderLoginResponse struct {
+	Reader ReaderResponse `json:"reader"`
+	Token  string         `json:"token"`
+}
+
+// follow of reader to influencer
+type FollowModel struct {
+	Id           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	ReaderID     string             `json:"reader_id,omitempty" bson:"reader_id,omitempty"`
+	InfluencerID string             `json:"influencer_id,omitempty" bson:"influencer_id,omitempty"`
+	CreatedOn    int64              `json:"created_on,omitempty" bson:"created_on,omitempty"`
+}
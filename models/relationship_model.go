@@ -0,0 +1,37 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// types of relationship between influencers
+const (
+	RelationshipMemberOf         = "member_of"
+	RelationshipCollaboratedWith = "collaborated_with"
+	RelationshipSibling          = "sibling"
+	RelationshipManagedBy        = "managed_by"
+)
+
+// relationship from influencer to related influencer
+// ex: Lisa member_of BLACKPINK, Lisa managed_by YG Entertainment
+type RelationshipModel struct {
+	Id           primitive.ObjectID        `json:"id,omitempty" bson:"_id,omitempty"`
+	InfluencerID string                    `json:"influencer_id,omitempty" bson:"influencer_id,omitempty"`
+	RelatedID    string                    `json:"related_id,omitempty" bson:"related_id,omitempty"`
+	Type         string                    `json:"type,omitempty" bson:"type,omitempty"`
+	StartOn      int64                     `json:"start_on,omitempty" bson:"start_on,omitempty"`
+	EndOn        int64                     `json:"end_on,omitempty" bson:"end_on,omitempty"`
+	Note         string                    `json:"note,omitempty" bson:"note,omitempty"`
+	CreatedOn    int64                     `json:"created_on,omitempty" bson:"created_on,omitempty"`
+	UpdatedOn    int64                     `json:"updated_on,omitempty" bson:"updated_on,omitempty"`
+	Influencer   *InfluencerSmallDataModel `json:"influencer,omitempty" bson:"-"`
+	Related      *InfluencerSmallDataModel `json:"related,omitempty" bson:"-"`
+}
+
+type PayloadRelationship struct {
+	RelatedID string `json:"related_id,omitempty"`
+	Type      string `json:"type,omitempty"`
+	StartOn   int64  `json:"start_on,omitempty"`
+	EndOn     int64  `json:"end_on,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
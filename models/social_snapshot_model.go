@@ -0,0 +1,43 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// one row of social_snapshots collection
+// recorded every time the social fetcher reads follower count of an account
+type SocialSnapshotModel struct {
+	Id           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	InfluencerID string             `json:"influencer_id,omitempty" bson:"influencer_id,omitempty"`
+	Type         string             `json:"type,omitempty" bson:"type,omitempty"`
+	Handle       string             `json:"handle,omitempty" bson:"handle,omitempty"`
+	Followers    int64              `json:"followers" bson:"followers"`
+	IsVerified   bool               `json:"is_verified,omitempty" bson:"is_verified,omitempty"`
+	CreatedOn    int64              `json:"created_on,omitempty" bson:"created_on,omitempty"`
+}
+
+type SocialGrowthPointModel struct {
+	CreatedOn int64 `json:"created_on"`
+	Followers int64 `json:"followers"`
+}
+
+// growth curve of single social account on given period
+type SocialGrowthModel struct {
+	Type       string                   `json:"type"`
+	Handle     string                   `json:"handle,omitempty"`
+	Points     []SocialGrowthPointModel `json:"points"`
+	Gained     int64                    `json:"gained"`
+	GrowthRate float64                  `json:"growth_rate"`
+}
+
+// item of "fastest growing" leaderboard
+type SocialLeaderboardModel struct {
+	InfluencerID   string                    `json:"influencer_id" bson:"influencer_id"`
+	Type           string                    `json:"type" bson:"type"`
+	Handle         string                    `json:"handle,omitempty" bson:"handle"`
+	FirstFollowers int64                     `json:"first_followers" bson:"first_followers"`
+	LastFollowers  int64                     `json:"last_followers" bson:"last_followers"`
+	Gained         int64                     `json:"gained" bson:"gained"`
+	GrowthRate     float64                   `json:"growth_rate" bson:"growth_rate"`
+	Influencer     *InfluencerSmallDataModel `json:"influencer,omitempty" bson:"-"`
+}
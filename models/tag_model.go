@@ -0,0 +1,34 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// canonical tag of news and galleries, contents are saved with the tag name
+// synonyms and spelling variants (ex: kpop, k pop) are replaced with the name when contents are saved
+type TagModel struct {
+	Id             primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name           string             `json:"name" bson:"name"`
+	Slug           string             `json:"slug" bson:"slug"`
+	Labels         map[string]string  `json:"labels" bson:"labels"`
+	Synonyms       []string           `json:"synonyms" bson:"synonyms"`
+	Keys           []string           `json:"-" bson:"keys"`
+	NewsCount      int64              `json:"news_count" bson:"news_count"`
+	GalleriesCount int64              `json:"galleries_count" bson:"galleries_count"`
+	Count          int64              `json:"count" bson:"count"`
+	Label          string             `json:"label,omitempty" bson:"-"`
+	CreatedOn      int64              `json:"created_on" bson:"created_on"`
+	UpdatedOn      int64              `json:"updated_on" bson:"updated_on"`
+}
+
+// labels is name of tag by language, ex: {"ID": "Musik", "EN": "Music"}
+type PayloadTag struct {
+	Name     string            `json:"name,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Synonyms []string          `json:"synonyms,omitempty"`
+}
+
+// tags are slugs of tags or free tags merged to the tag
+type PayloadTagMerge struct {
+	Tags []string `json:"tags"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// types of timeline item
+const (
+	TimelineNews       = "news"
+	TimelineGallery    = "gallery"
+	TimelineBestMoment = "best_moment"
+)
+
+// single item of influencer timeline
+// only one of News, Gallery or BestMoment is filled, depends on Type
+type TimelineItemModel struct {
+	Type       string                 `json:"type"`
+	Id         string                 `json:"id"`
+	Date       int64                  `json:"date"`
+	News       *NewsModel             `json:"news,omitempty"`
+	Gallery    *GalleryModel          `json:"gallery,omitempty"`
+	BestMoment *InfluencerBestMoments `json:"best_moment,omitempty"`
+}
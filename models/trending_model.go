@@ -0,0 +1,49 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// types of trending item
+const (
+	TrendingNews       = TimelineNews
+	TrendingGallery    = TimelineGallery
+	TrendingInfluencer = "influencer"
+)
+
+// languages of trending scores, views on other languages are not counted
+var TrendingLangs = []string{"ID", "EN"}
+
+// views of news, gallery or influencer page in one hour, hour is its first millisecond
+// buckets older than the trending window are removed by the trending job
+type ViewBucketModel struct {
+	Id     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Type   string             `json:"type" bson:"type"`
+	ItemID string             `json:"item_id" bson:"item_id"`
+	Lang   string             `json:"lang" bson:"lang"`
+	Hour   int64              `json:"hour" bson:"hour"`
+	Views  int64              `json:"views" bson:"views"`
+}
+
+// trending score of item on language, views on the window weighted by their age
+// computed on is time of the trending job run
+type TrendingScoreModel struct {
+	Type       string  `json:"type" bson:"type"`
+	ItemID     string  `json:"item_id" bson:"item_id"`
+	Lang       string  `json:"lang" bson:"lang"`
+	Score      float64 `json:"score" bson:"score"`
+	Views      int64   `json:"views" bson:"views"`
+	ComputedOn int64   `json:"computed_on" bson:"computed_on"`
+}
+
+// single item of trending list
+// only one of News, Gallery or Influencer is filled, depends on Type
+type TrendingItemModel struct {
+	Type       string                    `json:"type"`
+	Id         string                    `json:"id"`
+	Score      float64                   `json:"score"`
+	Views      int64                     `json:"views"`
+	News       *NewsModel                `json:"news,omitempty"`
+	Gallery    *GalleryModel             `json:"gallery,omitempty"`
+	Influencer *InfluencerSmallDataModel `json:"influencer,omitempty"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// status of chunked upload
+const (
+	UploadSessionPending    = "pending"
+	UploadSessionCompleting = "completing"
+	UploadSessionCompleted  = "completed"
+)
+
+// chunked upload of single image or video, chunks are stored on server temp folder until completed
+// result is response of the completed upload, returned again when complete is retried
+type UploadSessionModel struct {
+	Id          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Filename    string             `json:"filename" bson:"filename"`
+	Directory   string             `json:"directory" bson:"directory"`
+	Size        int64              `json:"size" bson:"size"`
+	ChunkSize   int64              `json:"chunk_size" bson:"chunk_size"`
+	TotalChunks int                `json:"total_chunks" bson:"total_chunks"`
+	Received    []int              `json:"received" bson:"received"`
+	Sha256      string             `json:"sha256,omitempty" bson:"sha256,omitempty"`
+	UploaderID  string             `json:"uploader_id,omitempty" bson:"uploader_id,omitempty"`
+	Tags        []string           `json:"tags,omitempty" bson:"tags,omitempty"`
+	Status      string             `json:"status" bson:"status"`
+	// chunks being written to the file, session is completed only when no chunk is written
+	Writing   int                 `json:"-" bson:"writing,omitempty"`
+	Result    *UploadedMediaModel `json:"result,omitempty" bson:"result,omitempty"`
+	CreatedOn int64               `json:"created_on" bson:"created_on"`
+	UpdatedOn int64               `json:"updated_on" bson:"updated_on"`
+	ExpiresOn int64               `json:"expires_on" bson:"expires_on"`
+}
+
+// uploaded image or video, video has duration, poster and playback urls
+type UploadedMediaModel struct {
+	MediaID   string            `json:"media_id,omitempty" bson:"media_id,omitempty"`
+	Type      string            `json:"type" bson:"type"`
+	Url       string            `json:"url" bson:"url"`
+	PublicID  string            `json:"public_id" bson:"public_id"`
+	Format    string            `json:"format" bson:"format"`
+	Size      int               `json:"size" bson:"size"`
+	Directory string            `json:"directory" bson:"directory"`
+	Duration  float64           `json:"duration,omitempty" bson:"duration,omitempty"`
+	Poster    string            `json:"poster,omitempty" bson:"poster,omitempty"`
+	Playback  map[string]string `json:"playback,omitempty" bson:"playback,omitempty"`
+}
+
+type PayloadUploadSession struct {
+	Filename  string   `json:"filename"`
+	Directory string   `json:"directory"`
+	Size      int64    `json:"size"`
+	ChunkSize int64    `json:"chunk_size,omitempty"`
+	Sha256    string   `json:"sha256,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+}
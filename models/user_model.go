@@ -4,10 +4,18 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// role of user, user without role (ex: new account) has no upload role until it is set by set-user-role command
+const (
+	UserRoleAdmin       = "admin"
+	UserRoleEditor      = "editor"
+	UserRoleContributor = "contributor"
+)
+
 type UserModel struct {
 	ID        primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty" validate:"required"`
 	Username  string              `json:"username,omitempty" validate:"required"`
 	Password  string              `json:"password,omitempty" bson:"password,omitempty" validate:"required"`
+	Role      string              `json:"role,omitempty" bson:"role,omitempty"`
 	CreatedAt int64               `json:"created_at,omitempty" bson:"created_at,omitempty"`
 	UpdatedAt int64               `json:"updated_at,omitempty" bson:"updated_at,omitempty"`
 }
@@ -20,6 +28,7 @@ type CreateUserModel struct {
 type UserResponse struct {
 	ID       primitive.ObjectID `json:"id,omitempty"`
 	Username string              `json:"username,omitempty"`
+	Role     string              `json:"role,omitempty"`
 	CreatedAt int64              `json:"created_at,omitempty"`
 	UpdatedAt int64              `json:"updated_at,omitempty"`
 }
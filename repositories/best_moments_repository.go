@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"follooow-be/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var ErrBestMomentNotFound = errors.New("best moment not found")
+
+// presentation keys allowed on best moment style, used by frontend to render the moment card
+var BestMomentStyleKeys = map[string]bool{
+	"margin":              true,
+	"padding":             true,
+	"color":               true,
+	"background":          true,
+	"background_position": true,
+	"background_size":     true,
+	"text_align":          true,
+	"font_size":           true,
+	"font_weight":         true,
+	"position":            true,
+	"layout":              true,
+	"theme":               true,
+}
+
+// ValidateBestMomentStyle checks every style key is known and every value is string or number
+func ValidateBestMomentStyle(style bson.M) error {
+	for key, value := range style {
+		if !BestMomentStyleKeys[key] {
+			return fmt.Errorf("unknown style key %q", key)
+		}
+
+		switch value.(type) {
+		case string, float64, int, int32, int64:
+		default:
+			return fmt.Errorf("style %q must be string or number", key)
+		}
+	}
+
+	return nil
+}
+
+// function to get influencer with best moments
+// best moments created before moments have id get new id here
+func GetInfluencerBestMoments(ctx context.Context, influencerId string) (models.InfluencerModel, error) {
+	var influencer models.InfluencerModel
+	objId, _ := primitive.ObjectIDFromHex(influencerId)
+
+	err := InfluencersCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&influencer)
+	if err != nil {
+		return influencer, err
+	}
+
+	missingId := false
+	for key := range influencer.BestMoments {
+		if influencer.BestMoments[key].Id == "" {
+			influencer.BestMoments[key].Id = primitive.NewObjectID().Hex()
+			missingId = true
+		}
+	}
+
+	if missingId {
+		err = SetBestMoments(ctx, objId, influencer.BestMoments)
+	}
+
+	return influencer, err
+}
+
+// function to replace all best moments of influencer
+func SetBestMoments(ctx context.Context, influencerId primitive.ObjectID, moments []models.InfluencerBestMoments) error {
+	_, err := InfluencersCollections.UpdateOne(ctx, bson.M{"_id": influencerId}, bson.M{"$set": bson.M{"best_moments": moments}})
+	return err
+}
+
+// function to append best moment at the end of influencer best moments
+func AddBestMoment(ctx context.Context, influencerId primitive.ObjectID, moment models.InfluencerBestMoments) error {
+	_, err := InfluencersCollections.UpdateOne(ctx, bson.M{"_id": influencerId}, bson.M{"$push": bson.M{"best_moments": moment}})
+	return err
+}
+
+// function to replace single best moment by its id
+func UpdateBestMoment(ctx context.Context, influencerId primitive.ObjectID, moment models.InfluencerBestMoments) error {
+	filter := bson.M{"_id": influencerId, "best_moments.id": moment.Id}
+	update := bson.M{"$set": bson.M{"best_moments.$": moment}}
+
+	result, err := InfluencersCollections.UpdateOne(ctx, filter, update)
+	if err == nil && result.MatchedCount < 1 {
+		return ErrBestMomentNotFound
+	}
+	return err
+}
+
+// function to delete single best moment by its id
+func DeleteBestMoment(ctx context.Context, influencerId primitive.ObjectID, momentId string) error {
+	filter := bson.M{"_id": influencerId, "best_moments.id": momentId}
+	update := bson.M{"$pull": bson.M{"best_moments": bson.M{"id": momentId}}}
+
+	result, err := InfluencersCollections.UpdateOne(ctx, filter, update)
+	if err == nil && result.MatchedCount < 1 {
+		return ErrBestMomentNotFound
+	}
+	return err
+}
@@ -0,0 +1,408 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var CategoriesCollections *mongo.Collection = configs.GetCollection(configs.DB, "categories")
+
+// max levels of categories, ex: Music > K-Pop > Comeback
+const CategoryMaxDepth = 3
+
+var (
+	ErrCategoryNotFound  = errors.New("category not found")
+	ErrCategoryName      = errors.New("name is required")
+	ErrCategorySlug      = errors.New("slug is required")
+	ErrCategorySlugTaken = errors.New("slug is already used by other category")
+	ErrCategoryParent    = errors.New("invalid parent category")
+	ErrCategoryInUse     = errors.New("category still has sub categories or contents")
+	ErrContentCategory   = errors.New("invalid category")
+)
+
+// item counts of categories navigation by language, counting every category is slow so it is cached
+var categoryCountsCache = utils.NewCache(5 * time.Minute)
+
+// function to create category, slug is generated from name when it is empty
+func CreateCategory(ctx context.Context, payload models.PayloadCategory) (models.CategoryModel, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	category := models.CategoryModel{
+		Id:          primitive.NewObjectID(),
+		Name:        strings.TrimSpace(payload.Name),
+		Slug:        utils.Slugify(payload.Slug),
+		Description: payload.Description,
+		Labels:      normalizeLabels(payload.Labels),
+		ParentID:    payload.ParentID,
+		Order:       payload.Order,
+		CreatedOn:   now,
+		UpdatedOn:   now,
+	}
+	if category.Slug == "" {
+		category.Slug = utils.Slugify(category.Name)
+	}
+
+	if err := validateCategory(ctx, category); err != nil {
+		return category, err
+	}
+
+	_, err := CategoriesCollections.InsertOne(ctx, category)
+	return category, err
+}
+
+// function to update category by its id or slug, sub categories are moved together with it
+// slug is kept when it is empty so links are not broken
+func UpdateCategory(ctx context.Context, idOrSlug string, payload models.PayloadCategory) (models.CategoryModel, error) {
+	category, err := GetCategory(ctx, idOrSlug)
+	if err != nil {
+		return category, err
+	}
+
+	category.Name = strings.TrimSpace(payload.Name)
+	if payload.Slug != "" {
+		category.Slug = utils.Slugify(payload.Slug)
+	}
+	category.Description = payload.Description
+	category.Labels = normalizeLabels(payload.Labels)
+	category.ParentID = payload.ParentID
+	category.Order = payload.Order
+	category.UpdatedOn = time.Now().UnixNano() / int64(time.Millisecond)
+
+	if err = validateCategory(ctx, category); err != nil {
+		return category, err
+	}
+
+	_, err = CategoriesCollections.ReplaceOne(ctx, bson.M{"_id": category.Id}, category)
+	categoryCountsCache.DeletePrefix("")
+	return category, err
+}
+
+// function to delete category by its id or slug
+// category with sub categories or contents can't be deleted, they must be moved first
+func DeleteCategory(ctx context.Context, idOrSlug string) error {
+	category, err := GetCategory(ctx, idOrSlug)
+	if err != nil {
+		return err
+	}
+
+	children, err := CategoriesCollections.CountDocuments(ctx, bson.M{"parent_id": category.Id.Hex()})
+	if err != nil {
+		return err
+	}
+	if children > 0 {
+		return fmt.Errorf("%w: %d sub categories", ErrCategoryInUse, children)
+	}
+
+	for _, collection := range []*mongo.Collection{NewsCollections, GalleryCollections} {
+		contents, err := collection.CountDocuments(ctx, categoryContentsFilter([]string{category.Id.Hex()}))
+		if err != nil {
+			return err
+		}
+		if contents > 0 {
+			return fmt.Errorf("%w: %d %s", ErrCategoryInUse, contents, collection.Name())
+		}
+	}
+
+	_, err = CategoriesCollections.DeleteOne(ctx, bson.M{"_id": category.Id})
+	categoryCountsCache.DeletePrefix("")
+	return err
+}
+
+// function to get category by its id or slug
+func GetCategory(ctx context.Context, idOrSlug string) (models.CategoryModel, error) {
+	var category models.CategoryModel
+
+	filter := bson.M{"slug": utils.Slugify(idOrSlug)}
+	if objId, err := primitive.ObjectIDFromHex(idOrSlug); err == nil {
+		filter = bson.M{"_id": objId}
+	}
+
+	err := CategoriesCollections.FindOne(ctx, filter).Decode(&category)
+	if err == mongo.ErrNoDocuments {
+		return category, ErrCategoryNotFound
+	}
+	if category.Labels == nil {
+		category.Labels = map[string]string{}
+	}
+	return category, err
+}
+
+// function to get categories tree for navigation, counts include news and galleries of sub categories
+// counts are filtered by language when lang is set
+func GetCategoryTree(ctx context.Context, lang string) ([]models.CategoryModel, error) {
+	categories, err := loadCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = countCategories(ctx, categories, lang); err != nil {
+		return nil, err
+	}
+	for key := range categories {
+		categories[key].Label = localizedLabel(categories[key].Name, categories[key].Labels, lang)
+	}
+
+	return categoryChildren(categories, ""), nil
+}
+
+// function to get category with its sub categories tree and its parents from the top category
+func GetCategoryDetail(ctx context.Context, idOrSlug string, lang string) (models.CategoryModel, []models.CategoryModel, error) {
+	category, err := GetCategory(ctx, idOrSlug)
+	if err != nil {
+		return category, nil, err
+	}
+
+	tree, err := GetCategoryTree(ctx, lang)
+	if err != nil {
+		return category, nil, err
+	}
+
+	breadcrumb := []models.CategoryModel{}
+	found, ok := findCategoryPath(tree, category.Id.Hex(), &breadcrumb)
+	if !ok {
+		return category, nil, ErrCategoryNotFound
+	}
+	return found, breadcrumb, nil
+}
+
+// function to get filter of news or galleries on category and its sub categories
+// content is on category when it is its primary or secondary category
+func CategoryContentsFilter(ctx context.Context, idOrSlug string) (bson.M, error) {
+	category, err := GetCategory(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := loadCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryContentsFilter(categoryDescendantIds(categories, category.Id.Hex())), nil
+}
+
+// function to check categories of news or gallery before it is saved
+// secondary categories need primary category, repeated categories are removed
+func NormalizeContentCategories(ctx context.Context, primary string, secondary []string) (string, []string, error) {
+	primary = strings.TrimSpace(primary)
+	ids := []string{}
+	seen := map[string]bool{primary: true}
+	for _, id := range secondary {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+
+	if primary == "" {
+		if len(ids) > 0 {
+			return primary, ids, fmt.Errorf("%w: secondary categories need category_id", ErrContentCategory)
+		}
+		return primary, ids, nil
+	}
+
+	categories, err := loadCategories(ctx)
+	if err != nil {
+		return primary, ids, err
+	}
+	exists := map[string]bool{}
+	for _, category := range categories {
+		exists[category.Id.Hex()] = true
+	}
+
+	for _, id := range append([]string{primary}, ids...) {
+		if !exists[id] {
+			return primary, ids, fmt.Errorf("%w: %s is not found", ErrContentCategory, id)
+		}
+	}
+	return primary, ids, nil
+}
+
+// validateCategory checks name, unique slug and parent of category
+// parent can't be the category or its sub category, and the tree is max CategoryMaxDepth levels
+func validateCategory(ctx context.Context, category models.CategoryModel) error {
+	if category.Name == "" {
+		return ErrCategoryName
+	}
+	// slug looks like object id would be found by id instead
+	if _, err := primitive.ObjectIDFromHex(category.Slug); category.Slug == "" || err == nil {
+		return ErrCategorySlug
+	}
+
+	count, err := CategoriesCollections.CountDocuments(ctx, bson.M{"slug": category.Slug, "_id": bson.M{"$ne": category.Id}})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategorySlugTaken
+	}
+
+	if category.ParentID == "" {
+		return nil
+	}
+
+	categories, err := loadCategories(ctx)
+	if err != nil {
+		return err
+	}
+	parents := map[string]string{}
+	found := false
+	for _, existing := range categories {
+		parents[existing.Id.Hex()] = existing.ParentID
+		found = found || existing.Id.Hex() == category.ParentID
+	}
+	if !found {
+		return fmt.Errorf("%w: %s is not found", ErrCategoryParent, category.ParentID)
+	}
+
+	descendants := categoryDescendantIds(categories, category.Id.Hex())
+	for _, id := range descendants {
+		if id == category.ParentID {
+			return fmt.Errorf("%w: category can't be moved under itself", ErrCategoryParent)
+		}
+	}
+
+	// levels above the category, plus the category and its deepest sub category
+	depth := 1
+	for parentId := category.ParentID; parentId != ""; parentId = parents[parentId] {
+		depth++
+	}
+	if depth+categoryHeight(categories, category.Id.Hex())-1 > CategoryMaxDepth {
+		return fmt.Errorf("%w: max %d levels", ErrCategoryParent, CategoryMaxDepth)
+	}
+	return nil
+}
+
+// loadCategories gets every category sorted by order then name
+func loadCategories(ctx context.Context) ([]models.CategoryModel, error) {
+	categories := []models.CategoryModel{}
+
+	results, err := CategoriesCollections.Find(ctx, bson.M{})
+	if err != nil {
+		return categories, err
+	}
+	defer results.Close(ctx)
+
+	if err = results.All(ctx, &categories); err != nil {
+		return categories, err
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].Order != categories[j].Order {
+			return categories[i].Order < categories[j].Order
+		}
+		return categories[i].Name < categories[j].Name
+	})
+	return categories, nil
+}
+
+// countCategories counts news and galleries of every category including its sub categories
+func countCategories(ctx context.Context, categories []models.CategoryModel, lang string) error {
+	var counts map[string][2]int64
+	if cached, ok := categoryCountsCache.Get(lang); ok {
+		counts = cached.(map[string][2]int64)
+	} else {
+		counts = map[string][2]int64{}
+		for _, category := range categories {
+			filter := categoryContentsFilter(categoryDescendantIds(categories, category.Id.Hex()))
+			if lang != "" {
+				filter["lang"] = lang
+			}
+
+			newsCount, err := NewsCollections.CountDocuments(ctx, filter)
+			if err != nil {
+				return err
+			}
+			galleriesCount, err := GalleryCollections.CountDocuments(ctx, filter)
+			if err != nil {
+				return err
+			}
+			counts[category.Id.Hex()] = [2]int64{newsCount, galleriesCount}
+		}
+		categoryCountsCache.Set(lang, counts)
+	}
+
+	// category created after the counts are cached has no content yet
+	for key := range categories {
+		count := counts[categories[key].Id.Hex()]
+		categories[key].NewsCount = count[0]
+		categories[key].GalleriesCount = count[1]
+		categories[key].Count = count[0] + count[1]
+	}
+	return nil
+}
+
+// categoryContentsFilter finds news or galleries with primary or secondary category on the ids
+func categoryContentsFilter(ids []string) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"category_id": bson.M{"$in": ids}},
+		bson.M{"category_ids": bson.M{"$in": ids}},
+	}}
+}
+
+// categoryChildren builds tree of sub categories under the parent
+func categoryChildren(categories []models.CategoryModel, parentId string) []models.CategoryModel {
+	children := []models.CategoryModel{}
+	for _, category := range categories {
+		if category.ParentID == parentId {
+			category.Children = categoryChildren(categories, category.Id.Hex())
+			children = append(children, category)
+		}
+	}
+	return children
+}
+
+// categoryDescendantIds gets id of category and every sub category under it
+func categoryDescendantIds(categories []models.CategoryModel, categoryId string) []string {
+	ids := []string{categoryId}
+	for _, category := range categories {
+		if category.ParentID == categoryId {
+			ids = append(ids, categoryDescendantIds(categories, category.Id.Hex())...)
+		}
+	}
+	return ids
+}
+
+// categoryHeight gets number of levels from category to its deepest sub category, 1 when it has none
+func categoryHeight(categories []models.CategoryModel, categoryId string) int {
+	height := 1
+	for _, category := range categories {
+		if category.ParentID == categoryId {
+			if childHeight := categoryHeight(categories, category.Id.Hex()) + 1; childHeight > height {
+				height = childHeight
+			}
+		}
+	}
+	return height
+}
+
+// findCategoryPath finds category on the tree, its parents are added to path from the top category
+func findCategoryPath(tree []models.CategoryModel, categoryId string, path *[]models.CategoryModel) (models.CategoryModel, bool) {
+	for _, category := range tree {
+		if category.Id.Hex() == categoryId {
+			return category, true
+		}
+
+		parent := category
+		parent.Children = nil
+		*path = append(*path, parent)
+		if found, ok := findCategoryPath(category.Children, categoryId, path); ok {
+			return found, true
+		}
+		*path = (*path)[:len(*path)-1]
+	}
+	return models.CategoryModel{}, false
+}
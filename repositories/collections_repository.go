@@ -0,0 +1,302 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var CollectionsCollections *mongo.Collection = configs.GetCollection(configs.DB, "collections")
+
+// max galleries and news on one collection
+const CollectionMaxItems = 500
+
+var (
+	ErrCollectionNotFound  = errors.New("collection not found")
+	ErrCollectionSlugTaken = errors.New("slug is already used by other collection")
+	ErrCollectionSlug      = errors.New("slug is required")
+	ErrCollectionItem      = errors.New("invalid collection item")
+	ErrCollectionTooLarge  = fmt.Errorf("collection can have max %d items", CollectionMaxItems)
+)
+
+// struct of ListCollections() params
+type ListCollectionsParams struct {
+	Lang   string
+	Search string
+	Limit  int64
+	Page   int64
+}
+
+// function to create collection, slug is generated from title when it is empty
+func CreateCollection(ctx context.Context, payload models.PayloadCollection) (models.CollectionModel, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	collection := models.CollectionModel{
+		Id:          primitive.NewObjectID(),
+		Title:       payload.Title,
+		Slug:        collectionSlug(payload),
+		Description: payload.Description,
+		Cover:       payload.Cover,
+		Lang:        payload.Lang,
+		Items:       payload.Items,
+		CreatedOn:   now,
+		UpdatedOn:   now,
+	}
+	if collection.Items == nil {
+		collection.Items = []models.CollectionItemModel{}
+	}
+
+	if err := validateCollection(ctx, collection); err != nil {
+		return collection, err
+	}
+
+	_, err := CollectionsCollections.InsertOne(ctx, collection)
+	return collection, err
+}
+
+// function to update collection by its id or slug
+// slug is kept when it is empty so links are not broken, items are replaced when they are sent
+func UpdateCollection(ctx context.Context, idOrSlug string, payload models.PayloadCollection) (models.CollectionModel, error) {
+	collection, err := GetCollection(ctx, idOrSlug)
+	if err != nil {
+		return collection, err
+	}
+
+	collection.Title = payload.Title
+	if payload.Slug != "" {
+		collection.Slug = collectionSlug(payload)
+	}
+	collection.Description = payload.Description
+	collection.Cover = payload.Cover
+	collection.Lang = payload.Lang
+	if payload.Items != nil {
+		collection.Items = payload.Items
+	}
+	collection.UpdatedOn = time.Now().UnixNano() / int64(time.Millisecond)
+
+	if err = validateCollection(ctx, collection); err != nil {
+		return collection, err
+	}
+
+	_, err = CollectionsCollections.UpdateOne(ctx, bson.M{"_id": collection.Id}, bson.M{"$set": bson.M{
+		"title":       collection.Title,
+		"slug":        collection.Slug,
+		"description": collection.Description,
+		"cover":       collection.Cover,
+		"lang":        collection.Lang,
+		"items":       collection.Items,
+		"updated_on":  collection.UpdatedOn,
+	}})
+	return collection, err
+}
+
+// function to replace ordered items of collection
+func SetCollectionItems(ctx context.Context, idOrSlug string, items []models.CollectionItemModel) (models.CollectionModel, error) {
+	collection, err := GetCollection(ctx, idOrSlug)
+	if err != nil {
+		return collection, err
+	}
+
+	if items == nil {
+		items = []models.CollectionItemModel{}
+	}
+	collection.Items = items
+	collection.UpdatedOn = time.Now().UnixNano() / int64(time.Millisecond)
+
+	if err = validateCollectionItems(ctx, collection.Items); err != nil {
+		return collection, err
+	}
+
+	_, err = CollectionsCollections.UpdateOne(ctx, bson.M{"_id": collection.Id}, bson.M{"$set": bson.M{
+		"items":      collection.Items,
+		"updated_on": collection.UpdatedOn,
+	}})
+	return collection, err
+}
+
+// function to delete collection by its id or slug, its galleries and news are kept
+func DeleteCollection(ctx context.Context, idOrSlug string) error {
+	result, err := CollectionsCollections.DeleteOne(ctx, collectionFilter(idOrSlug))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount < 1 {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+// function to get collection by its id or slug
+func GetCollection(ctx context.Context, idOrSlug string) (models.CollectionModel, error) {
+	var collection models.CollectionModel
+
+	err := CollectionsCollections.FindOne(ctx, collectionFilter(idOrSlug)).Decode(&collection)
+	if err == mongo.ErrNoDocuments {
+		return collection, ErrCollectionNotFound
+	}
+	if collection.Items == nil {
+		collection.Items = []models.CollectionItemModel{}
+	}
+	return collection, err
+}
+
+// function to list collections, latest updated first
+func ListCollections(ctx context.Context, params ListCollectionsParams) ([]models.CollectionModel, int64, error) {
+	collections := []models.CollectionModel{}
+
+	filter := bson.M{}
+	if params.Lang != "" {
+		filter["lang"] = params.Lang
+	}
+	if params.Search != "" {
+		filter["title"] = bson.M{"$regex": params.Search, "$options": "i"}
+	}
+
+	opts := options.Find().SetSort(bson.D{{"updated_on", -1}, {"_id", -1}}).SetLimit(params.Limit).SetSkip((params.Page - 1) * params.Limit)
+
+	results, err := CollectionsCollections.Find(ctx, filter, opts)
+	if err != nil {
+		return collections, 0, err
+	}
+	defer results.Close(ctx)
+
+	if err = results.All(ctx, &collections); err != nil {
+		return collections, 0, err
+	}
+
+	count, err := CollectionsCollections.CountDocuments(ctx, filter)
+	return collections, count, err
+}
+
+// function to get galleries and news of collection on its order
+// content of news is removed like on timeline, deleted items are skipped
+func GetCollectionContents(ctx context.Context, collection models.CollectionModel) ([]models.TimelineItemModel, error) {
+	contents := []models.TimelineItemModel{}
+
+	found := map[string]models.TimelineItemModel{}
+	for _, itemType := range []string{models.TimelineGallery, models.TimelineNews} {
+		ids := collectionItemObjectIds(collection.Items, itemType)
+		if len(ids) < 1 {
+			continue
+		}
+
+		items, err := findFeedItems(ctx, itemType, bson.M{"_id": bson.M{"$in": ids}}, nil, 0)
+		if err != nil {
+			return contents, err
+		}
+		for _, item := range items {
+			found[itemType+":"+item.Id] = item
+		}
+	}
+
+	for _, item := range collection.Items {
+		if content, ok := found[item.Type+":"+item.Id]; ok {
+			contents = append(contents, content)
+		}
+	}
+	return contents, nil
+}
+
+// function to get ids of galleries on collection, used to filter galleries list
+func GetCollectionGalleryIds(ctx context.Context, idOrSlug string) ([]primitive.ObjectID, error) {
+	collection, err := GetCollection(ctx, idOrSlug)
+	if err != nil {
+		return nil, err
+	}
+	return collectionItemObjectIds(collection.Items, models.TimelineGallery), nil
+}
+
+// collectionFilter finds collection by id, or by slug when it is not an id
+func collectionFilter(idOrSlug string) bson.M {
+	if objId, err := primitive.ObjectIDFromHex(idOrSlug); err == nil {
+		return bson.M{"_id": objId}
+	}
+	return bson.M{"slug": utils.Slugify(idOrSlug)}
+}
+
+// collectionSlug normalizes slug of payload, generated from title when it is empty
+func collectionSlug(payload models.PayloadCollection) string {
+	if payload.Slug != "" {
+		return utils.Slugify(payload.Slug)
+	}
+	return utils.Slugify(payload.Title)
+}
+
+// validateCollection checks slug is not used by other collection and every item exists
+func validateCollection(ctx context.Context, collection models.CollectionModel) error {
+	// slug looks like object id would be found by id instead
+	if _, err := primitive.ObjectIDFromHex(collection.Slug); collection.Slug == "" || err == nil {
+		return ErrCollectionSlug
+	}
+
+	count, err := CollectionsCollections.CountDocuments(ctx, bson.M{"slug": collection.Slug, "_id": bson.M{"$ne": collection.Id}})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCollectionSlugTaken
+	}
+
+	return validateCollectionItems(ctx, collection.Items)
+}
+
+// validateCollectionItems checks type of items, item can only be added once and it must exist
+func validateCollectionItems(ctx context.Context, items []models.CollectionItemModel) error {
+	if len(items) > CollectionMaxItems {
+		return ErrCollectionTooLarge
+	}
+
+	exists := map[string]bool{}
+	for _, item := range items {
+		if item.Type != models.TimelineGallery && item.Type != models.TimelineNews {
+			return fmt.Errorf("%w: type of %s must be gallery or news", ErrCollectionItem, item.Id)
+		}
+		if _, err := primitive.ObjectIDFromHex(item.Id); err != nil {
+			return fmt.Errorf("%w: %s is not valid id", ErrCollectionItem, item.Id)
+		}
+		if exists[item.Type+":"+item.Id] {
+			return fmt.Errorf("%w: %s %s is added twice", ErrCollectionItem, item.Type, item.Id)
+		}
+		exists[item.Type+":"+item.Id] = true
+	}
+
+	for itemType, collection := range map[string]*mongo.Collection{models.TimelineGallery: GalleryCollections, models.TimelineNews: NewsCollections} {
+		ids := collectionItemObjectIds(items, itemType)
+		if len(ids) < 1 {
+			continue
+		}
+
+		count, err := collection.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return err
+		}
+		if count < int64(len(ids)) {
+			return fmt.Errorf("%w: some %s items are not found", ErrCollectionItem, itemType)
+		}
+	}
+
+	return nil
+}
+
+// collectionItemObjectIds gets ids of items with the type
+func collectionItemObjectIds(items []models.CollectionItemModel, itemType string) []primitive.ObjectID {
+	ids := []primitive.ObjectID{}
+	for _, item := range items {
+		if item.Type != itemType {
+			continue
+		}
+		if objId, err := primitive.ObjectIDFromHex(item.Id); err == nil {
+			ids = append(ids, objId)
+		}
+	}
+	return ids
+}
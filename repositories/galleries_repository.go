@@ -2,12 +2,15 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"follooow-be/configs"
 	"follooow-be/models"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 var GalleryCollections *mongo.Collection = configs.GetCollection(configs.DB, "galleries")
@@ -22,6 +25,8 @@ type CreateGalleryParams struct {
 	Slug        string
 	AuthorID    string
 	Tags        []string
+	CategoryID  string
+	CategoryIDs []string
 }
 
 // function to create new gallery
@@ -29,6 +34,7 @@ type CreateGalleryParams struct {
 func CreateGallery(ctx context.Context, params CreateGalleryParams) (*mongo.InsertOneResult, error) {
 	// get now times
 	now := time.Now().UnixNano() / int64(time.Millisecond)
+	EnsureImageIds(params.Images)
 	// ref: https://stackoverflow.com/a/8689281/2780875
 	newData := bson.D{
 		{"title", params.Title},
@@ -42,6 +48,8 @@ func CreateGallery(ctx context.Context, params CreateGalleryParams) (*mongo.Inse
 		{"influencers", params.Influencers},
 		{"author_id", params.AuthorID},
 		{"tags", params.Tags},
+		{"category_id", params.CategoryID},
+		{"category_ids", params.CategoryIDs},
 	}
 
 	// insert data to database
@@ -56,3 +64,200 @@ func CreateGallery(ctx context.Context, params CreateGalleryParams) (*mongo.Inse
 	}
 
 }
+
+// function to get gallery without changing it, ex: export on public GET
+// images created before images have id have empty id
+func GetGallery(ctx context.Context, galleryId string) (models.GalleryModel, error) {
+	var gallery models.GalleryModel
+	objId, _ := primitive.ObjectIDFromHex(galleryId)
+
+	err := GalleryCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&gallery)
+	return gallery, err
+}
+
+// function to get gallery for image operations
+// images created before images have id get new id here
+func GetGalleryWithImageIds(ctx context.Context, galleryId string) (models.GalleryModel, error) {
+	var gallery models.GalleryModel
+	objId, _ := primitive.ObjectIDFromHex(galleryId)
+
+	err := GalleryCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&gallery)
+	if err != nil {
+		return gallery, err
+	}
+
+	if EnsureImageIds(gallery.Images) {
+		_, err = GalleryCollections.UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$set": bson.M{"images": gallery.Images}})
+	}
+
+	return gallery, err
+}
+
+// ErrGalleryImageNotFound is returned when image is not on the gallery
+var ErrGalleryImageNotFound = errors.New("gallery image not found")
+
+// ErrGalleryImagesChanged is returned when images of gallery changed since they were read, ex: reorder during upload
+var ErrGalleryImagesChanged = errors.New("gallery images changed, reload the gallery and try again")
+
+// every image operation below updates single images on the database instead of writing back the images read before,
+// so concurrent edits of different images never overwrite each other
+
+// function to add images at the end of gallery
+// first added image becomes cover when gallery has no cover, returns true if it does
+func AddGalleryImages(ctx context.Context, galleryId primitive.ObjectID, images []models.ImageModel) (bool, error) {
+	if len(images) < 1 {
+		return false, nil
+	}
+
+	update := bson.M{
+		"$push": bson.M{"images": bson.M{"$each": images}},
+		"$set":  bson.M{"updated_on": time.Now().UnixNano() / int64(time.Millisecond)},
+	}
+	result, err := GalleryCollections.UpdateOne(ctx, bson.M{"_id": galleryId}, update)
+	if err != nil {
+		return false, err
+	}
+	if result.MatchedCount < 1 {
+		return false, mongo.ErrNoDocuments
+	}
+
+	// cover is checked by the update filter, so concurrent adds never set two covers
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: bson.A{bson.M{"image.id": images[0].Id}}})
+	result, err = GalleryCollections.UpdateOne(ctx, bson.M{"_id": galleryId, "images.iscover": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"images.$[image].iscover": true}}, opts)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+// function to remove single image of gallery, returns the removed image
+// first image becomes cover when the cover is removed
+func RemoveGalleryImage(ctx context.Context, galleryId primitive.ObjectID, imageId string) (models.ImageModel, error) {
+	var gallery models.GalleryModel
+	var removed models.ImageModel
+
+	update := bson.M{
+		"$pull": bson.M{"images": bson.M{"id": imageId}},
+		"$set":  bson.M{"updated_on": time.Now().UnixNano() / int64(time.Millisecond)},
+	}
+	// removed image is read from the gallery before the update
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.Before).SetProjection(bson.M{"images": bson.M{"$elemMatch": bson.M{"id": imageId}}})
+	err := GalleryCollections.FindOneAndUpdate(ctx, bson.M{"_id": galleryId, "images.id": imageId}, update, opts).Decode(&gallery)
+	if err == mongo.ErrNoDocuments {
+		return removed, galleryImageMissing(ctx, galleryId)
+	}
+	if err != nil {
+		return removed, err
+	}
+	if len(gallery.Images) < 1 {
+		return removed, ErrGalleryImageNotFound
+	}
+	removed = gallery.Images[0]
+
+	if removed.IsCover {
+		filter := bson.M{"_id": galleryId, "images.iscover": bson.M{"$ne": true}, "images.0": bson.M{"$exists": true}}
+		_, err = GalleryCollections.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"images.0.iscover": true}})
+	}
+	return removed, err
+}
+
+// function to make single image of gallery its cover, other images are no longer cover
+func SetGalleryCover(ctx context.Context, galleryId primitive.ObjectID, imageId string) error {
+	update := mongo.Pipeline{{{"$set", bson.M{
+		"images": bson.M{"$map": bson.M{
+			"input": "$images",
+			"as":    "image",
+			"in":    bson.M{"$mergeObjects": bson.A{"$$image", bson.M{"iscover": bson.M{"$eq": bson.A{"$$image.id", imageId}}}}},
+		}},
+		"updated_on": time.Now().UnixNano() / int64(time.Millisecond),
+	}}}}
+
+	result, err := GalleryCollections.UpdateOne(ctx, bson.M{"_id": galleryId, "images.id": imageId}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount < 1 {
+		return galleryImageMissing(ctx, galleryId)
+	}
+	return nil
+}
+
+// function to order images of gallery by ids, ids must be every image of the gallery without duplicate
+// images are moved on the database, so edits of images done meanwhile are kept
+func ReorderGalleryImages(ctx context.Context, galleryId primitive.ObjectID, ids []string) ([]models.ImageModel, error) {
+	var gallery models.GalleryModel
+
+	// gallery must still have exactly the ordered images
+	filter := bson.M{"_id": galleryId, "images": bson.M{"$size": len(ids)}, "images.id": bson.M{"$all": ids}}
+	update := mongo.Pipeline{{{"$set", bson.M{
+		"images": bson.M{"$map": bson.M{
+			"input": ids,
+			"as":    "id",
+			"in":    bson.M{"$arrayElemAt": bson.A{"$images", bson.M{"$indexOfArray": bson.A{"$images.id", "$$id"}}}},
+		}},
+		"updated_on": time.Now().UnixNano() / int64(time.Millisecond),
+	}}}}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"images": 1})
+	err := GalleryCollections.FindOneAndUpdate(ctx, filter, update, opts).Decode(&gallery)
+	if err == mongo.ErrNoDocuments {
+		if err = galleryImageMissing(ctx, galleryId); err == ErrGalleryImageNotFound {
+			err = ErrGalleryImagesChanged
+		}
+	}
+	return gallery.Images, err
+}
+
+// function to update fields of single image of gallery, fields are bson keys of the image, ex: caption
+// returns the updated image
+func UpdateGalleryImage(ctx context.Context, galleryId primitive.ObjectID, imageId string, fields bson.M) (models.ImageModel, error) {
+	var gallery models.GalleryModel
+	var image models.ImageModel
+
+	set := bson.M{"updated_on": time.Now().UnixNano() / int64(time.Millisecond)}
+	for key, value := range fields {
+		set["images.$[image]."+key] = value
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetArrayFilters(options.ArrayFilters{Filters: bson.A{bson.M{"image.id": imageId}}}).
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"images": bson.M{"$elemMatch": bson.M{"id": imageId}}})
+	err := GalleryCollections.FindOneAndUpdate(ctx, bson.M{"_id": galleryId, "images.id": imageId}, bson.M{"$set": set}, opts).Decode(&gallery)
+	if err == mongo.ErrNoDocuments {
+		return image, galleryImageMissing(ctx, galleryId)
+	}
+	if err != nil {
+		return image, err
+	}
+	if len(gallery.Images) < 1 {
+		return image, ErrGalleryImageNotFound
+	}
+	return gallery.Images[0], nil
+}
+
+// galleryImageMissing gets error of image operation which matched no gallery
+// mongo.ErrNoDocuments when the gallery doesn't exist, otherwise ErrGalleryImageNotFound
+func galleryImageMissing(ctx context.Context, galleryId primitive.ObjectID) error {
+	count, err := GalleryCollections.CountDocuments(ctx, bson.M{"_id": galleryId})
+	if err != nil {
+		return err
+	}
+	if count < 1 {
+		return mongo.ErrNoDocuments
+	}
+	return ErrGalleryImageNotFound
+}
+
+// EnsureImageIds gives id to every image without id
+// return true if any image changed
+func EnsureImageIds(images []models.ImageModel) bool {
+	changed := false
+	for key := range images {
+		if images[key].Id == "" {
+			images[key].Id = primitive.NewObjectID().Hex()
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var GalleryImportsCollections *mongo.Collection = configs.GetCollection(configs.DB, "gallery_imports")
+
+var ErrGalleryImportNotFound = errors.New("gallery import not found")
+
+// function to create queued gallery import, total is number of images found on the archive
+func CreateGalleryImport(ctx context.Context, filename string, title string, order string, total int) (models.GalleryImportModel, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	job := models.GalleryImportModel{
+		Id:        primitive.NewObjectID(),
+		Filename:  filename,
+		Title:     title,
+		Order:     order,
+		Status:    models.GalleryImportQueued,
+		Total:     total,
+		Failures:  []models.ImportFailureModel{},
+		CreatedOn: now,
+		UpdatedOn: now,
+	}
+
+	_, err := GalleryImportsCollections.InsertOne(ctx, job)
+	return job, err
+}
+
+// function to get gallery import by its id
+func GetGalleryImport(ctx context.Context, importId string) (models.GalleryImportModel, error) {
+	var job models.GalleryImportModel
+
+	objId, err := primitive.ObjectIDFromHex(importId)
+	if err != nil {
+		return job, ErrGalleryImportNotFound
+	}
+
+	err = GalleryImportsCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return job, ErrGalleryImportNotFound
+	}
+	if job.Failures == nil {
+		job.Failures = []models.ImportFailureModel{}
+	}
+	return job, err
+}
+
+// function to set status of gallery import
+func SetGalleryImportStatus(ctx context.Context, importId primitive.ObjectID, status string) error {
+	return updateGalleryImport(ctx, importId, bson.M{"$set": bson.M{"status": status}})
+}
+
+// function to count processed image of gallery import, failure is added when the image is not imported
+func AddGalleryImportProgress(ctx context.Context, importId primitive.ObjectID, failure *models.ImportFailureModel) error {
+	update := bson.M{"$inc": bson.M{"processed": 1}}
+	if failure != nil {
+		update["$push"] = bson.M{"failures": failure}
+	}
+	return updateGalleryImport(ctx, importId, update)
+}
+
+// function to complete gallery import with its created gallery
+func CompleteGalleryImport(ctx context.Context, importId primitive.ObjectID, galleryId string, duplicates []models.DuplicateImageModel) error {
+	return updateGalleryImport(ctx, importId, bson.M{"$set": bson.M{
+		"status":     models.GalleryImportCompleted,
+		"gallery_id": galleryId,
+		"duplicates": duplicates,
+	}})
+}
+
+// function to fail gallery import, duplicates are saved when the import is rejected because of them
+func FailGalleryImport(ctx context.Context, importId primitive.ObjectID, reason string, duplicates []models.DuplicateImageModel) error {
+	fields := bson.M{"status": models.GalleryImportFailed, "error": reason}
+	if len(duplicates) > 0 {
+		fields["duplicates"] = duplicates
+	}
+	return updateGalleryImport(ctx, importId, bson.M{"$set": fields})
+}
+
+// function to fail imports which were not finished when the server stopped, their archive is already gone
+// returns number of failed imports
+func FailInterruptedGalleryImports(ctx context.Context) (int64, error) {
+	filter := bson.M{"status": bson.M{"$in": bson.A{models.GalleryImportQueued, models.GalleryImportRunning}}}
+	update := bson.M{"$set": bson.M{
+		"status":     models.GalleryImportFailed,
+		"error":      "import was interrupted by server restart",
+		"updated_on": time.Now().UnixNano() / int64(time.Millisecond),
+	}}
+
+	result, err := GalleryImportsCollections.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+func updateGalleryImport(ctx context.Context, importId primitive.ObjectID, update bson.M) error {
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["updated_on"] = time.Now().UnixNano() / int64(time.Millisecond)
+
+	_, err := GalleryImportsCollections.UpdateOne(ctx, bson.M{"_id": importId}, update)
+	return err
+}
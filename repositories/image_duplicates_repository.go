@@ -0,0 +1,151 @@
+package repositories
+
+import (
+	"context"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// function to find stored gallery images near duplicate of hash
+// images of excluded gallery are skipped, ex: gallery which images are being replaced
+func FindDuplicateImages(ctx context.Context, hash string, excludeGalleryId primitive.ObjectID) ([]models.DuplicateImageModel, error) {
+	duplicates := []models.DuplicateImageModel{}
+
+	bands := utils.ImageHashBands(hash)
+	if len(bands) < 1 {
+		return duplicates, nil
+	}
+
+	filter := bson.M{"images.hash_bands": bson.M{"$in": bands}}
+	if !excludeGalleryId.IsZero() {
+		filter["_id"] = bson.M{"$ne": excludeGalleryId}
+	}
+
+	galleries, err := findImageReportGalleries(ctx, filter)
+	if err != nil {
+		return duplicates, err
+	}
+
+	maxDistance := utils.DuplicateImageDistance()
+	for _, gallery := range galleries {
+		for _, image := range gallery.Images {
+			distance := utils.ImageHashDistance(hash, image.Hash)
+			if distance < 0 || distance > maxDistance {
+				continue
+			}
+
+			duplicate := duplicateImage(gallery, image)
+			duplicate.Distance = distance
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+
+	return duplicates, nil
+}
+
+// function to group every near duplicate gallery images, largest cluster first
+func GetDuplicateClusters(ctx context.Context) ([]models.DuplicateClusterModel, error) {
+	clusters := []models.DuplicateClusterModel{}
+
+	galleries, err := findImageReportGalleries(ctx, bson.M{"images.hash": bson.M{"$exists": true}})
+	if err != nil {
+		return clusters, err
+	}
+
+	var images []models.DuplicateImageModel
+	for _, gallery := range galleries {
+		for _, image := range gallery.Images {
+			if image.Hash != "" {
+				images = append(images, duplicateImage(gallery, image))
+			}
+		}
+	}
+
+	// union find of images, only images sharing a band are compared
+	parents := make([]int, len(images))
+	for i := range parents {
+		parents[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parents[i] != i {
+			parents[i] = find(parents[i])
+		}
+		return parents[i]
+	}
+
+	maxDistance := utils.DuplicateImageDistance()
+	buckets := map[string][]int{}
+	for i, image := range images {
+		for _, band := range utils.ImageHashBands(image.Hash) {
+			for _, j := range buckets[band] {
+				if find(i) == find(j) {
+					continue
+				}
+				if distance := utils.ImageHashDistance(image.Hash, images[j].Hash); distance >= 0 && distance <= maxDistance {
+					parents[find(i)] = find(j)
+				}
+			}
+			buckets[band] = append(buckets[band], i)
+		}
+	}
+
+	groups := map[int][]models.DuplicateImageModel{}
+	for i, image := range images {
+		root := find(i)
+		groups[root] = append(groups[root], image)
+	}
+
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		galleryIds := map[string]bool{}
+		for key := range group {
+			group[key].Distance = utils.ImageHashDistance(group[0].Hash, group[key].Hash)
+			galleryIds[group[key].GalleryID] = true
+		}
+		clusters = append(clusters, models.DuplicateClusterModel{Images: group, Galleries: len(galleryIds)})
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Images) != len(clusters[j].Images) {
+			return len(clusters[i].Images) > len(clusters[j].Images)
+		}
+		return clusters[i].Images[0].Hash < clusters[j].Images[0].Hash
+	})
+
+	return clusters, nil
+}
+
+// findImageReportGalleries gets galleries with only fields used by image reports
+func findImageReportGalleries(ctx context.Context, filter bson.M) ([]models.GalleryModel, error) {
+	var galleries []models.GalleryModel
+
+	opts := options.Find().SetProjection(bson.M{"title": 1, "slug": 1, "lang": 1, "images": 1})
+	results, err := GalleryCollections.Find(ctx, filter, opts)
+	if err != nil {
+		return galleries, err
+	}
+	defer results.Close(ctx)
+
+	err = results.All(ctx, &galleries)
+	return galleries, err
+}
+
+func duplicateImage(gallery models.GalleryModel, image models.ImageModel) models.DuplicateImageModel {
+	return models.DuplicateImageModel{
+		GalleryID:    gallery.Id.Hex(),
+		GalleryTitle: gallery.Title,
+		GalleryURL:   "https://follooow.com/" + gallery.Lang + "/gallery/" + gallery.Slug + "-" + gallery.Id.Hex(),
+		ImageID:      image.Id,
+		Url:          image.Url,
+		Hash:         image.Hash,
+	}
+}
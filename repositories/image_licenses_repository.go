@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"follooow-be/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// function to find gallery images which license expires before the time, already expired images included
+// soonest expiry first
+func FindExpiringImageLicenses(ctx context.Context, before time.Time) ([]models.ExpiringImageLicenseModel, error) {
+	images := []models.ExpiringImageLicenseModel{}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	limit := before.UnixNano() / int64(time.Millisecond)
+
+	filter := bson.M{"images": bson.M{"$elemMatch": bson.M{"license_expires_on": bson.M{"$gt": 0, "$lte": limit}}}}
+	galleries, err := findImageReportGalleries(ctx, filter)
+	if err != nil {
+		return images, err
+	}
+
+	for _, gallery := range galleries {
+		for _, image := range gallery.Images {
+			if image.LicenseExpiresOn <= 0 || image.LicenseExpiresOn > limit {
+				continue
+			}
+
+			images = append(images, models.ExpiringImageLicenseModel{
+				GalleryID:        gallery.Id.Hex(),
+				GalleryTitle:     gallery.Title,
+				GalleryURL:       "https://follooow.com/" + gallery.Lang + "/gallery/" + gallery.Slug + "-" + gallery.Id.Hex(),
+				ImageID:          image.Id,
+				Url:              image.Url,
+				Caption:          image.Caption,
+				Credit:           image.Credit,
+				SourceUrl:        image.SourceUrl,
+				License:          image.License,
+				LicenseExpiresOn: image.LicenseExpiresOn,
+				LicenseExpired:   image.LicenseExpiresOn <= now,
+			})
+		}
+	}
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return images[i].LicenseExpiresOn < images[j].LicenseExpiresOn
+	})
+
+	return images, nil
+}
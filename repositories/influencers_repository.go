@@ -4,6 +4,7 @@ import (
 	"context"
 	"follooow-be/configs"
 	"follooow-be/models"
+	"follooow-be/utils"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -37,8 +38,18 @@ func GetDetailInfluencers(ctx context.Context, influencer_id string, lang string
 		influencer.Stats.TotalNews = int(countNews)
 		influencer.Stats.TotalGallery = int(countGallery)
 
+		// get current members if influencer is a group
+		members, err := GetGroupMembers(ctx, influencer_id)
+		if err != nil {
+			return err, influencer
+		}
+		influencer.Members = members
+
 		// increase visits
 		InfluencersCollections.UpdateOne(ctx, bson.D{{"_id", objId}}, bson.D{{"$set", bson.D{{"visits", influencer.Visits + 1}}}})
+
+		// count view for trending of the language
+		RecordView(ctx, models.TrendingInfluencer, influencer_id, lang)
 	}
 
 	return err, influencer
@@ -63,3 +74,37 @@ func InfluencersUpdateOnToNow(ctx context.Context, influencersIds []string) erro
 
 	return err
 }
+
+// function to get small data of influencers, keyed by influencer id
+func GetInfluencersSmallData(ctx context.Context, influencersIds []string) (map[string]models.InfluencerSmallDataModel, error) {
+	influencers := map[string]models.InfluencerSmallDataModel{}
+
+	var objectIds []primitive.ObjectID
+	for key := range influencersIds {
+		objId, err := primitive.ObjectIDFromHex(influencersIds[key])
+		if err == nil {
+			objectIds = append(objectIds, objId)
+		}
+	}
+
+	if len(objectIds) < 1 {
+		return influencers, nil
+	}
+
+	results, err := InfluencersCollections.Find(ctx, bson.D{{"_id", bson.M{"$in": objectIds}}})
+	if err != nil {
+		return influencers, err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		var singleInfluencer models.InfluencerSmallDataModel
+		if err = results.Decode(&singleInfluencer); err != nil {
+			return influencers, err
+		}
+		singleInfluencer.AvatarRenditions = utils.ImageRenditions(singleInfluencer.Avatar, 0, 0)
+		influencers[singleInfluencer.Id.Hex()] = singleInfluencer
+	}
+
+	return influencers, nil
+}
@@ -0,0 +1,301 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/storage"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var MediaCollections *mongo.Collection = configs.GetCollection(configs.DB, "media")
+
+var (
+	ErrMediaNotFound = errors.New("media not found")
+	ErrMediaInUse    = errors.New("media is still used")
+)
+
+// RecordMediaParams is detail of upload which is not returned by the storage
+type RecordMediaParams struct {
+	Directory  string
+	Filename   string
+	UploaderID string
+	Tags       []string
+}
+
+// ListMediaParams is filter of media library, empty field is not filtered
+type ListMediaParams struct {
+	Search     string
+	Type       string
+	Directory  string
+	Tag        string
+	UploaderID string
+	Limit      int64
+	Page       int64
+}
+
+// function to record uploaded file on media library
+// file uploaded again with the same public id, ex: replaced avatar, updates its media
+func RecordMedia(ctx context.Context, asset *storage.Asset, params RecordMediaParams) (models.MediaModel, error) {
+	var media models.MediaModel
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	mediaType := models.MediaImage
+	if asset.ResourceType == storage.ResourceVideo {
+		mediaType = models.MediaVideo
+	}
+
+	fields := bson.M{
+		"type":           mediaType,
+		"url":            asset.URL,
+		"directory":      params.Directory,
+		"width":          asset.Width,
+		"height":         asset.Height,
+		"bytes":          asset.Bytes,
+		"format":         asset.Format,
+		"dominant_color": asset.DominantColor,
+		"duration":       asset.Duration,
+		"poster":         asset.PosterURL,
+		"hash":           asset.Hash,
+		"updated_on":     now,
+	}
+	if params.Filename != "" {
+		fields["filename"] = params.Filename
+	}
+	if params.UploaderID != "" {
+		fields["uploader_id"] = params.UploaderID
+	}
+
+	update := bson.M{
+		"$set":         fields,
+		"$setOnInsert": bson.M{"created_on": now},
+	}
+	if tags := NormalizeMediaTags(params.Tags); len(tags) > 0 {
+		update["$addToSet"] = bson.M{"tags": bson.M{"$each": tags}}
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := MediaCollections.FindOneAndUpdate(ctx, bson.M{"public_id": asset.PublicID}, update, opts).Decode(&media)
+	if media.Tags == nil {
+		media.Tags = []string{}
+	}
+	return media, err
+}
+
+// function to list media of library, newest first
+func ListMedia(ctx context.Context, params ListMediaParams) ([]models.MediaModel, int64, error) {
+	medias := []models.MediaModel{}
+
+	filter := bson.M{}
+	if params.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(params.Search), Options: "i"}
+		filter["$or"] = bson.A{
+			bson.M{"filename": pattern},
+			bson.M{"public_id": pattern},
+			bson.M{"tags": pattern},
+		}
+	}
+	if params.Type != "" {
+		filter["type"] = params.Type
+	}
+	if params.Directory != "" {
+		filter["directory"] = params.Directory
+	}
+	if params.Tag != "" {
+		filter["tags"] = strings.ToLower(strings.TrimSpace(params.Tag))
+	}
+	if params.UploaderID != "" {
+		filter["uploader_id"] = params.UploaderID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_on", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(params.Limit).SetSkip((params.Page - 1) * params.Limit)
+
+	results, err := MediaCollections.Find(ctx, filter, opts)
+	if err != nil {
+		return medias, 0, err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		var media models.MediaModel
+		if err = results.Decode(&media); err != nil {
+			return medias, 0, err
+		}
+		if media.Tags == nil {
+			media.Tags = []string{}
+		}
+		medias = append(medias, media)
+	}
+
+	total, err := MediaCollections.CountDocuments(ctx, filter)
+	return medias, total, err
+}
+
+// function to get media of library by its id
+func GetMedia(ctx context.Context, mediaId string) (models.MediaModel, error) {
+	var media models.MediaModel
+
+	objId, err := primitive.ObjectIDFromHex(mediaId)
+	if err != nil {
+		return media, ErrMediaNotFound
+	}
+
+	err = MediaCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&media)
+	if err == mongo.ErrNoDocuments {
+		return media, ErrMediaNotFound
+	}
+	if media.Tags == nil {
+		media.Tags = []string{}
+	}
+	return media, err
+}
+
+// function to get medias of library by their ids, missing id is skipped
+// medias are on the same order as the ids
+func GetMediaByIds(ctx context.Context, mediaIds []string) ([]models.MediaModel, error) {
+	var objIds []primitive.ObjectID
+	for _, mediaId := range mediaIds {
+		objId, err := primitive.ObjectIDFromHex(mediaId)
+		if err != nil {
+			return nil, ErrMediaNotFound
+		}
+		objIds = append(objIds, objId)
+	}
+
+	results, err := MediaCollections.Find(ctx, bson.M{"_id": bson.M{"$in": objIds}})
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+
+	found := map[primitive.ObjectID]models.MediaModel{}
+	for results.Next(ctx) {
+		var media models.MediaModel
+		if err = results.Decode(&media); err != nil {
+			return nil, err
+		}
+		found[media.Id] = media
+	}
+
+	var medias []models.MediaModel
+	for _, objId := range objIds {
+		if media, ok := found[objId]; ok {
+			medias = append(medias, media)
+		}
+	}
+	return medias, nil
+}
+
+// function to replace tags of media
+func UpdateMediaTags(ctx context.Context, mediaId primitive.ObjectID, tags []string) (models.MediaModel, error) {
+	var media models.MediaModel
+
+	update := bson.M{"$set": bson.M{"tags": NormalizeMediaTags(tags), "updated_on": time.Now().UnixNano() / int64(time.Millisecond)}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err := MediaCollections.FindOneAndUpdate(ctx, bson.M{"_id": mediaId}, update, opts).Decode(&media)
+	if err == mongo.ErrNoDocuments {
+		return media, ErrMediaNotFound
+	}
+	return media, err
+}
+
+// function to delete media from library, the stored file is deleted by caller
+func DeleteMedia(ctx context.Context, mediaId primitive.ObjectID) error {
+	result, err := MediaCollections.DeleteOne(ctx, bson.M{"_id": mediaId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount < 1 {
+		return ErrMediaNotFound
+	}
+	return nil
+}
+
+// function to delete media of stored file from library, missing media is not an error
+func DeleteMediaByPublicID(ctx context.Context, publicID string) error {
+	_, err := MediaCollections.DeleteOne(ctx, bson.M{"public_id": publicID})
+	return err
+}
+
+// function to find galleries, news, influencers and collections using media by its url or public id
+func FindMediaUsages(ctx context.Context, media models.MediaModel) ([]models.MediaUsageModel, error) {
+	usages := []models.MediaUsageModel{}
+
+	galleryFilter := bson.M{"$or": bson.A{bson.M{"images.url": media.Url}, bson.M{"images.public_id": media.PublicID}}}
+	galleryUsages, err := findMediaUsagesOf(ctx, GalleryCollections, galleryFilter, models.MediaUsageGallery, "title")
+	if err != nil {
+		return usages, err
+	}
+	usages = append(usages, galleryUsages...)
+
+	newsUsages, err := findMediaUsagesOf(ctx, NewsCollections, bson.M{"thumbnail": media.Url}, models.MediaUsageNews, "title")
+	if err != nil {
+		return usages, err
+	}
+	usages = append(usages, newsUsages...)
+
+	// avatar or image of best moment
+	influencerFilter := bson.M{"$or": bson.A{bson.M{"avatar": media.Url}, bson.M{"best_moments.image": media.Url}}}
+	influencerUsages, err := findMediaUsagesOf(ctx, InfluencersCollections, influencerFilter, models.MediaUsageInfluencer, "name")
+	if err != nil {
+		return usages, err
+	}
+	usages = append(usages, influencerUsages...)
+
+	collectionUsages, err := findMediaUsagesOf(ctx, CollectionsCollections, bson.M{"cover": media.Url}, models.MediaUsageCollection, "title")
+	if err != nil {
+		return usages, err
+	}
+	return append(usages, collectionUsages...), nil
+}
+
+// NormalizeMediaTags lowercases and trims tags, empty and repeated tags are removed
+func NormalizeMediaTags(tags []string) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+func findMediaUsagesOf(ctx context.Context, collection *mongo.Collection, filter bson.M, usageType string, titleField string) ([]models.MediaUsageModel, error) {
+	var usages []models.MediaUsageModel
+
+	opts := options.Find().SetProjection(bson.M{titleField: 1})
+	results, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		var doc bson.M
+		if err = results.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		usage := models.MediaUsageModel{Type: usageType}
+		if id, ok := doc["_id"].(primitive.ObjectID); ok {
+			usage.Id = id.Hex()
+		}
+		usage.Title, _ = doc[titleField].(string)
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
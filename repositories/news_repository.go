@@ -53,6 +53,14 @@ func GetDetailNews(ctx context.Context, params DetailNewsParams) (error, models.
 		return updateErr, news
 	}
 
+	// count view for trending on language of the news, failure doesn't block reader
+	// news created before lang existed are counted on requested language
+	lang := news.Lang
+	if lang == "" {
+		lang = params.Lang
+	}
+	RecordView(ctx, models.TrendingNews, params.NewsId, lang)
+
 	// get influencer data if news has influencers
 	if len(news.Influencers) > 0 {
 		var idsArr []string = news.Influencers
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"context"
+	"follooow-be/models"
+	"follooow-be/storage"
+	"follooow-be/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OrphanSweepParams is scope of orphaned files sweep
+type OrphanSweepParams struct {
+	Folders     []string
+	GracePeriod time.Duration
+	DryRun      bool
+}
+
+// function to find stored files not used by news, galleries or influencers and not in media library
+// orphans older than grace period are deleted from the storage, unless dry run
+func SweepOrphanMedia(ctx context.Context, params OrphanSweepParams) (models.OrphanSweepModel, error) {
+	sweep := models.OrphanSweepModel{
+		DryRun:      params.DryRun,
+		GracePeriod: params.GracePeriod.String(),
+		Folders:     params.Folders,
+		Orphans:     []models.OrphanMediaModel{},
+	}
+
+	references, err := loadMediaReferences(ctx)
+	if err != nil {
+		return sweep, err
+	}
+
+	// folders may overlap, ex: root folder and its sub folder
+	seen := map[string]bool{}
+	var orphans []storage.StoredFile
+	for _, folder := range params.Folders {
+		err = storage.Default().List(ctx, folder, func(file storage.StoredFile) error {
+			if seen[file.PublicID] {
+				return nil
+			}
+			seen[file.PublicID] = true
+			sweep.Scanned++
+
+			if !references.has(file.PublicID) {
+				orphans = append(orphans, file)
+			}
+			return nil
+		})
+		if err != nil {
+			return sweep, err
+		}
+	}
+
+	// files listed while content was edited, ex: media picked from library during the sweep
+	// only orphans of both references are deleted
+	if !params.DryRun && len(orphans) > 0 {
+		if references, err = loadMediaReferences(ctx); err != nil {
+			return sweep, err
+		}
+	}
+
+	expired := time.Now().Add(-params.GracePeriod)
+	for _, file := range orphans {
+		orphan := models.OrphanMediaModel{
+			PublicID:     file.PublicID,
+			ResourceType: file.ResourceType,
+			Bytes:        file.Bytes,
+			CreatedOn:    file.CreatedAt.UnixNano() / int64(time.Millisecond),
+		}
+
+		if !params.DryRun && file.CreatedAt.Before(expired) && !references.has(file.PublicID) {
+			if err = utils.DeleteMedia(ctx, file.PublicID, file.ResourceType); err != nil {
+				orphan.Error = err.Error()
+				sweep.Failed++
+			} else {
+				orphan.Deleted = true
+				sweep.Deleted++
+			}
+		}
+
+		sweep.OrphanBytes += int64(file.Bytes)
+		sweep.Orphans = append(sweep.Orphans, orphan)
+	}
+
+	return sweep, nil
+}
+
+// mediaReferences is every stored file used by content
+// public id found inside urls or news content is referenced too, so a file is never deleted by unusual url, ex: resized url
+type mediaReferences struct {
+	publicIDs map[string]bool
+	text      strings.Builder
+}
+
+func (r *mediaReferences) add(url string) {
+	if url == "" {
+		return
+	}
+	if publicID := storage.Default().PublicIDFromURL(url); publicID != "" {
+		r.publicIDs[publicID] = true
+	}
+	r.text.WriteString(url)
+	r.text.WriteString("\n")
+}
+
+func (r *mediaReferences) has(publicID string) bool {
+	return r.publicIDs[publicID] || strings.Contains(r.text.String(), publicID)
+}
+
+// loadMediaReferences reads urls of gallery images, news thumbnail and content, influencer avatar and best moments, collection cover
+// files of media library are referenced too, they are kept to be picked later and deleted with DELETE /api/media/:media_id
+func loadMediaReferences(ctx context.Context) (*mediaReferences, error) {
+	references := &mediaReferences{publicIDs: map[string]bool{}}
+
+	err := eachMediaReference(ctx, GalleryCollections, bson.M{"images.url": 1, "images.public_id": 1, "images.poster": 1}, func(results *mongo.Cursor) error {
+		var gallery models.GalleryModel
+		if err := results.Decode(&gallery); err != nil {
+			return err
+		}
+		for _, image := range gallery.Images {
+			if image.PublicID != "" {
+				references.publicIDs[image.PublicID] = true
+			}
+			references.add(image.Url)
+			references.add(image.Poster)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = eachMediaReference(ctx, NewsCollections, bson.M{"thumbnail": 1, "content": 1}, func(results *mongo.Cursor) error {
+		var news models.NewsModel
+		if err := results.Decode(&news); err != nil {
+			return err
+		}
+		references.add(news.Thumbnail)
+		// uploaded images embedded on content
+		references.text.WriteString(news.Content)
+		references.text.WriteString("\n")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = eachMediaReference(ctx, InfluencersCollections, bson.M{"avatar": 1, "best_moments.image": 1}, func(results *mongo.Cursor) error {
+		var influencer models.InfluencerModel
+		if err := results.Decode(&influencer); err != nil {
+			return err
+		}
+		references.add(influencer.Avatar)
+		for _, moment := range influencer.BestMoments {
+			references.add(moment.Image)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = eachMediaReference(ctx, CollectionsCollections, bson.M{"cover": 1}, func(results *mongo.Cursor) error {
+		var collection models.CollectionModel
+		if err := results.Decode(&collection); err != nil {
+			return err
+		}
+		references.add(collection.Cover)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = eachMediaReference(ctx, MediaCollections, bson.M{"public_id": 1, "url": 1, "poster": 1}, func(results *mongo.Cursor) error {
+		var media models.MediaModel
+		if err := results.Decode(&media); err != nil {
+			return err
+		}
+		references.publicIDs[media.PublicID] = true
+		references.add(media.Url)
+		references.add(media.Poster)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return references, nil
+}
+
+func eachMediaReference(ctx context.Context, collection *mongo.Collection, projection bson.M, fn func(results *mongo.Cursor) error) error {
+	results, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		if err = fn(results); err != nil {
+			return err
+		}
+	}
+	return results.Err()
+}
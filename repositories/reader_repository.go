@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ReadersCollections *mongo.Collection = configs.GetCollection(configs.DB, "readers")
+var FollowsCollections *mongo.Collection = configs.GetCollection(configs.DB, "follows")
+
+// struct of GetReaderFeed() params
+type ReaderFeedParams struct {
+	InfluencerIDs []string
+	Lang          string
+	Cursor        *utils.FeedCursor
+	Limit         int64
+}
+
+func CreateReader(reader models.CreateReaderModel) (*models.ReaderModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Check if username already exists
+	var existingReader models.ReaderModel
+	err := ReadersCollections.FindOne(ctx, bson.M{"username": reader.Username}).Decode(&existingReader)
+	if err == nil {
+		return nil, nil // Reader already exists
+	}
+
+	// Create new reader
+	newReader := models.ReaderModel{
+		ID:          primitive.NewObjectID(),
+		Username:    reader.Username,
+		DisplayName: reader.DisplayName,
+		Password:    reader.Password, // Password should be hashed before calling this
+		CreatedAt:   time.Now().Unix(),
+		UpdatedAt:   time.Now().Unix(),
+	}
+
+	_, err = ReadersCollections.InsertOne(ctx, newReader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newReader, nil
+}
+
+func FindReaderByUsername(username string) (*models.ReaderModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reader models.ReaderModel
+	err := ReadersCollections.FindOne(ctx, bson.M{"username": username}).Decode(&reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader, nil
+}
+
+func FindReaderByID(id primitive.ObjectID) (*models.ReaderModel, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var reader models.ReaderModel
+	err := ReadersCollections.FindOne(ctx, bson.M{"_id": id}).Decode(&reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reader, nil
+}
+
+// function to follow influencer
+// followers of influencer only increased on new follow
+func FollowInfluencer(ctx context.Context, readerId string, influencerId string) (bool, error) {
+	filter := bson.M{"reader_id": readerId, "influencer_id": influencerId}
+	update := bson.M{"$setOnInsert": bson.M{"created_on": time.Now().UnixNano() / int64(time.Millisecond)}}
+
+	result, err := FollowsCollections.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil || result.UpsertedCount < 1 {
+		return false, err
+	}
+
+	objId, _ := primitive.ObjectIDFromHex(influencerId)
+	_, err = InfluencersCollections.UpdateOne(ctx, bson.M{"_id": objId}, bson.M{"$inc": bson.M{"followers": 1}})
+
+	return true, err
+}
+
+// function to unfollow influencer
+// followers of influencer only decreased when follow exists
+func UnfollowInfluencer(ctx context.Context, readerId string, influencerId string) (bool, error) {
+	result, err := FollowsCollections.DeleteOne(ctx, bson.M{"reader_id": readerId, "influencer_id": influencerId})
+	if err != nil || result.DeletedCount < 1 {
+		return false, err
+	}
+
+	objId, _ := primitive.ObjectIDFromHex(influencerId)
+	_, err = InfluencersCollections.UpdateOne(ctx, bson.M{"_id": objId, "followers": bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{"followers": -1}})
+
+	return true, err
+}
+
+// function to get ids of influencers followed by reader, latest follow first
+func GetFollowedInfluencerIds(ctx context.Context, readerId string) ([]string, error) {
+	var influencerIds []string
+
+	opts := options.Find().SetSort(bson.D{{"created_on", -1}})
+	results, err := FollowsCollections.Find(ctx, bson.M{"reader_id": readerId}, opts)
+	if err != nil {
+		return influencerIds, err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		var follow models.FollowModel
+		if err = results.Decode(&follow); err != nil {
+			return influencerIds, err
+		}
+		influencerIds = append(influencerIds, follow.InfluencerID)
+	}
+
+	return influencerIds, nil
+}
+
+// function to get news and galleries of followed influencers as one feed
+// sorted by created_on descending, next cursor is empty on last page
+func GetReaderFeed(ctx context.Context, params ReaderFeedParams) ([]models.TimelineItemModel, string, error) {
+	if len(params.InfluencerIDs) < 1 {
+		return []models.TimelineItemModel{}, "", nil
+	}
+
+	filter := bson.M{"influencers": bson.M{"$in": params.InfluencerIDs}}
+	if params.Lang != "" {
+		filter["lang"] = params.Lang
+	}
+
+	news, err := findFeedItems(ctx, models.TimelineNews, filter, params.Cursor, params.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	galleries, err := findFeedItems(ctx, models.TimelineGallery, filter, params.Cursor, params.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, nextCursor := pageFeedItems(append(news, galleries...), params.Limit)
+	return page, nextCursor, nil
+}
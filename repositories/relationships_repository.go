@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var RelationshipsCollections *mongo.Collection = configs.GetCollection(configs.DB, "relationships")
+
+// ValidRelationshipType checks is relationship type supported
+func ValidRelationshipType(relationshipType string) bool {
+	switch relationshipType {
+	case models.RelationshipMemberOf, models.RelationshipCollaboratedWith, models.RelationshipSibling, models.RelationshipManagedBy:
+		return true
+	}
+	return false
+}
+
+// function to create relationship from influencer to related influencer
+func CreateRelationship(ctx context.Context, influencerId string, payload models.PayloadRelationship) (*mongo.InsertOneResult, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	newData := models.RelationshipModel{
+		InfluencerID: influencerId,
+		RelatedID:    payload.RelatedID,
+		Type:         payload.Type,
+		StartOn:      payload.StartOn,
+		EndOn:        payload.EndOn,
+		Note:         payload.Note,
+		CreatedOn:    now,
+		UpdatedOn:    now,
+	}
+
+	return RelationshipsCollections.InsertOne(ctx, newData)
+}
+
+// function to update relationship owned by influencer
+func UpdateRelationship(ctx context.Context, influencerId string, relationshipId string, payload models.PayloadRelationship) (*mongo.UpdateResult, error) {
+	objId, _ := primitive.ObjectIDFromHex(relationshipId)
+
+	filter := bson.M{"_id": objId, "influencer_id": influencerId}
+	update := bson.M{
+		"$set": bson.M{
+			"related_id": payload.RelatedID,
+			"type":       payload.Type,
+			"start_on":   payload.StartOn,
+			"end_on":     payload.EndOn,
+			"note":       payload.Note,
+			"updated_on": time.Now().UnixNano() / int64(time.Millisecond),
+		},
+	}
+
+	return RelationshipsCollections.UpdateOne(ctx, filter, update)
+}
+
+// function to delete relationship owned by influencer
+func DeleteRelationship(ctx context.Context, influencerId string, relationshipId string) (*mongo.DeleteResult, error) {
+	objId, _ := primitive.ObjectIDFromHex(relationshipId)
+
+	return RelationshipsCollections.DeleteOne(ctx, bson.M{"_id": objId, "influencer_id": influencerId})
+}
+
+// function to list all relationships of influencer, both directions
+// ex: relationships of BLACKPINK contains "Lisa member_of BLACKPINK"
+func ListRelationships(ctx context.Context, influencerId string) ([]models.RelationshipModel, error) {
+	var relationships []models.RelationshipModel
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"influencer_id": influencerId},
+		bson.M{"related_id": influencerId},
+	}}
+	opts := options.Find().SetSort(bson.D{{"type", 1}, {"start_on", 1}})
+
+	results, err := RelationshipsCollections.Find(ctx, filter, opts)
+	if err != nil {
+		return relationships, err
+	}
+	defer results.Close(ctx)
+
+	if err = results.All(ctx, &relationships); err != nil {
+		return relationships, err
+	}
+
+	// attach influencers data of both sides
+	var influencerIds []string
+	for key := range relationships {
+		influencerIds = append(influencerIds, relationships[key].InfluencerID, relationships[key].RelatedID)
+	}
+
+	influencers, err := GetInfluencersSmallData(ctx, influencerIds)
+	if err != nil {
+		return relationships, err
+	}
+
+	for key := range relationships {
+		if influencer, ok := influencers[relationships[key].InfluencerID]; ok {
+			relationships[key].Influencer = &influencer
+		}
+		if related, ok := influencers[relationships[key].RelatedID]; ok {
+			relationships[key].Related = &related
+		}
+	}
+
+	return relationships, nil
+}
+
+// function to get current members of group
+// member is influencer with active member_of relationship to the group
+func GetGroupMembers(ctx context.Context, groupId string) ([]models.InfluencerSmallDataModel, error) {
+	var members []models.InfluencerSmallDataModel
+
+	memberIds, err := getGroupMemberIds(ctx, []string{groupId})
+	if err != nil || len(memberIds) < 1 {
+		return members, err
+	}
+
+	influencers, err := GetInfluencersSmallData(ctx, memberIds)
+	if err != nil {
+		return members, err
+	}
+
+	// keep order of relationships start date
+	for _, memberId := range memberIds {
+		if influencer, ok := influencers[memberId]; ok {
+			members = append(members, influencer)
+		}
+	}
+
+	return members, nil
+}
+
+// function to add current members of every group in influencersIds
+// influencer which is not a group returned as is
+func ExpandGroupMembers(ctx context.Context, influencersIds []string) ([]string, error) {
+	memberIds, err := getGroupMemberIds(ctx, influencersIds)
+	if err != nil {
+		return influencersIds, err
+	}
+
+	expanded := append([]string{}, influencersIds...)
+	exists := map[string]bool{}
+	for _, id := range influencersIds {
+		exists[id] = true
+	}
+
+	for _, id := range memberIds {
+		if !exists[id] {
+			exists[id] = true
+			expanded = append(expanded, id)
+		}
+	}
+
+	return expanded, nil
+}
+
+func getGroupMemberIds(ctx context.Context, groupIds []string) ([]string, error) {
+	var memberIds []string
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	// empty start_on and end_on means open range
+	filter := bson.M{
+		"type":       models.RelationshipMemberOf,
+		"related_id": bson.M{"$in": groupIds},
+		"$and": bson.A{
+			bson.M{"$or": bson.A{bson.M{"start_on": bson.M{"$exists": false}}, bson.M{"start_on": bson.M{"$lte": now}}}},
+			bson.M{"$or": bson.A{bson.M{"end_on": bson.M{"$exists": false}}, bson.M{"end_on": 0}, bson.M{"end_on": bson.M{"$gte": now}}}},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{"start_on", 1}})
+
+	results, err := RelationshipsCollections.Find(ctx, filter, opts)
+	if err != nil {
+		return memberIds, err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		var relationship models.RelationshipModel
+		if err = results.Decode(&relationship); err != nil {
+			return memberIds, err
+		}
+		memberIds = append(memberIds, relationship.InfluencerID)
+	}
+
+	return memberIds, nil
+}
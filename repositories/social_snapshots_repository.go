@@ -0,0 +1,231 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var SocialSnapshotsCollections *mongo.Collection = configs.GetCollection(configs.DB, "social_snapshots")
+
+var ErrInfluencerNotFound = errors.New("influencer not found")
+
+// struct of GetSocialLeaderboard() params
+type SocialLeaderboardParams struct {
+	Type    string
+	Since   int64
+	Limit   int64
+	OrderBy string
+}
+
+// function to fetch all influencers socials and record follower snapshots
+// social data on influencer also updated with latest stats
+// influencer which fails is logged and skipped, so one broken account doesn't stop the others
+// return total snapshots recorded, error tells how many influencers failed
+func RefreshSocialSnapshots(ctx context.Context, fetcher utils.SocialFetcher) (int, error) {
+	total := 0
+	failed := 0
+	var firstErr error
+
+	results, err := InfluencersCollections.Find(ctx, bson.M{"socials.0": bson.M{"$exists": true}})
+	if err != nil {
+		return total, err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		var influencer models.InfluencerModel
+		if err = results.Decode(&influencer); err != nil {
+			return total, err
+		}
+
+		count, err := refreshInfluencerSocials(ctx, fetcher, influencer)
+		total += count
+		if err != nil {
+			fmt.Printf("Failed to refresh socials of %s: %v\n", influencer.Id.Hex(), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		}
+	}
+	if err = results.Err(); err != nil {
+		return total, err
+	}
+
+	if failed > 0 {
+		return total, fmt.Errorf("failed to refresh socials of %d influencers: %w", failed, firstErr)
+	}
+	return total, nil
+}
+
+// function to fetch socials of single influencer and record the snapshots
+// ErrInfluencerNotFound when id is invalid or influencer doesn't exist
+func RefreshInfluencerSocialSnapshots(ctx context.Context, fetcher utils.SocialFetcher, influencerId string) (int, error) {
+	var influencer models.InfluencerModel
+	objId, err := primitive.ObjectIDFromHex(influencerId)
+	if err != nil {
+		return 0, ErrInfluencerNotFound
+	}
+
+	err = InfluencersCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&influencer)
+	if err == mongo.ErrNoDocuments {
+		return 0, ErrInfluencerNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return refreshInfluencerSocials(ctx, fetcher, influencer)
+}
+
+func refreshInfluencerSocials(ctx context.Context, fetcher utils.SocialFetcher, influencer models.InfluencerModel) (int, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	var snapshots []interface{}
+
+	for key := range influencer.Socials {
+		social := &influencer.Socials[key]
+
+		stats, err := fetcher.Fetch(ctx, *social)
+		if err == utils.ErrSocialNotFound {
+			// platform without stats, ex: personal website
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to fetch %s of %s: %w", social.Type, influencer.Name, err)
+		}
+
+		social.Handle = stats.Handle
+		social.Followers = stats.Followers
+		social.IsVerified = stats.IsVerified
+		social.FollowersUpdatedOn = now
+
+		snapshots = append(snapshots, models.SocialSnapshotModel{
+			InfluencerID: influencer.Id.Hex(),
+			Type:         social.Type,
+			Handle:       stats.Handle,
+			Followers:    stats.Followers,
+			IsVerified:   stats.IsVerified,
+			CreatedOn:    now,
+		})
+	}
+
+	if len(snapshots) < 1 {
+		return 0, nil
+	}
+
+	_, err := SocialSnapshotsCollections.InsertMany(ctx, snapshots)
+	if err != nil {
+		return 0, err
+	}
+
+	// socials stats updated without touch updated_on, because updated_on is used for latest content
+	_, err = InfluencersCollections.UpdateOne(ctx, bson.D{{"_id", influencer.Id}}, bson.D{{"$set", bson.D{{"socials", influencer.Socials}}}})
+
+	return len(snapshots), err
+}
+
+// function to get growth curve of every social account owned by influencer
+func GetSocialGrowth(ctx context.Context, influencerId string, since int64) ([]models.SocialGrowthModel, error) {
+	var growths []models.SocialGrowthModel
+
+	filter := bson.M{
+		"influencer_id": influencerId,
+		"created_on":    bson.M{"$gte": since},
+	}
+	opts := options.Find().SetSort(bson.D{{"created_on", 1}})
+
+	results, err := SocialSnapshotsCollections.Find(ctx, filter, opts)
+	if err != nil {
+		return growths, err
+	}
+	defer results.Close(ctx)
+
+	var snapshots []models.SocialSnapshotModel
+	if err = results.All(ctx, &snapshots); err != nil {
+		return growths, err
+	}
+
+	return utils.SocialGrowths(snapshots), nil
+}
+
+// function to get fastest growing social accounts on given period
+// sort by growth rate by default, or by gained followers if OrderBy is "gained"
+func GetSocialLeaderboard(ctx context.Context, params SocialLeaderboardParams) ([]models.SocialLeaderboardModel, error) {
+	var leaderboard []models.SocialLeaderboardModel
+
+	match := bson.M{"created_on": bson.M{"$gte": params.Since}}
+	if params.Type != "" {
+		match["type"] = params.Type
+	}
+
+	sortBy := "growth_rate"
+	if params.OrderBy == "gained" {
+		sortBy = "gained"
+	}
+
+	pipeline := mongo.Pipeline{
+		{{"$match", match}},
+		{{"$sort", bson.D{{"created_on", 1}}}},
+		{{"$group", bson.D{
+			{"_id", bson.D{{"influencer_id", "$influencer_id"}, {"type", "$type"}}},
+			{"handle", bson.D{{"$last", "$handle"}}},
+			{"first_followers", bson.D{{"$first", "$followers"}}},
+			{"last_followers", bson.D{{"$last", "$followers"}}},
+		}}},
+		{{"$project", bson.D{
+			{"_id", 0},
+			{"influencer_id", "$_id.influencer_id"},
+			{"type", "$_id.type"},
+			{"handle", 1},
+			{"first_followers", 1},
+			{"last_followers", 1},
+			{"gained", bson.D{{"$subtract", bson.A{"$last_followers", "$first_followers"}}}},
+			{"growth_rate", bson.D{{"$cond", bson.A{
+				bson.D{{"$gt", bson.A{"$first_followers", 0}}},
+				bson.D{{"$divide", bson.A{bson.D{{"$subtract", bson.A{"$last_followers", "$first_followers"}}}, "$first_followers"}}},
+				0,
+			}}}},
+		}}},
+		{{"$sort", bson.D{{sortBy, -1}}}},
+		{{"$limit", params.Limit}},
+	}
+
+	results, err := SocialSnapshotsCollections.Aggregate(ctx, pipeline)
+	if err != nil {
+		return leaderboard, err
+	}
+	defer results.Close(ctx)
+
+	if err = results.All(ctx, &leaderboard); err != nil {
+		return leaderboard, err
+	}
+
+	// attach influencers data
+	var influencerIds []string
+	for key := range leaderboard {
+		influencerIds = append(influencerIds, leaderboard[key].InfluencerID)
+	}
+
+	influencers, err := GetInfluencersSmallData(ctx, influencerIds)
+	if err != nil {
+		return leaderboard, err
+	}
+
+	for key := range leaderboard {
+		if influencer, ok := influencers[leaderboard[key].InfluencerID]; ok {
+			leaderboard[key].Influencer = &influencer
+		}
+	}
+
+	return leaderboard, nil
+}
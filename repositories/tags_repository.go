@@ -0,0 +1,504 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var TagsCollections *mongo.Collection = configs.GetCollection(configs.DB, "tags")
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagName     = errors.New("name is required")
+	ErrTagConflict = errors.New("tag is already used by other tag")
+	ErrTagMerge    = errors.New("tags to merge are required")
+)
+
+// struct of ListTags() params
+type ListTagsParams struct {
+	Search string
+	Lang   string
+	Limit  int64
+	Page   int64
+}
+
+// struct of GetTagFeed() params
+type TagFeedParams struct {
+	Slug   string
+	Lang   string
+	Cursor *utils.FeedCursor
+	Limit  int64
+}
+
+// function to create tag, usage counts are counted from existing news and galleries
+func CreateTag(ctx context.Context, payload models.PayloadTag) (models.TagModel, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+
+	tag := models.TagModel{
+		Id:        primitive.NewObjectID(),
+		Name:      strings.TrimSpace(payload.Name),
+		Labels:    normalizeLabels(payload.Labels),
+		Synonyms:  payload.Synonyms,
+		CreatedOn: now,
+		UpdatedOn: now,
+	}
+
+	if err := prepareTag(ctx, &tag, nil); err != nil {
+		return tag, err
+	}
+	if err := countTag(ctx, &tag); err != nil {
+		return tag, err
+	}
+
+	_, err := TagsCollections.InsertOne(ctx, tag)
+	return tag, err
+}
+
+// function to update name, labels and synonyms of tag by its slug, the slug is kept so links of the tag still work
+// on rename the old name becomes synonym and news and galleries are rewritten to the new name, like merged tags
+func UpdateTag(ctx context.Context, slug string, payload models.PayloadTag) (models.TagModel, error) {
+	tag, err := GetTag(ctx, slug)
+	if err != nil {
+		return tag, err
+	}
+
+	oldName := tag.Name
+	tag.Name = strings.TrimSpace(payload.Name)
+	tag.Labels = normalizeLabels(payload.Labels)
+	tag.Synonyms = payload.Synonyms
+	tag.UpdatedOn = time.Now().UnixNano() / int64(time.Millisecond)
+
+	renamed := tag.Name != oldName
+	if renamed {
+		tag.Synonyms = append(tag.Synonyms, oldName)
+	}
+
+	if err = prepareTag(ctx, &tag, nil); err != nil {
+		return tag, err
+	}
+
+	if renamed {
+		for _, collection := range []*mongo.Collection{NewsCollections, GalleryCollections} {
+			if _, err = rewriteContentTags(ctx, collection, tag); err != nil {
+				return tag, err
+			}
+		}
+	}
+
+	if err = countTag(ctx, &tag); err != nil {
+		return tag, err
+	}
+
+	_, err = TagsCollections.ReplaceOne(ctx, bson.M{"_id": tag.Id}, tag)
+	return tag, err
+}
+
+// function to get tag by its slug or id
+func GetTag(ctx context.Context, slug string) (models.TagModel, error) {
+	var tag models.TagModel
+
+	filter := bson.M{"slug": utils.Slugify(slug)}
+	if objId, err := primitive.ObjectIDFromHex(slug); err == nil {
+		filter = bson.M{"_id": objId}
+	}
+
+	err := TagsCollections.FindOne(ctx, filter).Decode(&tag)
+	if err == mongo.ErrNoDocuments {
+		return tag, ErrTagNotFound
+	}
+	if tag.Labels == nil {
+		tag.Labels = map[string]string{}
+	}
+	return tag, err
+}
+
+// function to list tags, most used first
+func ListTags(ctx context.Context, params ListTagsParams) ([]models.TagModel, int64, error) {
+	tags := []models.TagModel{}
+
+	filter := bson.M{}
+	if params.Search != "" {
+		search := bson.A{bson.M{"name": bson.M{"$regex": regexp.QuoteMeta(params.Search), "$options": "i"}}}
+		if key := utils.TagKey(params.Search); key != "" {
+			search = append(search, bson.M{"keys": bson.M{"$regex": "^" + regexp.QuoteMeta(key)}})
+		}
+		filter["$or"] = search
+	}
+
+	opts := options.Find().SetSort(bson.D{{"count", -1}, {"name", 1}}).SetLimit(params.Limit).SetSkip((params.Page - 1) * params.Limit)
+
+	results, err := TagsCollections.Find(ctx, filter, opts)
+	if err != nil {
+		return tags, 0, err
+	}
+	defer results.Close(ctx)
+
+	if err = results.All(ctx, &tags); err != nil {
+		return tags, 0, err
+	}
+	for key := range tags {
+		setTagLabel(&tags[key], params.Lang)
+	}
+
+	count, err := TagsCollections.CountDocuments(ctx, filter)
+	return tags, count, err
+}
+
+// function to get news and galleries of tag as one feed, sorted by date descending
+// contents saved with a synonym or other spelling are found too
+func GetTagFeed(ctx context.Context, params TagFeedParams) (models.TagModel, []models.TimelineItemModel, string, error) {
+	tag, err := GetTag(ctx, params.Slug)
+	if err != nil {
+		return tag, nil, "", err
+	}
+	setTagLabel(&tag, params.Lang)
+
+	filter := tagContentsFilter(tag.Keys)
+	if params.Lang != "" {
+		filter["lang"] = params.Lang
+	}
+
+	news, err := findFeedItems(ctx, models.TimelineNews, filter, params.Cursor, params.Limit+1)
+	if err != nil {
+		return tag, nil, "", err
+	}
+
+	galleries, err := findFeedItems(ctx, models.TimelineGallery, filter, params.Cursor, params.Limit+1)
+	if err != nil {
+		return tag, nil, "", err
+	}
+
+	items, nextCursor := pageFeedItems(append(news, galleries...), params.Limit)
+	return tag, items, nextCursor, nil
+}
+
+// function to replace tags with their canonical name before news or gallery is saved
+// unknown tags are kept as is, repeated tags are removed
+func NormalizeTags(ctx context.Context, tags []string) ([]string, error) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	var keys []string
+	for _, tag := range tags {
+		if key := utils.TagKey(tag); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	names := map[string]string{}
+	if len(keys) > 0 {
+		canonicalTags, err := findTagsByKeys(ctx, keys)
+		if err != nil {
+			return tags, err
+		}
+		for _, tag := range canonicalTags {
+			for _, key := range tag.Keys {
+				names[key] = tag.Name
+			}
+		}
+	}
+
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if name, ok := names[utils.TagKey(tag)]; ok {
+			tag = name
+		}
+
+		key := utils.TagKey(tag)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+// function to merge tags into the tag, merged tags become its synonyms and are deleted
+// news and galleries with merged tags are rewritten to the tag name, returns number of rewritten contents
+func MergeTags(ctx context.Context, slug string, sources []string) (models.TagModel, int64, error) {
+	tag, err := GetTag(ctx, slug)
+	if err != nil {
+		return tag, 0, err
+	}
+
+	// sources are slugs of other tags or free tags
+	var mergedIds []primitive.ObjectID
+	synonyms := append([]string{}, tag.Synonyms...)
+	for _, source := range sources {
+		source = strings.TrimSpace(source)
+		if utils.TagKey(source) == "" {
+			continue
+		}
+
+		merged, err := GetTag(ctx, source)
+		if err == ErrTagNotFound {
+			synonyms = append(synonyms, source)
+			continue
+		}
+		if err != nil {
+			return tag, 0, err
+		}
+		if merged.Id == tag.Id {
+			continue
+		}
+
+		mergedIds = append(mergedIds, merged.Id)
+		synonyms = append(synonyms, merged.Name)
+		synonyms = append(synonyms, merged.Synonyms...)
+		for lang, label := range merged.Labels {
+			if _, ok := tag.Labels[lang]; !ok {
+				tag.Labels[lang] = label
+			}
+		}
+	}
+	if len(synonyms) == len(tag.Synonyms) && len(mergedIds) == 0 {
+		return tag, 0, ErrTagMerge
+	}
+
+	tag.Synonyms = synonyms
+	tag.UpdatedOn = time.Now().UnixNano() / int64(time.Millisecond)
+	if err = prepareTag(ctx, &tag, mergedIds); err != nil {
+		return tag, 0, err
+	}
+
+	var rewritten int64
+	for _, collection := range []*mongo.Collection{NewsCollections, GalleryCollections} {
+		total, err := rewriteContentTags(ctx, collection, tag)
+		rewritten += total
+		if err != nil {
+			return tag, rewritten, err
+		}
+	}
+
+	if len(mergedIds) > 0 {
+		if _, err = TagsCollections.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": mergedIds}}); err != nil {
+			return tag, rewritten, err
+		}
+	}
+
+	if err = countTag(ctx, &tag); err != nil {
+		return tag, rewritten, err
+	}
+	_, err = TagsCollections.ReplaceOne(ctx, bson.M{"_id": tag.Id}, tag)
+	return tag, rewritten, err
+}
+
+// function to recount usage of tags after news or gallery is saved, unknown tags are skipped
+func RefreshTagCounts(ctx context.Context, tags []string) error {
+	var keys []string
+	for _, tag := range tags {
+		if key := utils.TagKey(tag); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) < 1 {
+		return nil
+	}
+
+	canonicalTags, err := findTagsByKeys(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	for key := range canonicalTags {
+		tag := &canonicalTags[key]
+		if err = countTag(ctx, tag); err != nil {
+			return err
+		}
+
+		_, err = TagsCollections.UpdateOne(ctx, bson.M{"_id": tag.Id}, bson.M{"$set": bson.M{
+			"news_count":      tag.NewsCount,
+			"galleries_count": tag.GalleriesCount,
+			"count":           tag.Count,
+		}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareTag normalizes slug, synonyms and keys of tag, slug of saved tag is kept
+// keys must not be used by other tags, except tags which are going to be merged
+func prepareTag(ctx context.Context, tag *models.TagModel, mergedIds []primitive.ObjectID) error {
+	key := utils.TagKey(tag.Name)
+	if key == "" {
+		return ErrTagName
+	}
+	if tag.Slug == "" {
+		tag.Slug = utils.Slugify(tag.Name)
+	}
+	if tag.Slug == "" {
+		tag.Slug = key
+	}
+
+	// synonym with the same key as the name or other synonym is removed
+	synonyms := []string{}
+	keys := []string{key}
+	seen := map[string]bool{key: true}
+	for _, synonym := range tag.Synonyms {
+		synonym = strings.TrimSpace(synonym)
+		synonymKey := utils.TagKey(synonym)
+		if synonymKey == "" || seen[synonymKey] {
+			continue
+		}
+		seen[synonymKey] = true
+		synonyms = append(synonyms, synonym)
+		keys = append(keys, synonymKey)
+	}
+	tag.Synonyms = synonyms
+	tag.Keys = keys
+
+	excluded := append([]primitive.ObjectID{tag.Id}, mergedIds...)
+	var other models.TagModel
+	err := TagsCollections.FindOne(ctx, bson.M{
+		"_id": bson.M{"$nin": excluded},
+		"$or": bson.A{bson.M{"keys": bson.M{"$in": keys}}, bson.M{"slug": tag.Slug}},
+	}).Decode(&other)
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrTagConflict, other.Name)
+	}
+	if err != mongo.ErrNoDocuments {
+		return err
+	}
+	return nil
+}
+
+// countTag counts news and galleries using tag
+func countTag(ctx context.Context, tag *models.TagModel) error {
+	filter := tagContentsFilter(tag.Keys)
+
+	newsCount, err := NewsCollections.CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+	galleriesCount, err := GalleryCollections.CountDocuments(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	tag.NewsCount = newsCount
+	tag.GalleriesCount = galleriesCount
+	tag.Count = newsCount + galleriesCount
+	return nil
+}
+
+// rewriteContentTags replaces every spelling of tag with its name on news or galleries
+func rewriteContentTags(ctx context.Context, collection *mongo.Collection, tag models.TagModel) (int64, error) {
+	keys := map[string]bool{}
+	for _, key := range tag.Keys {
+		keys[key] = true
+	}
+
+	opts := options.Find().SetProjection(bson.M{"tags": 1})
+	results, err := collection.Find(ctx, tagContentsFilter(tag.Keys), opts)
+	if err != nil {
+		return 0, err
+	}
+	defer results.Close(ctx)
+
+	var updates []mongo.WriteModel
+	for results.Next(ctx) {
+		var content struct {
+			Id   primitive.ObjectID `bson:"_id"`
+			Tags []string           `bson:"tags"`
+		}
+		if err = results.Decode(&content); err != nil {
+			return 0, err
+		}
+
+		tags := []string{}
+		seen := map[string]bool{}
+		for _, contentTag := range content.Tags {
+			if keys[utils.TagKey(contentTag)] {
+				contentTag = tag.Name
+			}
+			if seen[utils.TagKey(contentTag)] {
+				continue
+			}
+			seen[utils.TagKey(contentTag)] = true
+			tags = append(tags, contentTag)
+		}
+
+		if strings.Join(tags, "\n") != strings.Join(content.Tags, "\n") {
+			updates = append(updates, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": content.Id}).SetUpdate(bson.M{"$set": bson.M{"tags": tags}}))
+		}
+	}
+	if err = results.Err(); err != nil {
+		return 0, err
+	}
+	if len(updates) < 1 {
+		return 0, nil
+	}
+
+	result, err := collection.BulkWrite(ctx, updates)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// tagContentsFilter finds news or galleries with any spelling of the keys
+func tagContentsFilter(keys []string) bson.M {
+	regexes := bson.A{}
+	for _, key := range keys {
+		regexes = append(regexes, utils.TagKeyRegex(key))
+	}
+	return bson.M{"tags": bson.M{"$in": regexes}}
+}
+
+// findTagsByKeys finds tags with name or synonym of the keys
+func findTagsByKeys(ctx context.Context, keys []string) ([]models.TagModel, error) {
+	tags := []models.TagModel{}
+
+	results, err := TagsCollections.Find(ctx, bson.M{"keys": bson.M{"$in": keys}})
+	if err != nil {
+		return tags, err
+	}
+	defer results.Close(ctx)
+
+	err = results.All(ctx, &tags)
+	return tags, err
+}
+
+// normalizeLabels uppercases languages and trims labels of tag or category, empty labels are removed
+func normalizeLabels(labels map[string]string) map[string]string {
+	normalized := map[string]string{}
+	for lang, label := range labels {
+		lang = strings.ToUpper(strings.TrimSpace(lang))
+		label = strings.TrimSpace(label)
+		if lang != "" && label != "" {
+			normalized[lang] = label
+		}
+	}
+	return normalized
+}
+
+// setTagLabel sets label of tag on the language
+func setTagLabel(tag *models.TagModel, lang string) {
+	tag.Label = localizedLabel(tag.Name, tag.Labels, lang)
+}
+
+// localizedLabel gets label on the language, name is used when there is no label
+func localizedLabel(name string, labels map[string]string, lang string) string {
+	if label, ok := labels[strings.ToUpper(lang)]; ok {
+		return label
+	}
+	return name
+}
@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"sort"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// struct of GetInfluencerTimeline() params
+type TimelineParams struct {
+	InfluencerID string
+	Lang         string
+	Year         int
+	Cursor       *utils.FeedCursor
+	Limit        int64
+}
+
+// function to get influencer news, galleries and best moments as one feed
+// sorted by date descending, next cursor is empty on last page
+func GetInfluencerTimeline(ctx context.Context, params TimelineParams) ([]models.TimelineItemModel, string, error) {
+	var influencer models.InfluencerModel
+	objId, _ := primitive.ObjectIDFromHex(params.InfluencerID)
+
+	err := InfluencersCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&influencer)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// filter generator
+	filter := bson.M{"influencers": params.InfluencerID}
+	if params.Lang != "" {
+		filter["lang"] = params.Lang
+	}
+	if params.Year > 0 {
+		filter["created_on"] = bson.M{"$gte": yearStart(params.Year), "$lt": yearStart(params.Year + 1)}
+	}
+
+	news, err := findFeedItems(ctx, models.TimelineNews, filter, params.Cursor, params.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	galleries, err := findFeedItems(ctx, models.TimelineGallery, filter, params.Cursor, params.Limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	items := append(news, galleries...)
+
+	// best moments only have year, placed on the first day of the year
+	for key := range influencer.BestMoments {
+		moment := influencer.BestMoments[key]
+		year, err := strconv.Atoi(moment.Year)
+		if err != nil || (params.Year > 0 && year != params.Year) {
+			continue
+		}
+
+		// moments without id are not saved by best moments api yet, use its position instead
+		momentId := moment.Id
+		if momentId == "" {
+			momentId = strconv.Itoa(key)
+		}
+
+		item := models.TimelineItemModel{
+			Type:       models.TimelineBestMoment,
+			Id:         momentId,
+			Date:       yearStart(year),
+			BestMoment: &moment,
+		}
+		if params.Cursor == nil || params.Cursor.After(item.Date, item.Type, item.Id) {
+			items = append(items, item)
+		}
+	}
+
+	page, nextCursor := pageFeedItems(items, params.Limit)
+	return page, nextCursor, nil
+}
+
+// function to find news or galleries placed after cursor
+// content of news is removed to keep the feed small
+func findFeedItems(ctx context.Context, itemType string, filter bson.M, cursor *utils.FeedCursor, limit int64) ([]models.TimelineItemModel, error) {
+	var items []models.TimelineItemModel
+
+	collection := NewsCollections
+	if itemType == models.TimelineGallery {
+		collection = GalleryCollections
+	}
+
+	opts := options.Find().SetSort(bson.D{{"created_on", -1}, {"_id", -1}}).SetLimit(limit)
+
+	results, err := collection.Find(ctx, bson.M{"$and": bson.A{filter, feedCursorFilter(itemType, cursor)}}, opts)
+	if err != nil {
+		return items, err
+	}
+	defer results.Close(ctx)
+
+	for results.Next(ctx) {
+		item := models.TimelineItemModel{Type: itemType}
+
+		if itemType == models.TimelineGallery {
+			var gallery models.GalleryModel
+			if err = results.Decode(&gallery); err != nil {
+				return items, err
+			}
+			item.Id = gallery.Id.Hex()
+			item.Date = int64(gallery.CreatedOn)
+			utils.SetGalleryRenditions(&gallery)
+			item.Gallery = &gallery
+		} else {
+			var news models.NewsModel
+			if err = results.Decode(&news); err != nil {
+				return items, err
+			}
+			news.Content = ""
+			item.Id = news.Id.Hex()
+			item.Date = int64(news.CreatedOn)
+			utils.SetNewsRenditions(&news)
+			item.News = &news
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// feedCursorFilter generates filter of documents placed after cursor
+// on the same date, items ordered by type then by id
+func feedCursorFilter(itemType string, cursor *utils.FeedCursor) bson.M {
+	if cursor == nil {
+		return bson.M{}
+	}
+
+	if itemType < cursor.Type {
+		return bson.M{"created_on": bson.M{"$lte": cursor.Date}}
+	}
+	if itemType > cursor.Type {
+		return bson.M{"created_on": bson.M{"$lt": cursor.Date}}
+	}
+
+	objId, _ := primitive.ObjectIDFromHex(cursor.Id)
+	return bson.M{"$or": bson.A{
+		bson.M{"created_on": bson.M{"$lt": cursor.Date}},
+		bson.M{"created_on": cursor.Date, "_id": bson.M{"$lt": objId}},
+	}}
+}
+
+// pageFeedItems sorts items by date, type and id descending, then cut it by limit
+// return cursor of next page if there are more items
+func pageFeedItems(items []models.TimelineItemModel, limit int64) ([]models.TimelineItemModel, string) {
+	// item i placed first when item j is after it
+	sort.SliceStable(items, func(i, j int) bool {
+		cursor := utils.FeedCursor{Date: items[i].Date, Type: items[i].Type, Id: items[i].Id}
+		return cursor.After(items[j].Date, items[j].Type, items[j].Id)
+	})
+
+	if int64(len(items)) <= limit {
+		return items, ""
+	}
+
+	items = items[:limit]
+	last := items[len(items)-1]
+	return items, utils.EncodeCursor(utils.FeedCursor{Date: last.Date, Type: last.Type, Id: last.Id})
+}
+
+// yearStart returns first millisecond of the year on UTC
+func yearStart(year int) int64 {
+	return time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+}
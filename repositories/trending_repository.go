@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"context"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var ViewBucketsCollections *mongo.Collection = configs.GetCollection(configs.DB, "view_buckets")
+var TrendingCollections *mongo.Collection = configs.GetCollection(configs.DB, "trending")
+
+// struct of ComputeTrending() params
+// views older than window are not counted, weight of views is halved every half life
+type TrendingParams struct {
+	Window   time.Duration
+	HalfLife time.Duration
+	Now      time.Time
+}
+
+// result of ComputeTrending()
+type TrendingResult struct {
+	Items   int
+	Removed int64
+}
+
+// function to count view of news, gallery or influencer page on the current hour
+// view on language which is not supported is not counted, so query params can't create buckets
+func RecordView(ctx context.Context, itemType string, itemId string, lang string) error {
+	lang = TrendingLang(lang)
+	if lang == "" {
+		return nil
+	}
+
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	hour := now - now%int64(time.Hour/time.Millisecond)
+
+	filter := bson.M{"type": itemType, "item_id": itemId, "lang": lang, "hour": hour}
+	_, err := ViewBucketsCollections.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"views": 1}}, options.Update().SetUpsert(true))
+	return err
+}
+
+// function to normalise language of trending, ex: id -> ID
+// empty when language is not supported
+func TrendingLang(lang string) string {
+	lang = strings.ToUpper(strings.TrimSpace(lang))
+	for _, supported := range models.TrendingLangs {
+		if lang == supported {
+			return lang
+		}
+	}
+	return ""
+}
+
+// function to compute trending score of every item viewed on the window
+// score is sum of hourly views, views of each hour are weighted by exp(-ln2 * age / half life)
+// trending_score of news, galleries and influencers is updated for order_by=trending, old buckets are removed
+func ComputeTrending(ctx context.Context, params TrendingParams) (TrendingResult, error) {
+	var result TrendingResult
+
+	now := params.Now.UnixNano() / int64(time.Millisecond)
+	since := now - int64(params.Window/time.Millisecond)
+	// negative decay rate per millisecond, now - hour is the age of the views
+	rate := -math.Ln2 / float64(params.HalfLife/time.Millisecond)
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.M{"hour": bson.M{"$gte": since}}}},
+		{{"$group", bson.M{
+			"_id": bson.M{"type": "$type", "item_id": "$item_id", "lang": "$lang"},
+			"score": bson.M{"$sum": bson.M{"$multiply": bson.A{
+				"$views",
+				bson.M{"$exp": bson.M{"$multiply": bson.A{rate, bson.M{"$subtract": bson.A{now, "$hour"}}}}},
+			}}},
+			"views": bson.M{"$sum": "$views"},
+		}}},
+	}
+
+	cursor, err := ViewBucketsCollections.Aggregate(ctx, pipeline)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+
+	var updates []mongo.WriteModel
+	// scores of every item type, influencer score is sum of its languages
+	itemScores := map[string]map[string]float64{
+		models.TrendingNews:       {},
+		models.TrendingGallery:    {},
+		models.TrendingInfluencer: {},
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			Id struct {
+				Type   string `bson:"type"`
+				ItemID string `bson:"item_id"`
+				Lang   string `bson:"lang"`
+			} `bson:"_id"`
+			Score float64 `bson:"score"`
+			Views int64   `bson:"views"`
+		}
+		if err = cursor.Decode(&row); err != nil {
+			return result, err
+		}
+
+		score := models.TrendingScoreModel{
+			Type:       row.Id.Type,
+			ItemID:     row.Id.ItemID,
+			Lang:       row.Id.Lang,
+			Score:      row.Score,
+			Views:      row.Views,
+			ComputedOn: now,
+		}
+		filter := bson.M{"type": score.Type, "item_id": score.ItemID, "lang": score.Lang}
+		updates = append(updates, mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(score).SetUpsert(true))
+
+		if scores, ok := itemScores[score.Type]; ok {
+			scores[score.ItemID] += score.Score
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return result, err
+	}
+	result.Items = len(updates)
+
+	if len(updates) > 0 {
+		if _, err = TrendingCollections.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false)); err != nil {
+			return result, err
+		}
+	}
+
+	// items not viewed on the window are not trending anymore
+	if _, err = TrendingCollections.DeleteMany(ctx, bson.M{"computed_on": bson.M{"$ne": now}}); err != nil {
+		return result, err
+	}
+
+	for itemType, collection := range map[string]*mongo.Collection{
+		models.TrendingNews:       NewsCollections,
+		models.TrendingGallery:    GalleryCollections,
+		models.TrendingInfluencer: InfluencersCollections,
+	} {
+		if err = setTrendingScores(ctx, collection, itemScores[itemType]); err != nil {
+			return result, err
+		}
+	}
+
+	removed, err := ViewBucketsCollections.DeleteMany(ctx, bson.M{"hour": bson.M{"$lt": since}})
+	if err != nil {
+		return result, err
+	}
+	result.Removed = removed.DeletedCount
+	return result, nil
+}
+
+// function to get top trending items of the type on the language, highest score first
+// items which are deleted after the score is computed are skipped
+func GetTrending(ctx context.Context, itemType string, lang string, limit int64) ([]models.TrendingItemModel, error) {
+	items := []models.TrendingItemModel{}
+
+	var scores []models.TrendingScoreModel
+	opts := options.Find().SetSort(bson.D{{"score", -1}, {"item_id", 1}}).SetLimit(limit)
+	results, err := TrendingCollections.Find(ctx, bson.M{"type": itemType, "lang": strings.ToUpper(lang)}, opts)
+	if err != nil {
+		return items, err
+	}
+	defer results.Close(ctx)
+
+	if err = results.All(ctx, &scores); err != nil {
+		return items, err
+	}
+	if len(scores) < 1 {
+		return items, nil
+	}
+
+	var ids []string
+	var objIds []primitive.ObjectID
+	for _, score := range scores {
+		if objId, err := primitive.ObjectIDFromHex(score.ItemID); err == nil {
+			ids = append(ids, score.ItemID)
+			objIds = append(objIds, objId)
+		}
+	}
+
+	found := map[string]models.TrendingItemModel{}
+	if itemType == models.TrendingInfluencer {
+		influencers, err := GetInfluencersSmallData(ctx, ids)
+		if err != nil {
+			return items, err
+		}
+		for id := range influencers {
+			influencer := influencers[id]
+			found[id] = models.TrendingItemModel{Influencer: &influencer}
+		}
+	} else {
+		contents, err := findFeedItems(ctx, itemType, bson.M{"_id": bson.M{"$in": objIds}}, nil, 0)
+		if err != nil {
+			return items, err
+		}
+		for _, content := range contents {
+			found[content.Id] = models.TrendingItemModel{News: content.News, Gallery: content.Gallery}
+		}
+	}
+
+	for _, score := range scores {
+		item, ok := found[score.ItemID]
+		if !ok {
+			continue
+		}
+		item.Type = itemType
+		item.Id = score.ItemID
+		item.Score = score.Score
+		item.Views = score.Views
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// setTrendingScores saves trending_score of items, score of items not on scores is reset
+func setTrendingScores(ctx context.Context, collection *mongo.Collection, scores map[string]float64) error {
+	var objIds []primitive.ObjectID
+	var updates []mongo.WriteModel
+	for id, score := range scores {
+		objId, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			continue
+		}
+		objIds = append(objIds, objId)
+		updates = append(updates, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": objId}).SetUpdate(bson.M{"$set": bson.M{"trending_score": score}}))
+	}
+
+	if len(updates) > 0 {
+		if _, err := collection.BulkWrite(ctx, updates, options.BulkWrite().SetOrdered(false)); err != nil {
+			return err
+		}
+	}
+
+	filter := bson.M{"trending_score": bson.M{"$gt": 0}}
+	if len(objIds) > 0 {
+		filter["_id"] = bson.M{"$nin": objIds}
+	}
+	_, err := collection.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{"trending_score": ""}})
+	return err
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"follooow-be/utils"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var UploadSessionsCollections *mongo.Collection = configs.GetCollection(configs.DB, "upload_sessions")
+
+var (
+	ErrUploadSessionNotFound = errors.New("upload session not found")
+	ErrUploadSessionBusy     = errors.New("upload session is being completed")
+	ErrUploadSessionClosed   = errors.New("upload session is already completed")
+	ErrUploadSessionWriting  = errors.New("upload session chunk is being written")
+)
+
+// function to create upload session and its empty file, only its uploader can send chunks
+func CreateUploadSession(ctx context.Context, payload models.PayloadUploadSession, uploaderID string) (models.UploadSessionModel, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	chunkSize := utils.UploadChunkSize(payload.ChunkSize)
+
+	session := models.UploadSessionModel{
+		Id:          primitive.NewObjectID(),
+		Filename:    payload.Filename,
+		Directory:   payload.Directory,
+		Size:        payload.Size,
+		ChunkSize:   chunkSize,
+		TotalChunks: int((payload.Size + chunkSize - 1) / chunkSize),
+		Received:    []int{},
+		Sha256:      payload.Sha256,
+		UploaderID:  uploaderID,
+		Tags:        payload.Tags,
+		Status:      models.UploadSessionPending,
+		CreatedOn:   now,
+		UpdatedOn:   now,
+		ExpiresOn:   uploadSessionExpiresOn(),
+	}
+
+	if err := utils.CreateUploadFile(session.Id.Hex(), session.Size); err != nil {
+		return session, err
+	}
+
+	if _, err := UploadSessionsCollections.InsertOne(ctx, session); err != nil {
+		utils.RemoveUploadFile(session.Id.Hex())
+		return session, err
+	}
+
+	return session, nil
+}
+
+// function to get upload session by its id
+func GetUploadSession(ctx context.Context, sessionId string) (models.UploadSessionModel, error) {
+	var session models.UploadSessionModel
+
+	objId, err := primitive.ObjectIDFromHex(sessionId)
+	if err != nil {
+		return session, ErrUploadSessionNotFound
+	}
+
+	err = UploadSessionsCollections.FindOne(ctx, bson.M{"_id": objId}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return session, ErrUploadSessionNotFound
+	}
+	sort.Ints(session.Received)
+	return session, err
+}
+
+// function to start writing chunk to the file, only pending session accepts chunks
+// the write is counted atomically with the status check, so the session can't be claimed while the file changes
+// every started chunk must be finished by FinishUploadSessionChunk
+func StartUploadSessionChunk(ctx context.Context, sessionId primitive.ObjectID) error {
+	err := UploadSessionsCollections.FindOneAndUpdate(ctx, bson.M{"_id": sessionId, "status": models.UploadSessionPending}, bson.M{"$inc": bson.M{"writing": 1}}).Err()
+	if err == mongo.ErrNoDocuments {
+		return ErrUploadSessionClosed
+	}
+	return err
+}
+
+// function to finish writing chunk, chunk is marked as received when it is written
+func FinishUploadSessionChunk(ctx context.Context, sessionId primitive.ObjectID, index int, written bool) (models.UploadSessionModel, error) {
+	var session models.UploadSessionModel
+
+	update := bson.M{"$inc": bson.M{"writing": -1}}
+	if written {
+		update["$addToSet"] = bson.M{"received": index}
+		update["$set"] = bson.M{"updated_on": time.Now().UnixNano() / int64(time.Millisecond), "expires_on": uploadSessionExpiresOn()}
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	err := UploadSessionsCollections.FindOneAndUpdate(ctx, bson.M{"_id": sessionId}, update, opts).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return session, ErrUploadSessionNotFound
+	}
+	sort.Ints(session.Received)
+	return session, err
+}
+
+// function to claim pending upload session to complete it, so the file is uploaded once
+// session with chunk being written is not claimed, completed session is returned as is,
+// its result is the response of the first complete
+func ClaimUploadSession(ctx context.Context, sessionId primitive.ObjectID) (models.UploadSessionModel, error) {
+	var session models.UploadSessionModel
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	update := bson.M{"$set": bson.M{"status": models.UploadSessionCompleting, "updated_on": time.Now().UnixNano() / int64(time.Millisecond)}}
+	filter := bson.M{"_id": sessionId, "status": models.UploadSessionPending, "writing": bson.M{"$not": bson.M{"$gt": 0}}}
+
+	err := UploadSessionsCollections.FindOneAndUpdate(ctx, filter, update, opts).Decode(&session)
+	if err != mongo.ErrNoDocuments {
+		return session, err
+	}
+
+	// not claimable, chunk is being written, completed by other request or still being completed
+	err = UploadSessionsCollections.FindOne(ctx, bson.M{"_id": sessionId}).Decode(&session)
+	if err == mongo.ErrNoDocuments {
+		return session, ErrUploadSessionNotFound
+	}
+	if err == nil && session.Status == models.UploadSessionPending {
+		return session, ErrUploadSessionWriting
+	}
+	if err == nil && session.Status == models.UploadSessionCompleting {
+		return session, ErrUploadSessionBusy
+	}
+	return session, err
+}
+
+// function to release claimed upload session when its upload failed, so complete can be retried
+func ReleaseUploadSession(ctx context.Context, sessionId primitive.ObjectID) error {
+	_, err := UploadSessionsCollections.UpdateOne(ctx, bson.M{"_id": sessionId, "status": models.UploadSessionCompleting}, bson.M{"$set": bson.M{"status": models.UploadSessionPending}})
+	return err
+}
+
+// function to save result of completed upload session
+func CompleteUploadSession(ctx context.Context, sessionId primitive.ObjectID, result models.UploadedMediaModel) error {
+	_, err := UploadSessionsCollections.UpdateOne(ctx, bson.M{"_id": sessionId}, bson.M{"$set": bson.M{
+		"status":     models.UploadSessionCompleted,
+		"result":     result,
+		"updated_on": time.Now().UnixNano() / int64(time.Millisecond),
+		"expires_on": uploadSessionExpiresOn(),
+	}})
+	return err
+}
+
+// function to delete upload session and its file
+func DeleteUploadSession(ctx context.Context, sessionId primitive.ObjectID) error {
+	if _, err := UploadSessionsCollections.DeleteOne(ctx, bson.M{"_id": sessionId}); err != nil {
+		return err
+	}
+	return utils.RemoveUploadFile(sessionId.Hex())
+}
+
+// function to delete expired upload sessions and files without session
+// returns number of deleted sessions
+func CleanupUploadSessions(ctx context.Context) (int, error) {
+	now := time.Now()
+	results, err := UploadSessionsCollections.Find(ctx, bson.M{"expires_on": bson.M{"$lt": now.UnixNano() / int64(time.Millisecond)}})
+	if err != nil {
+		return 0, err
+	}
+	defer results.Close(ctx)
+
+	total := 0
+	for results.Next(ctx) {
+		var session models.UploadSessionModel
+		if err = results.Decode(&session); err != nil {
+			return total, err
+		}
+		if err = DeleteUploadSession(ctx, session.Id); err != nil {
+			return total, err
+		}
+		total++
+	}
+
+	// file is written on every chunk, stale file belongs to expired or deleted session
+	removed, err := utils.RemoveStaleUploadFiles(now.Add(-utils.UploadSessionTTL()))
+	if removed > 0 {
+		fmt.Printf("Removed %d upload files without session\n", removed)
+	}
+
+	return total, err
+}
+
+func uploadSessionExpiresOn() int64 {
+	return time.Now().Add(utils.UploadSessionTTL()).UnixNano() / int64(time.Millisecond)
+}
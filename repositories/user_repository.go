@@ -66,3 +66,18 @@ func FindUserByID(id primitive.ObjectID) (*models.UserModel, error) {
 
 	return &user, nil
 }
+
+// function to change role of user, returns mongo.ErrNoDocuments when username is not found
+func SetUserRole(username string, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := userCollection.UpdateOne(ctx, bson.M{"username": username}, bson.M{"$set": bson.M{"role": role, "updated_at": time.Now().Unix()}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount < 1 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"follooow-be/handlers"
+	"follooow-be/middlewares"
+
+	"github.com/labstack/echo/v4"
+)
+
+func AdminRoute(e *echo.Echo) {
+	// all routes relates to admin reports comes here
+	e.GET("/admin/images/duplicates", handlers.DuplicateImagesReport, middlewares.AdminAuth)
+	e.GET("/admin/images/licenses", handlers.ExpiringImageLicensesReport, middlewares.AdminAuth)
+	// orphans report lists every stored file, so it is only for admin
+	e.GET("/admin/media/orphans", handlers.OrphanMediaReport, middlewares.AdminAuth)
+}
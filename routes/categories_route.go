@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"follooow-be/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func CategoriesRoute(e *echo.Echo) {
+	// all routes relates to categories of news and galleries comes here
+	e.GET("/categories", handlers.ListCategories)
+	e.GET("/categories/:category_id", handlers.DetailCategory)
+	e.POST("/categories", handlers.CreateCategory)
+	e.PUT("/categories/:category_id", handlers.UpdateCategory)
+	e.DELETE("/categories/:category_id", handlers.DeleteCategory)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"follooow-be/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func CollectionsRoute(e *echo.Echo) {
+	// all routes relates to collections of galleries and news comes here
+	e.GET("/collections", handlers.ListCollections)
+	e.GET("/collections/:collection_id", handlers.DetailCollection)
+	e.POST("/collections", handlers.CreateCollection)
+	e.PUT("/collections/:collection_id", handlers.UpdateCollection)
+	e.PUT("/collections/:collection_id/items", handlers.SetCollectionItems)
+	e.DELETE("/collections/:collection_id", handlers.DeleteCollection)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"follooow-be/handlers"
+	"follooow-be/middlewares"
 
 	"github.com/labstack/echo/v4"
 )
@@ -11,7 +12,20 @@ func GalleriesRoute(e *echo.Echo) {
 	e.GET("/galleries", handlers.ListGalleries)
 	e.GET("/galleries/:gallery_id", handlers.DetailGallery)
 	e.POST("/galleries", handlers.CreateGallery)
-	e.POST("/galleries/upload", handlers.CreateGalleryWithUpload)
+
+	// routes uploading images, user role must be allowed on galleries namespace
+	e.POST("/galleries/upload", handlers.CreateGalleryWithUpload, middlewares.UploadAuth("galleries"))
+	e.POST("/galleries/import", handlers.ImportGallery, middlewares.UploadAuth("galleries"))
+	e.GET("/galleries/import/:import_id", handlers.DetailGalleryImport)
+	e.GET("/galleries/:gallery_id/export", handlers.ExportGallery)
 	e.PUT("/galleries/:gallery_id", handlers.UpdateGallery)
-	e.PUT("/galleries/:gallery_id/upload", handlers.UpdateGalleryWithUpload)
+	e.PUT("/galleries/:gallery_id/upload", handlers.UpdateGalleryWithUpload, middlewares.UploadAuth("galleries"))
+
+	// single image operations
+	e.POST("/galleries/:gallery_id/images", handlers.AddGalleryImages, middlewares.UploadAuth("galleries"))
+	e.POST("/galleries/:gallery_id/images/media", handlers.AddGalleryMedia, middlewares.UploadAuth("galleries"))
+	e.PUT("/galleries/:gallery_id/images/order", handlers.ReorderGalleryImages)
+	e.PUT("/galleries/:gallery_id/images/:image_id", handlers.UpdateGalleryImage)
+	e.PUT("/galleries/:gallery_id/images/:image_id/cover", handlers.SetGalleryCover)
+	e.DELETE("/galleries/:gallery_id/images/:image_id", handlers.DeleteGalleryImage)
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"follooow-be/handlers"
+	"follooow-be/middlewares"
 
 	"github.com/labstack/echo/v4"
 )
@@ -13,4 +14,24 @@ func InfluencerRoute(e *echo.Echo) {
 	e.GET("/influencers/:influencer_id", handlers.DetailInfluencers)
 	e.PUT("/influencers/:influencer_id", handlers.UpdateInfluencer)
 	e.GET("/influencers/quick-find", handlers.QuickFindInfluencers)
+	e.GET("/influencers/:influencer_id/timeline", handlers.TimelineInfluencer)
+
+	// socials follower growth
+	e.GET("/influencers/socials/leaderboard", handlers.LeaderboardInfluencerSocials)
+	e.GET("/influencers/:influencer_id/socials/growth", handlers.GrowthInfluencerSocials)
+	// refresh fetches from social platforms, so it is only for admin
+	e.POST("/influencers/:influencer_id/socials/refresh", handlers.RefreshInfluencerSocials, middlewares.AdminAuth)
+
+	// best moments
+	e.GET("/influencers/:influencer_id/best-moments", handlers.ListBestMoments)
+	e.POST("/influencers/:influencer_id/best-moments", handlers.AddBestMoment)
+	e.PUT("/influencers/:influencer_id/best-moments/order", handlers.ReorderBestMoments)
+	e.PUT("/influencers/:influencer_id/best-moments/:moment_id", handlers.UpdateBestMoment)
+	e.DELETE("/influencers/:influencer_id/best-moments/:moment_id", handlers.DeleteBestMoment)
+
+	// relationships between influencers
+	e.GET("/influencers/:influencer_id/relationships", handlers.ListInfluencerRelationships)
+	e.POST("/influencers/:influencer_id/relationships", handlers.AddInfluencerRelationship)
+	e.PUT("/influencers/:influencer_id/relationships/:relationship_id", handlers.UpdateInfluencerRelationship)
+	e.DELETE("/influencers/:influencer_id/relationships/:relationship_id", handlers.DeleteInfluencerRelationship)
 }
@@ -2,12 +2,29 @@ package routes
 
 import (
 	"follooow-be/handlers"
+	"follooow-be/middlewares"
 
 	"github.com/labstack/echo/v4"
 )
 
 // MediaRoute defines all media-related routes
 func MediaRoute(e *echo.Echo) {
-	// Media upload route
-	e.POST("/api/media/upload", handlers.UploadMedia)
+	// Media upload route, directory must be allowed for the user role
+	e.POST("/api/media/upload", handlers.UploadMedia, middlewares.UserAuth)
+
+	// Chunked upload routes, to resume large upload after disconnect
+	uploads := e.Group("/api/media/uploads", middlewares.UserAuth)
+	uploads.POST("", handlers.CreateUploadSession)
+	uploads.GET("/:upload_id", handlers.DetailUploadSession)
+	uploads.PUT("/:upload_id/chunks/:index", handlers.UploadSessionChunk)
+	uploads.POST("/:upload_id/complete", handlers.CompleteUploadSession)
+	uploads.DELETE("/:upload_id", handlers.DeleteUploadSession)
+
+	// Media library routes, every upload is recorded to reuse it
+	// media can only be deleted by its uploader or admin
+	library := e.Group("/api/media", middlewares.UserAuth)
+	library.GET("", handlers.ListMedia)
+	library.GET("/:media_id", handlers.DetailMedia)
+	library.PUT("/:media_id", handlers.UpdateMedia)
+	library.DELETE("/:media_id", handlers.DeleteMedia)
 }
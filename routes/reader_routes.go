@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"follooow-be/handlers"
+	"follooow-be/middlewares"
+
+	"github.com/labstack/echo/v4"
+)
+
+func ReaderRoute(e *echo.Echo) {
+	// all routes relates to readers comes here
+	e.POST("/api/readers", handlers.CreateReader)
+	e.POST("/api/readers/login", handlers.LoginReader)
+
+	// routes of logged in reader
+	me := e.Group("/me", middlewares.ReaderAuth)
+	me.GET("", handlers.GetMe)
+	me.GET("/follows", handlers.ListFollows)
+	me.POST("/follows/:influencer_id", handlers.FollowInfluencer)
+	me.DELETE("/follows/:influencer_id", handlers.UnfollowInfluencer)
+	me.GET("/feed", handlers.ReaderFeed)
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"follooow-be/handlers"
+	"follooow-be/storage"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StorageRoute serves uploaded files when they are stored on local disk
+func StorageRoute(e *echo.Echo) {
+	if local, ok := storage.Default().(*storage.LocalStorage); ok {
+		e.GET(local.RoutePath()+"/_renditions/:rendition/*", handlers.LocalRendition)
+		e.Static(local.RoutePath(), local.Dir)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"follooow-be/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TagsRoute(e *echo.Echo) {
+	// all routes relates to tags comes here
+	e.GET("/tags", handlers.ListTags)
+	e.GET("/tags/:slug", handlers.DetailTag)
+	e.POST("/tags", handlers.CreateTag)
+	e.PUT("/tags/:slug", handlers.UpdateTag)
+	e.POST("/tags/:slug/merge", handlers.MergeTags)
+}
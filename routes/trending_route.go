@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"follooow-be/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TrendingRoute(e *echo.Echo) {
+	// all routes relates to trending news, galleries and influencers comes here
+	e.GET("/trending", handlers.ListTrending)
+}
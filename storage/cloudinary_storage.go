@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"follooow-be/configs"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/admin"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// CloudinaryStorage stores files on Cloudinary
+type CloudinaryStorage struct{}
+
+func NewCloudinaryStorage() *CloudinaryStorage {
+	// Initialize Cloudinary if not already done
+	if configs.CloudinaryClient == nil {
+		configs.InitCloudinary()
+	}
+
+	return &CloudinaryStorage{}
+}
+
+func (s *CloudinaryStorage) Upload(ctx context.Context, reader io.Reader, opts UploadOptions) (*Asset, error) {
+	return s.upload(ctx, reader, opts)
+}
+
+func (s *CloudinaryStorage) UploadURL(ctx context.Context, url string, opts UploadOptions) (*Asset, error) {
+	if url == "" {
+		return nil, fmt.Errorf("no image URL provided")
+	}
+
+	// Cloudinary downloads the url by itself
+	return s.upload(ctx, url, opts)
+}
+
+func (s *CloudinaryStorage) upload(ctx context.Context, file interface{}, opts UploadOptions) (*Asset, error) {
+	resourceType := resourceTypeOf(opts.ResourceType)
+	uploadParams := uploader.UploadParams{
+		Folder:       opts.Folder,
+		PublicID:     opts.Filename,
+		ResourceType: resourceType,
+	}
+	// colors are only extracted from images
+	if resourceType == ResourceImage {
+		uploadParams.Colors = api.Bool(true)
+	}
+
+	result, err := configs.CloudinaryClient.Upload.Upload(ctx, file, uploadParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload %s: %w", resourceType, err)
+	}
+	if result.Error.Message != "" {
+		return nil, fmt.Errorf("failed to upload %s: %s", resourceType, result.Error.Message)
+	}
+
+	asset := &Asset{
+		PublicID:      result.PublicID,
+		URL:           result.SecureURL,
+		Width:         result.Width,
+		Height:        result.Height,
+		Bytes:         result.Bytes,
+		Format:        result.Format,
+		DominantColor: uploadResultColor(result),
+		ResourceType:  resourceType,
+	}
+
+	if resourceType == ResourceVideo {
+		asset.Duration = uploadResultDuration(result)
+		asset.PosterURL = posterURL(result.SecureURL)
+	}
+
+	return asset, nil
+}
+
+func (s *CloudinaryStorage) Delete(ctx context.Context, publicID string, resourceType string) error {
+	if publicID == "" {
+		return fmt.Errorf("no public ID provided")
+	}
+
+	// Cloudinary public ids are unique per resource type only
+	resourceType = resourceTypeOf(resourceType)
+	result, err := configs.CloudinaryClient.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: resourceType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s: %w", resourceType, err)
+	}
+	if result.Error.Message != "" {
+		return fmt.Errorf("failed to delete %s: %s", resourceType, result.Error.Message)
+	}
+
+	return nil
+}
+
+// List pages uploaded images and videos of folder from Cloudinary admin api
+func (s *CloudinaryStorage) List(ctx context.Context, folder string, fn func(file StoredFile) error) error {
+	prefix := strings.Trim(folder, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	for _, resourceType := range []string{ResourceImage, ResourceVideo} {
+		cursor := ""
+		for {
+			result, err := configs.CloudinaryClient.Admin.Assets(ctx, admin.AssetsParams{
+				AssetType:    api.AssetType(resourceType),
+				DeliveryType: "upload",
+				Prefix:       prefix,
+				MaxResults:   500,
+				NextCursor:   cursor,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to list %s: %w", resourceType, err)
+			}
+			if result.Error.Message != "" {
+				return fmt.Errorf("failed to list %s: %s", resourceType, result.Error.Message)
+			}
+
+			for _, asset := range result.Assets {
+				err = fn(StoredFile{PublicID: asset.PublicID, ResourceType: resourceType, Bytes: asset.Bytes, CreatedAt: asset.CreatedAt})
+				if err != nil {
+					return err
+				}
+			}
+
+			if result.NextCursor == "" {
+				break
+			}
+			cursor = result.NextCursor
+		}
+	}
+
+	return nil
+}
+
+// Open downloads original file from Cloudinary delivery url
+func (s *CloudinaryStorage) Open(ctx context.Context, publicID string, resourceType string) (io.ReadCloser, error) {
+	if publicID == "" {
+		return nil, fmt.Errorf("no public ID provided")
+	}
+
+	file, err := configs.CloudinaryClient.Image(publicID)
+	if resourceTypeOf(resourceType) == ResourceVideo {
+		file, err = configs.CloudinaryClient.Video(publicID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	url, err := file.String()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	return resp.Body, nil
+}
+
+// Detail gets uploaded image from Cloudinary admin api, including its colors
+func (s *CloudinaryStorage) Detail(ctx context.Context, publicID string) (*Asset, error) {
+	if publicID == "" {
+		return nil, fmt.Errorf("no public ID provided")
+	}
+
+	result, err := configs.CloudinaryClient.Admin.Asset(ctx, admin.AssetParams{
+		PublicID: publicID,
+		Colors:   api.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image detail: %w", err)
+	}
+	if result.Error.Message != "" {
+		return nil, fmt.Errorf("failed to get image detail: %s", result.Error.Message)
+	}
+
+	return &Asset{
+		PublicID:      result.PublicID,
+		URL:           result.SecureURL,
+		Width:         result.Width,
+		Height:        result.Height,
+		Bytes:         result.Bytes,
+		Format:        result.Format,
+		DominantColor: dominantColor(result.Colors),
+	}, nil
+}
+
+func (s *CloudinaryStorage) PublicURL(publicID string) string {
+	image, err := configs.CloudinaryClient.Image(publicID)
+	if err != nil {
+		return ""
+	}
+
+	url, err := image.String()
+	if err != nil {
+		return ""
+	}
+	return url
+}
+
+// PublicIDFromURL extracts public ID from Cloudinary URL
+func (s *CloudinaryStorage) PublicIDFromURL(url string) string {
+	if !strings.Contains(url, "res.cloudinary.com") {
+		return ""
+	}
+
+	// Example URL: https://res.cloudinary.com/cloud_name/image/upload/v1234567890/folder/image_name.jpg
+	parts := strings.Split(url, "/")
+
+	// Find folder and image parts
+	for i, part := range parts {
+		if part == "upload" && i+2 < len(parts) {
+			// Skip version part (v1234567890)
+			publicID := strings.Join(parts[i+2:], "/")
+			// Remove file extension for deletion
+			if dotIndex := strings.LastIndex(publicID, "."); dotIndex != -1 {
+				publicID = publicID[:dotIndex]
+			}
+			return publicID
+		}
+	}
+
+	return ""
+}
+
+// RenditionURL adds Cloudinary transformation to uploaded image url
+// ex: .../image/upload/v1/a.jpg -> .../image/upload/c_lfill,g_auto,w_200,h_200,q_auto/f_webp/v1/a.jpg
+// rendition of uploaded video is its poster frame, format is set by the extension
+// ex: .../video/upload/v1/a.mp4 -> .../video/upload/so_auto/c_lfill,g_auto,w_200,h_200,q_auto/v1/a.jpg
+func (s *CloudinaryStorage) RenditionURL(url string, rendition Rendition, format string) string {
+	if !strings.Contains(url, "res.cloudinary.com") {
+		return ""
+	}
+
+	transformation := fmt.Sprintf("c_limit,w_%d,h_%d,q_auto", rendition.Width, rendition.Height)
+	if rendition.Crop {
+		transformation = fmt.Sprintf("c_lfill,g_auto,w_%d,h_%d,q_auto", rendition.Width, rendition.Height)
+	}
+
+	if index := strings.Index(url, "/video/upload/"); index >= 0 {
+		if format == "" {
+			format = "jpg"
+		}
+		prefix := url[:index+len("/video/upload/")]
+		return prefix + "so_auto/" + transformation + "/" + replaceExtension(url[len(prefix):], format)
+	}
+
+	index := strings.Index(url, "/image/upload/")
+	if index < 0 {
+		return ""
+	}
+	if format != "" {
+		transformation += "/f_" + format
+	}
+
+	prefix := url[:index+len("/image/upload/")]
+	return prefix + transformation + "/" + url[len(prefix):]
+}
+
+// VideoURL changes extension of uploaded video url, Cloudinary transcodes the video to that format
+// ex: .../video/upload/v1/a.mov -> .../video/upload/q_auto/v1/a.mp4
+func (s *CloudinaryStorage) VideoURL(url string, format string) string {
+	index := strings.Index(url, "/video/upload/")
+	if index < 0 || !strings.Contains(url, "res.cloudinary.com") || format == "" {
+		return ""
+	}
+
+	prefix := url[:index+len("/video/upload/")]
+	return prefix + "q_auto/" + replaceExtension(url[len(prefix):], format)
+}
+
+// posterURL gets frame of uploaded video as jpeg, Cloudinary picks the frame
+// ex: .../video/upload/v1/a.mp4 -> .../video/upload/so_auto/v1/a.jpg
+func posterURL(url string) string {
+	index := strings.Index(url, "/video/upload/")
+	if index < 0 {
+		return ""
+	}
+
+	prefix := url[:index+len("/video/upload/")]
+	return prefix + "so_auto/" + replaceExtension(url[len(prefix):], "jpg")
+}
+
+func replaceExtension(url string, format string) string {
+	if dot := strings.LastIndex(url, "."); dot > strings.LastIndex(url, "/") {
+		url = url[:dot]
+	}
+	return url + "." + format
+}
+
+// resourceTypeOf gets Cloudinary resource type, image when empty
+func resourceTypeOf(resourceType string) string {
+	if resourceType == ResourceVideo {
+		return ResourceVideo
+	}
+	return ResourceImage
+}
+
+// uploadResultResponse gets raw response of upload, it has fields not mapped by UploadResult
+// the SDK stores json object of the response as *map[string]interface{}
+func uploadResultResponse(result *uploader.UploadResult) map[string]interface{} {
+	response, ok := result.Response.(*map[string]interface{})
+	if !ok || response == nil {
+		return nil
+	}
+
+	return *response
+}
+
+// uploadResultColor gets dominant colour of upload result, ex: #F4E2D0
+// colors only available when uploaded with Colors param
+func uploadResultColor(result *uploader.UploadResult) string {
+	colors, _ := uploadResultResponse(result)["colors"].([]interface{})
+	var normalized [][]interface{}
+	for _, color := range colors {
+		if pair, ok := color.([]interface{}); ok {
+			normalized = append(normalized, pair)
+		}
+	}
+
+	return dominantColor(normalized)
+}
+
+// uploadResultDuration gets duration in seconds of uploaded video, 0 when not available
+func uploadResultDuration(result *uploader.UploadResult) float64 {
+	duration, _ := uploadResultResponse(result)["duration"].(float64)
+	return duration
+}
+
+// dominantColor gets the first colour of Cloudinary colors, which is sorted by its percentage
+// ex: [["#F4E2D0", 45.2], ["#1A1A1A", 20.1]] -> #F4E2D0
+func dominantColor(colors [][]interface{}) string {
+	if len(colors) < 1 || len(colors[0]) < 1 {
+		return ""
+	}
+
+	color, _ := colors[0][0].(string)
+	return color
+}
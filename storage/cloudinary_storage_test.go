@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// uploadResult decodes body the same way the SDK does, including its raw Response
+func uploadResult(t *testing.T, body string) *uploader.UploadResult {
+	t.Helper()
+
+	var result uploader.UploadResult
+	if err := json.Unmarshal([]byte(body), &result); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.HandleRawResponse([]byte(body), &result); err != nil {
+		t.Fatal(err)
+	}
+	return &result
+}
+
+func TestUploadResultColor(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"with colors", `{"public_id":"a","colors":[["#F4E2D0",45.2],["#1A1A1A",20.1]]}`, "#F4E2D0"},
+		{"without colors", `{"public_id":"a"}`, ""},
+		{"empty colors", `{"public_id":"a","colors":[]}`, ""},
+		{"invalid colors", `{"public_id":"a","colors":"#F4E2D0"}`, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := uploadResultColor(uploadResult(t, test.body)); got != test.want {
+				t.Errorf("uploadResultColor() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUploadResultDuration(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want float64
+	}{
+		{"video", `{"public_id":"a","resource_type":"video","duration":12.48}`, 12.48},
+		{"without duration", `{"public_id":"a","resource_type":"video"}`, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := uploadResultDuration(uploadResult(t, test.body)); got != test.want {
+				t.Errorf("uploadResultDuration() = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestUploadResultWithoutResponse(t *testing.T) {
+	result := &uploader.UploadResult{}
+	if got := uploadResultColor(result); got != "" {
+		t.Errorf("uploadResultColor() = %q, want empty", got)
+	}
+	if got := uploadResultDuration(result); got != 0 {
+		t.Errorf("uploadResultDuration() = %v, want 0", got)
+	}
+}
+
+func TestCloudinaryRenditionURL(t *testing.T) {
+	thumb, _ := FindRendition("thumb")
+	full, _ := FindRendition("full")
+	tests := []struct {
+		name      string
+		url       string
+		rendition Rendition
+		format    string
+		want      string
+	}{
+		{"image", "https://res.cloudinary.com/demo/image/upload/v1/a.jpg", thumb, "", "https://res.cloudinary.com/demo/image/upload/c_lfill,g_auto,w_200,h_200,q_auto/v1/a.jpg"},
+		{"image webp", "https://res.cloudinary.com/demo/image/upload/v1/a.jpg", full, "webp", "https://res.cloudinary.com/demo/image/upload/c_limit,w_1600,h_1600,q_auto/f_webp/v1/a.jpg"},
+		{"video poster", "https://res.cloudinary.com/demo/video/upload/v1/clips/a.mp4", thumb, "", "https://res.cloudinary.com/demo/video/upload/so_auto/c_lfill,g_auto,w_200,h_200,q_auto/v1/clips/a.jpg"},
+		{"video poster avif", "https://res.cloudinary.com/demo/video/upload/v1/a.mov", full, "avif", "https://res.cloudinary.com/demo/video/upload/so_auto/c_limit,w_1600,h_1600,q_auto/v1/a.avif"},
+		{"external", "https://example.com/image/upload/a.jpg", thumb, "", ""},
+		{"not uploaded", "https://res.cloudinary.com/demo/image/fetch/a.jpg", thumb, "", ""},
+	}
+
+	store := &CloudinaryStorage{}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := store.RenditionURL(test.url, test.rendition, test.format); got != test.want {
+				t.Errorf("RenditionURL() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
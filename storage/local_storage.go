@@ -0,0 +1,396 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// folder of resized images cache inside Dir
+const renditionsFolder = "_renditions"
+
+// folder of video poster frames inside Dir
+const postersFolder = "_posters"
+
+// extension of sniffed content type, used when filename has no extension
+var localExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"video/mp4":  ".mp4",
+	"video/webm": ".webm",
+}
+
+// extension of stored videos, other files are listed as image
+var localVideoExtensions = map[string]bool{
+	".mp4":  true,
+	".webm": true,
+	".mov":  true,
+}
+
+// LocalStorage stores files on local disk, served by static route of BaseURL
+// public id is path of the file relative to Dir, ex: follooow/galleries/photo_1700000000.jpg
+// video posters are extracted by FFmpeg, videos have no poster when it is not installed
+type LocalStorage struct {
+	Dir     string
+	BaseURL string
+	FFmpeg  string
+}
+
+// NewLocalStorage creates local storage, by default files are stored on ./uploads and served on /uploads
+func NewLocalStorage(dir string, baseURL string) *LocalStorage {
+	if dir == "" {
+		dir = "uploads"
+	}
+	if baseURL == "" {
+		baseURL = "/uploads"
+	}
+
+	return &LocalStorage{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/"), FFmpeg: "ffmpeg"}
+}
+
+func (s *LocalStorage) Upload(ctx context.Context, reader io.Reader, opts UploadOptions) (*Asset, error) {
+	buffered := bufio.NewReader(reader)
+
+	// sniff content type to give the file an extension
+	head, _ := buffered.Peek(512)
+	filename := cleanPath(opts.Filename)
+	if filename == "" {
+		return nil, fmt.Errorf("no filename provided")
+	}
+	if path.Ext(filename) == "" {
+		filename += localExtensions[http.DetectContentType(head)]
+	}
+
+	publicID := path.Join(cleanPath(opts.Folder), filename)
+	fullPath := filepath.Join(s.Dir, filepath.FromSlash(publicID))
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	file, err := os.Create(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, &contextReader{ctx: ctx, reader: buffered}); err != nil {
+		os.Remove(fullPath)
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	asset, err := s.Detail(ctx, publicID)
+	if err != nil || opts.ResourceType != ResourceVideo {
+		return asset, err
+	}
+
+	asset.ResourceType = ResourceVideo
+	asset.PosterURL = s.extractPoster(ctx, publicID)
+	return asset, nil
+}
+
+// extractPoster saves a representative frame of video as jpeg, empty when FFmpeg is not available
+func (s *LocalStorage) extractPoster(ctx context.Context, publicID string) string {
+	if s.FFmpeg == "" {
+		return ""
+	}
+	ffmpeg, err := exec.LookPath(s.FFmpeg)
+	if err != nil {
+		return ""
+	}
+
+	posterID := path.Join(postersFolder, publicID+".jpg")
+	posterPath := filepath.Join(s.Dir, filepath.FromSlash(posterID))
+	if err = os.MkdirAll(filepath.Dir(posterPath), 0755); err != nil {
+		return ""
+	}
+
+	// thumbnail filter picks the most representative of the first frames
+	cmd := exec.CommandContext(ctx, ffmpeg, "-loglevel", "error", "-y", "-i", filepath.Join(s.Dir, filepath.FromSlash(publicID)), "-vf", "thumbnail", "-frames:v", "1", posterPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		fmt.Printf("Failed to extract poster of %s: %v %s\n", publicID, err, output)
+		os.Remove(posterPath)
+		return ""
+	}
+
+	return s.PublicURL(posterID)
+}
+
+// UploadURL downloads url then stores it as local file
+func (s *LocalStorage) UploadURL(ctx context.Context, url string, opts UploadOptions) (*Asset, error) {
+	if url == "" {
+		return nil, fmt.Errorf("no image URL provided")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+
+	return s.Upload(ctx, resp.Body, opts)
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, publicID string, resourceType string) error {
+	publicID = cleanPath(publicID)
+	if publicID == "" {
+		return fmt.Errorf("no public ID provided")
+	}
+
+	if err := os.Remove(filepath.Join(s.Dir, filepath.FromSlash(publicID))); err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	s.removeRenditions(publicID)
+
+	if resourceType == ResourceVideo {
+		posterID := path.Join(postersFolder, publicID+".jpg")
+		os.Remove(filepath.Join(s.Dir, filepath.FromSlash(posterID)))
+		s.removeRenditions(posterID)
+	}
+	return nil
+}
+
+// removeRenditions removes cached renditions of stored file, renditions which are not cached yet are ignored
+func (s *LocalStorage) removeRenditions(publicID string) {
+	for _, rendition := range Renditions {
+		os.Remove(filepath.Join(s.Dir, renditionsFolder, rendition.Name, filepath.FromSlash(publicID)))
+	}
+}
+
+// List walks files of folder, renditions cache and video posters are not listed
+func (s *LocalStorage) List(ctx context.Context, folder string, fn func(file StoredFile) error) error {
+	root := filepath.Join(s.Dir, filepath.FromSlash(cleanPath(folder)))
+
+	err := filepath.WalkDir(root, func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		relative, err := filepath.Rel(s.Dir, fullPath)
+		if err != nil {
+			return err
+		}
+		publicID := filepath.ToSlash(relative)
+
+		if entry.IsDir() {
+			if publicID == renditionsFolder || publicID == postersFolder {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		resourceType := ResourceImage
+		if localVideoExtensions[strings.ToLower(path.Ext(publicID))] {
+			resourceType = ResourceVideo
+		}
+
+		return fn(StoredFile{PublicID: publicID, ResourceType: resourceType, Bytes: int(info.Size()), CreatedAt: info.ModTime()})
+	})
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Open opens stored file, reading stops when context is done
+func (s *LocalStorage) Open(ctx context.Context, publicID string, resourceType string) (io.ReadCloser, error) {
+	publicID = cleanPath(publicID)
+	if publicID == "" {
+		return nil, fmt.Errorf("no public ID provided")
+	}
+
+	file, err := os.Open(filepath.Join(s.Dir, filepath.FromSlash(publicID)))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{&contextReader{ctx: ctx, reader: file}, file}, nil
+}
+
+// Detail reads size and dimensions of stored file, dimensions are empty when format is not decodable
+func (s *LocalStorage) Detail(ctx context.Context, publicID string) (*Asset, error) {
+	publicID = cleanPath(publicID)
+	if publicID == "" {
+		return nil, fmt.Errorf("no public ID provided")
+	}
+
+	file, err := os.Open(filepath.Join(s.Dir, filepath.FromSlash(publicID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image detail: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image detail: %w", err)
+	}
+
+	asset := &Asset{
+		PublicID: publicID,
+		URL:      s.PublicURL(publicID),
+		Bytes:    int(info.Size()),
+		Format:   strings.TrimPrefix(path.Ext(publicID), "."),
+	}
+
+	if config, format, err := image.DecodeConfig(file); err == nil {
+		asset.Width = config.Width
+		asset.Height = config.Height
+		asset.Format = format
+	}
+
+	return asset, nil
+}
+
+func (s *LocalStorage) PublicURL(publicID string) string {
+	return s.BaseURL + "/" + cleanPath(publicID)
+}
+
+func (s *LocalStorage) PublicIDFromURL(url string) string {
+	if !strings.HasPrefix(url, s.BaseURL+"/") {
+		return ""
+	}
+	return cleanPath(strings.TrimPrefix(url, s.BaseURL+"/"))
+}
+
+// RenditionURL gets url of resized image, resized on first request then cached
+// local storage can't encode webp and avif, so only original format is supported
+// rendition of video is resized from its poster extracted on upload
+func (s *LocalStorage) RenditionURL(url string, rendition Rendition, format string) string {
+	publicID := s.PublicIDFromURL(url)
+	if publicID == "" || format != "" {
+		return ""
+	}
+	if localVideoExtensions[strings.ToLower(path.Ext(publicID))] {
+		publicID = path.Join(postersFolder, publicID+".jpg")
+	}
+	return s.BaseURL + "/" + renditionsFolder + "/" + rendition.Name + "/" + publicID
+}
+
+// VideoURL gets url of stored video, local storage can't transcode so only original format is supported
+func (s *LocalStorage) VideoURL(url string, format string) string {
+	publicID := s.PublicIDFromURL(url)
+	if publicID == "" || !strings.EqualFold(path.Ext(publicID), "."+format) {
+		return ""
+	}
+	return url
+}
+
+// Rendition gets path of resized image, the image is resized when it is not cached yet
+// cache is stored inside Dir, so it is removed together with uploaded files
+func (s *LocalStorage) Rendition(name string, publicID string) (string, error) {
+	rendition, ok := FindRendition(name)
+	publicID = cleanPath(publicID)
+	if !ok || publicID == "" || strings.HasPrefix(publicID, renditionsFolder+"/") {
+		return "", ErrNotFound
+	}
+
+	cachePath := filepath.Join(s.Dir, renditionsFolder, rendition.Name, filepath.FromSlash(publicID))
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath, nil
+	}
+
+	source, err := os.Open(filepath.Join(s.Dir, filepath.FromSlash(publicID)))
+	if os.IsNotExist(err) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	img, format, err := image.Decode(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return "", err
+	}
+
+	// write to temporary file first, so concurrent request never read half written file
+	temp, err := os.CreateTemp(filepath.Dir(cachePath), ".rendition-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(temp.Name())
+
+	resized := resizeImage(img, rendition)
+	if format == "jpeg" {
+		err = jpeg.Encode(temp, resized, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(temp, resized)
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return cachePath, os.Rename(temp.Name(), cachePath)
+}
+
+// cleanPath keeps path inside storage dir, ex: /../a//b -> a/b
+func cleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+}
+
+// contextReader stops reading when context is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+// RoutePath gets path of BaseURL to serve the files, ex: http://localhost:20223/uploads -> /uploads
+func (s *LocalStorage) RoutePath() string {
+	parsed, err := neturl.Parse(s.BaseURL)
+	if err != nil || parsed.Path == "" {
+		return "/uploads"
+	}
+	return parsed.Path
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalRenditionURL(t *testing.T) {
+	thumb, _ := FindRendition("thumb")
+	tests := []struct {
+		name   string
+		url    string
+		format string
+		want   string
+	}{
+		{"image", "/uploads/follooow/a.jpg", "", "/uploads/_renditions/thumb/follooow/a.jpg"},
+		{"video poster", "/uploads/follooow/a.mp4", "", "/uploads/_renditions/thumb/_posters/follooow/a.mp4.jpg"},
+		{"video poster uppercase", "/uploads/follooow/a.MOV", "", "/uploads/_renditions/thumb/_posters/follooow/a.MOV.jpg"},
+		{"webp", "/uploads/follooow/a.jpg", "webp", ""},
+		{"external", "https://example.com/a.jpg", "", ""},
+	}
+
+	store := NewLocalStorage("", "")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := store.RenditionURL(test.url, thumb, test.format); got != test.want {
+				t.Errorf("RenditionURL() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// writeTestFile writes content to path inside dir, folders are created
+func writeTestFile(t *testing.T, dir string, name string, content []byte) string {
+	t.Helper()
+
+	fullPath := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fullPath
+}
+
+func TestLocalDeleteRemovesRenditions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 8, 8))); err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewLocalStorage(t.TempDir(), "/uploads")
+	original := writeTestFile(t, store.Dir, "follooow/a.png", buf.Bytes())
+
+	var cached []string
+	for _, rendition := range Renditions {
+		cachePath, err := store.Rendition(rendition.Name, "follooow/a.png")
+		if err != nil {
+			t.Fatalf("Rendition(%s) error = %v", rendition.Name, err)
+		}
+		cached = append(cached, cachePath)
+	}
+
+	if err := store.Delete(context.Background(), "follooow/a.png", ResourceImage); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	for _, name := range append(cached, original) {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s is not removed", name)
+		}
+	}
+}
+
+func TestLocalDeleteRemovesVideoPosterRenditions(t *testing.T) {
+	store := NewLocalStorage(t.TempDir(), "/uploads")
+	files := []string{
+		writeTestFile(t, store.Dir, "follooow/a.mp4", []byte("video")),
+		writeTestFile(t, store.Dir, "_posters/follooow/a.mp4.jpg", []byte("poster")),
+		writeTestFile(t, store.Dir, "_renditions/thumb/_posters/follooow/a.mp4.jpg", []byte("thumb")),
+		writeTestFile(t, store.Dir, "_renditions/card/_posters/follooow/a.mp4.jpg", []byte("card")),
+	}
+	other := writeTestFile(t, store.Dir, "_renditions/thumb/_posters/follooow/b.mp4.jpg", []byte("other"))
+
+	if err := store.Delete(context.Background(), "follooow/a.mp4", ResourceVideo); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	for _, name := range files {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Errorf("%s is not removed", name)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("rendition of other video is removed: %v", err)
+	}
+}
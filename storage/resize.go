@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"image"
+	"image/color"
+)
+
+// resizeImage resizes image by area average, cropped rendition is cut from the center
+func resizeImage(img image.Image, rendition Rendition) image.Image {
+	bounds := img.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW < 1 || srcH < 1 {
+		return img
+	}
+
+	dstW, dstH := rendition.Size(srcW, srcH)
+
+	// cropped rendition uses the widest center area with rendition aspect ratio
+	area := bounds
+	if rendition.Crop {
+		cropW, cropH := srcW, srcW*rendition.Height/rendition.Width
+		if cropH > srcH {
+			cropW, cropH = srcH*rendition.Width/rendition.Height, srcH
+		}
+		x0 := bounds.Min.X + (srcW-cropW)/2
+		y0 := bounds.Min.Y + (srcH-cropH)/2
+		area = image.Rect(x0, y0, x0+atLeastOne(cropW), y0+atLeastOne(cropH))
+	}
+
+	areaW, areaH := area.Dx(), area.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+
+	for y := 0; y < dstH; y++ {
+		sy0, sy1 := area.Min.Y+y*areaH/dstH, area.Min.Y+(y+1)*areaH/dstH
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < dstW; x++ {
+			sx0, sx1 := area.Min.X+x*areaW/dstW, area.Min.X+(x+1)*areaW/dstW
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			// at most 4x4 samples of each pixel area
+			stepX, stepY := (sx1-sx0+3)/4, (sy1-sy0+3)/4
+			var r, g, b, a, count uint64
+			for sy := sy0; sy < sy1; sy += stepY {
+				for sx := sx0; sx < sx1; sx += stepX {
+					c := color.NRGBA64Model.Convert(img.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					a += uint64(c.A)
+					count++
+				}
+			}
+
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / count >> 8),
+				G: uint8(g / count >> 8),
+				B: uint8(b / count >> 8),
+				A: uint8(a / count >> 8),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"follooow-be/configs"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BackendCloudinary = "cloudinary"
+	BackendLocal      = "local"
+)
+
+// resource type of stored file
+const (
+	ResourceImage = "image"
+	ResourceVideo = "video"
+)
+
+var ErrNotFound = errors.New("file not found")
+
+// Asset is uploaded file, the same on every backend
+type Asset struct {
+	PublicID      string
+	URL           string
+	Width         int
+	Height        int
+	Bytes         int
+	Format        string
+	DominantColor string
+	// image or video, image when empty
+	ResourceType string
+	// duration of video in seconds
+	Duration float64
+	// url of video poster frame, empty when the backend can't extract it
+	PosterURL string
+	// perceptual hash of uploaded image, not stored by the backend
+	Hash string
+}
+
+// StoredFile is file listed from the storage
+type StoredFile struct {
+	PublicID     string
+	ResourceType string
+	Bytes        int
+	CreatedAt    time.Time
+}
+
+// UploadOptions is destination of uploaded file
+// Filename is used as public id inside Folder, generated by caller to keep it unique
+type UploadOptions struct {
+	Folder       string
+	Filename     string
+	ResourceType string
+}
+
+// Storage stores uploaded files
+type Storage interface {
+	// Upload stores file of reader
+	Upload(ctx context.Context, reader io.Reader, opts UploadOptions) (*Asset, error)
+	// UploadURL stores file downloaded from url
+	UploadURL(ctx context.Context, url string, opts UploadOptions) (*Asset, error)
+	// Delete removes stored file by its public id and resource type
+	Delete(ctx context.Context, publicID string, resourceType string) error
+	// List calls fn for every stored file inside folder, listing stops when fn returns error
+	List(ctx context.Context, folder string, fn func(file StoredFile) error) error
+	// Open reads content of stored file by its public id and resource type, ErrNotFound when it is missing
+	Open(ctx context.Context, publicID string, resourceType string) (io.ReadCloser, error)
+	// Detail gets stored file by its public id
+	Detail(ctx context.Context, publicID string) (*Asset, error)
+	// PublicURL gets url to serve stored file
+	PublicURL(publicID string) string
+	// PublicIDFromURL gets public id of url returned by this storage, empty when url is not stored here
+	PublicIDFromURL(url string) string
+	// RenditionURL gets url of resized image on format, empty when url is not stored here or format is not supported
+	// empty format keeps the original format, rendition of video url is resized from its poster frame as jpeg
+	RenditionURL(url string, rendition Rendition, format string) string
+	// VideoURL gets url to play uploaded video on format, empty when url is not stored here or format is not supported
+	VideoURL(url string, format string) string
+}
+
+// Rendition is named size of image
+// cropped rendition fills the size, otherwise image is fit inside the size
+// images are never upscaled
+type Rendition struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// renditions returned on api responses
+var Renditions = []Rendition{
+	{Name: "thumb", Width: 200, Height: 200, Crop: true},
+	{Name: "card", Width: 600, Height: 400, Crop: true},
+	{Name: "full", Width: 1600, Height: 1600},
+	{Name: "og-image", Width: 1200, Height: 630, Crop: true},
+}
+
+// Size gets size of rendition of image, rendition size is returned when image size is unknown
+func (r Rendition) Size(width int, height int) (int, int) {
+	if width < 1 || height < 1 {
+		return r.Width, r.Height
+	}
+
+	if r.Crop {
+		// never upscale, smaller image keeps rendition aspect ratio
+		if width < r.Width || height < r.Height {
+			cropW, cropH := width, width*r.Height/r.Width
+			if cropH > height {
+				cropW, cropH = height*r.Width/r.Height, height
+			}
+			return atLeastOne(cropW), atLeastOne(cropH)
+		}
+		return r.Width, r.Height
+	}
+
+	// fit inside rendition size
+	if width > r.Width {
+		width, height = r.Width, height*r.Width/width
+	}
+	if height > r.Height {
+		width, height = width*r.Height/height, r.Height
+	}
+	return atLeastOne(width), atLeastOne(height)
+}
+
+func atLeastOne(value int) int {
+	if value < 1 {
+		return 1
+	}
+	return value
+}
+
+// modern formats of renditions, served when browser supports them
+var RenditionFormats = []string{"webp", "avif"}
+
+// formats of video playback urls, mp4 is played by every browser
+var VideoFormats = []string{"mp4", "webm"}
+
+// FindRendition gets rendition by its name
+func FindRendition(name string) (Rendition, bool) {
+	for _, rendition := range Renditions {
+		if rendition.Name == name {
+			return rendition, true
+		}
+	}
+	return Rendition{}, false
+}
+
+var (
+	defaultStorage Storage
+	defaultOnce    sync.Once
+)
+
+// Default gets storage selected by STORAGE_BACKEND, Cloudinary by default
+func Default() Storage {
+	defaultOnce.Do(func() {
+		backend := configs.EnvStorageBackend()
+
+		var err error
+		defaultStorage, err = New(backend)
+		if err != nil {
+			log.Fatal(err)
+		}
+	})
+
+	return defaultStorage
+}
+
+// New creates storage of backend
+func New(backend string) (Storage, error) {
+	switch backend {
+	case "", BackendCloudinary:
+		return NewCloudinaryStorage(), nil
+	case BackendLocal:
+		local := NewLocalStorage(configs.EnvStorageLocalDir(), configs.EnvStorageLocalURL())
+		if ffmpeg := configs.EnvFFmpegPath(); ffmpeg != "" {
+			local.FFmpeg = ffmpeg
+		}
+		return local, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// DecodeBase64 decodes base64 encoded file, data url prefix is allowed
+// ex: data:image/png;base64,iVBORw0KGgo...
+func DecodeBase64(data string) ([]byte, error) {
+	if data == "" {
+		return nil, fmt.Errorf("no base64 data provided")
+	}
+
+	// Remove data URL prefix if present
+	if strings.HasPrefix(data, "data:") {
+		parts := strings.SplitN(data, ",", 2)
+		if len(parts) == 2 {
+			data = parts[1]
+		}
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type cacheItem struct {
+	value     interface{}
+	expiredAt time.Time
+}
+
+// Cache is in-memory key value store with expiration, safe for concurrent use
+type Cache struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	items map[string]cacheItem
+	// next time expired items never read again are swept
+	sweepAt time.Time
+}
+
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{ttl: ttl, items: map[string]cacheItem{}, sweepAt: time.Now().Add(ttl)}
+}
+
+// Get returns cached value, ok is false if key not found or expired
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(item.expiredAt) {
+		delete(c.items, key)
+		return nil, false
+	}
+
+	return item.value, true
+}
+
+// Set stores value, expired items are removed by Get
+// items never read again are swept at most once per ttl, so Set doesn't scan the cache every time
+func (c *Cache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.After(c.sweepAt) {
+		for existingKey, item := range c.items {
+			if now.After(item.expiredAt) {
+				delete(c.items, existingKey)
+			}
+		}
+		c.sweepAt = now.Add(c.ttl)
+	}
+
+	c.items[key] = cacheItem{value: value, expiredAt: now.Add(c.ttl)}
+}
+
+// DeletePrefix removes all keys started with prefix
+func (c *Cache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.items, key)
+		}
+	}
+}
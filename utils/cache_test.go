@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCacheGet(t *testing.T) {
+	cache := NewCache(time.Hour)
+	cache.Set("feed:1", "a")
+
+	if value, ok := cache.Get("feed:1"); !ok || value != "a" {
+		t.Errorf("Get() = %v, %v, want a, true", value, ok)
+	}
+	if _, ok := cache.Get("feed:2"); ok {
+		t.Error("Get() of missing key is ok")
+	}
+
+	// expired item is removed on read
+	cache.items["feed:1"] = cacheItem{value: "a", expiredAt: time.Now().Add(-time.Second)}
+	if _, ok := cache.Get("feed:1"); ok {
+		t.Error("Get() of expired key is ok")
+	}
+	if _, ok := cache.items["feed:1"]; ok {
+		t.Error("expired key is kept after Get()")
+	}
+}
+
+func TestCacheSetSweep(t *testing.T) {
+	cache := NewCache(time.Hour)
+	expired := time.Now().Add(-time.Second)
+	cache.items["feed:1"] = cacheItem{value: "a", expiredAt: expired}
+
+	// expired item is kept until the next sweep
+	cache.Set("feed:2", "b")
+	if _, ok := cache.items["feed:1"]; !ok {
+		t.Error("expired key is swept before sweep time")
+	}
+
+	cache.sweepAt = expired
+	cache.Set("feed:3", "c")
+	if _, ok := cache.items["feed:1"]; ok {
+		t.Error("expired key is kept after sweep")
+	}
+	if len(cache.items) != 2 {
+		t.Errorf("cache has %d items, want 2", len(cache.items))
+	}
+	if !cache.sweepAt.After(time.Now()) {
+		t.Errorf("next sweep %v is not scheduled", cache.sweepAt)
+	}
+}
+
+func TestCacheDeletePrefix(t *testing.T) {
+	cache := NewCache(time.Hour)
+	cache.Set("feed:1", "a")
+	cache.Set("feed:2", "b")
+	cache.Set("counts", "c")
+
+	cache.DeletePrefix("feed:")
+	if len(cache.items) != 1 {
+		t.Errorf("cache has %d items, want 1", len(cache.items))
+	}
+	if _, ok := cache.Get("counts"); !ok {
+		t.Error("key without prefix is deleted")
+	}
+}
@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"follooow-be/configs"
+)
+
+const (
+	defaultUploadChunkSize  = 5 << 20
+	minUploadChunkSize      = 256 << 10
+	maxUploadChunkSize      = 20 << 20
+	defaultUploadSessionTTL = 24 * time.Hour
+)
+
+// extension of assembled file of upload session
+const uploadSessionExtension = ".part"
+
+var (
+	ErrChunkChecksum  = errors.New("chunk checksum does not match")
+	ErrChunkIndex     = errors.New("invalid chunk index")
+	ErrChunkSize      = errors.New("chunk size does not match")
+	ErrUploadChecksum = errors.New("file checksum does not match")
+)
+
+// UploadSessionDir gets folder of chunked uploads, default follooow-uploads on system temp folder
+func UploadSessionDir() string {
+	if dir := configs.EnvUploadSessionDir(); dir != "" {
+		return dir
+	}
+	return filepath.Join(os.TempDir(), "follooow-uploads")
+}
+
+// UploadSessionTTL gets time an upload session is kept after its last chunk, default 24h
+func UploadSessionTTL() time.Duration {
+	return parseUploadDuration(configs.EnvUploadSessionTTL(), defaultUploadSessionTTL)
+}
+
+// UploadChunkSize gets chunk size of new upload session, requested size is used when it is between 256KB and 20MB
+func UploadChunkSize(requested int64) int64 {
+	if requested >= minUploadChunkSize && requested <= maxUploadChunkSize {
+		return requested
+	}
+
+	size := envInt64(configs.EnvUploadChunkSize(), defaultUploadChunkSize)
+	if size < minUploadChunkSize || size > maxUploadChunkSize {
+		return defaultUploadChunkSize
+	}
+	return size
+}
+
+// UploadMaxBytes gets max size of any uploaded file, the larger of image and video limit
+func UploadMaxBytes() int64 {
+	if video := VideoMaxBytes(); video > ImageMaxBytes() {
+		return video
+	}
+	return ImageMaxBytes()
+}
+
+// UploadChunkRange gets offset and size of chunk on the file, every chunk has chunk size except the last one
+func UploadChunkRange(index int, chunkSize int64, size int64) (int64, int64, error) {
+	if index < 0 || chunkSize <= 0 {
+		return 0, 0, ErrChunkIndex
+	}
+
+	offset := int64(index) * chunkSize
+	if offset >= size {
+		return 0, 0, ErrChunkIndex
+	}
+	if offset+chunkSize > size {
+		return offset, size - offset, nil
+	}
+	return offset, chunkSize, nil
+}
+
+// CreateUploadFile creates empty file of upload session, chunks are written on their offset
+func CreateUploadFile(sessionId string, size int64) error {
+	if err := os.MkdirAll(UploadSessionDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create upload folder: %w", err)
+	}
+
+	file, err := os.Create(uploadFilePath(sessionId))
+	if err != nil {
+		return fmt.Errorf("failed to create upload file: %w", err)
+	}
+	defer file.Close()
+
+	return file.Truncate(size)
+}
+
+// WriteUploadChunk verifies sha256 of chunk then writes it on its offset
+// chunk may be written again, ex: client retries after connection lost
+func WriteUploadChunk(sessionId string, offset int64, data []byte, checksum string) error {
+	return writeChunk(uploadFilePath(sessionId), offset, data, checksum)
+}
+
+func writeChunk(name string, offset int64, data []byte, checksum string) error {
+	sum := sha256.Sum256(data)
+	if !strings.EqualFold(hex.EncodeToString(sum[:]), checksum) {
+		return ErrChunkChecksum
+	}
+
+	file, err := os.OpenFile(name, os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err = file.WriteAt(data, offset); err != nil {
+		return fmt.Errorf("failed to write chunk: %w", err)
+	}
+	return nil
+}
+
+// OpenUploadFile opens assembled file of upload session, checksum is verified when it is not empty
+func OpenUploadFile(sessionId string, checksum string) (*os.File, error) {
+	return openChecksumFile(uploadFilePath(sessionId), checksum)
+}
+
+func openChecksumFile(name string, checksum string) (*os.File, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	if checksum == "" {
+		return file, nil
+	}
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read upload file: %w", err)
+	}
+	if !strings.EqualFold(hex.EncodeToString(hash.Sum(nil)), checksum) {
+		file.Close()
+		return nil, ErrUploadChecksum
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read upload file: %w", err)
+	}
+
+	return file, nil
+}
+
+// RemoveUploadFile removes file of upload session, missing file is not an error
+func RemoveUploadFile(sessionId string) error {
+	err := os.Remove(uploadFilePath(sessionId))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// RemoveStaleUploadFiles removes files of upload sessions not written since before, ex: file of deleted session
+func RemoveStaleUploadFiles(before time.Time) (int, error) {
+	entries, err := os.ReadDir(UploadSessionDir())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != uploadSessionExtension {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(before) {
+			continue
+		}
+		if err = os.Remove(filepath.Join(UploadSessionDir(), entry.Name())); err == nil {
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+func uploadFilePath(sessionId string) string {
+	// session id is hex of object id, base keeps it inside the folder anyway
+	return filepath.Join(UploadSessionDir(), filepath.Base(sessionId)+uploadSessionExtension)
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadChunkRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		index      int
+		chunkSize  int64
+		size       int64
+		wantOffset int64
+		wantSize   int64
+		wantErr    error
+	}{
+		{"first chunk", 0, 10, 25, 0, 10, nil},
+		{"middle chunk", 1, 10, 25, 10, 10, nil},
+		{"last chunk is shorter", 2, 10, 25, 20, 5, nil},
+		{"last chunk is full", 1, 10, 20, 10, 10, nil},
+		{"single chunk", 0, 10, 3, 0, 3, nil},
+		{"index after last chunk", 3, 10, 25, 0, 0, ErrChunkIndex},
+		{"index on end of file", 2, 10, 20, 0, 0, ErrChunkIndex},
+		{"negative index", -1, 10, 25, 0, 0, ErrChunkIndex},
+		{"zero chunk size", 0, 0, 25, 0, 0, ErrChunkIndex},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			offset, size, err := UploadChunkRange(test.index, test.chunkSize, test.size)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("UploadChunkRange() error = %v, want %v", err, test.wantErr)
+			}
+			if offset != test.wantOffset || size != test.wantSize {
+				t.Errorf("UploadChunkRange() = %d, %d, want %d, %d", offset, size, test.wantOffset, test.wantSize)
+			}
+		})
+	}
+}
+
+func TestWriteChunk(t *testing.T) {
+	chunk := []byte("world")
+	sum := sha256.Sum256(chunk)
+	checksum := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name     string
+		offset   int64
+		checksum string
+		want     string
+		wantErr  error
+	}{
+		{"written on offset", 6, checksum, "hello world", nil},
+		{"checksum with spaces", 6, " " + checksum, "hello _____", ErrChunkChecksum},
+		{"upper case checksum", 6, strings.ToUpper(checksum), "hello world", nil},
+		{"wrong checksum", 6, hex.EncodeToString(make([]byte, 32)), "hello _____", ErrChunkChecksum},
+		{"empty checksum", 6, "", "hello _____", ErrChunkChecksum},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "upload.part")
+			if err := os.WriteFile(name, []byte("hello _____"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := writeChunk(name, test.offset, chunk, test.checksum)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("writeChunk() error = %v, want %v", err, test.wantErr)
+			}
+
+			got, err := os.ReadFile(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf("file = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOpenChecksumFile(t *testing.T) {
+	content := []byte("hello world")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	name := filepath.Join(t.TempDir(), "upload.part")
+	if err := os.WriteFile(name, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		checksum string
+		wantErr  error
+	}{
+		{"matching checksum", checksum, nil},
+		{"upper case checksum", strings.ToUpper(checksum), nil},
+		{"no checksum", "", nil},
+		{"wrong checksum", hex.EncodeToString(make([]byte, 32)), ErrUploadChecksum},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file, err := openChecksumFile(name, test.checksum)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("openChecksumFile() error = %v, want %v", err, test.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			defer file.Close()
+
+			// file is read again from the start after its checksum is verified
+			got, err := io.ReadAll(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(content) {
+				t.Errorf("file = %q, want %q", got, content)
+			}
+		})
+	}
+}
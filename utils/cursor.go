@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FeedCursor is position of last item on feed sorted by date, type and id descending
+type FeedCursor struct {
+	Date int64
+	Type string
+	Id   string
+}
+
+// EncodeCursor encodes cursor to url safe string
+func EncodeCursor(cursor FeedCursor) string {
+	raw := fmt.Sprintf("%d|%s|%s", cursor.Date, cursor.Type, cursor.Id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor decodes cursor from EncodeCursor(), return nil if cursor is empty
+func DecodeCursor(encoded string) (*FeedCursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	date, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &FeedCursor{Date: date, Type: parts[1], Id: parts[2]}, nil
+}
+
+// After checks is item placed after cursor on descending feed
+func (cursor FeedCursor) After(date int64, itemType string, id string) bool {
+	if date != cursor.Date {
+		return date < cursor.Date
+	}
+	if itemType != cursor.Type {
+		return itemType < cursor.Type
+	}
+	return id < cursor.Id
+}
@@ -0,0 +1,296 @@
+package utils
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"follooow-be/configs"
+)
+
+const (
+	defaultGalleryImportMaxFiles = 200
+	defaultGalleryImportMaxBytes = 1 << 30
+)
+
+const (
+	// image entry is rejected when it is this many times larger than compressed, ex: zip bomb
+	maxArchiveCompressionRatio = 100
+	// EXIF is searched on the first 256KB of image entry only
+	archiveExifHeaderSize = 256 << 10
+	// captions.csv larger than 1MB is rejected
+	maxArchiveCaptionsBytes = 1 << 20
+)
+
+// order of images imported from archive
+const (
+	ArchiveOrderFilename = "filename"
+	ArchiveOrderExif     = "exif"
+)
+
+// name of optional captions file inside archive, filename,caption per row
+const archiveCaptionsFile = "captions.csv"
+
+var (
+	ErrArchiveInvalid      = errors.New("archive is not a valid ZIP file")
+	ErrArchiveUnsafePath   = errors.New("archive has unsafe file path")
+	ErrArchiveTooLarge     = errors.New("archive is too large")
+	ErrArchiveTooManyFiles = errors.New("archive has too many images")
+	ErrArchiveNoImages     = errors.New("archive has no images")
+	ErrArchiveCaptions     = errors.New("captions.csv is not readable")
+)
+
+// GalleryArchive is ZIP archive of gallery images, entries are never extracted to disk by their path
+type GalleryArchive struct {
+	reader   *zip.ReadCloser
+	Images   []*ArchiveImage
+	Captions map[string]string
+}
+
+// ArchiveImage is image entry of gallery archive
+type ArchiveImage struct {
+	Name      string
+	DateTaken time.Time
+	file      *zip.File
+}
+
+// GalleryImportMaxFiles gets max images of imported archive, default 200
+func GalleryImportMaxFiles() int {
+	return int(envInt64(configs.EnvGalleryImportMaxFiles(), defaultGalleryImportMaxFiles))
+}
+
+// GalleryImportMaxBytes gets max size of imported archive and of its extracted images, default 1GB
+func GalleryImportMaxBytes() int64 {
+	return envInt64(configs.EnvGalleryImportMaxBytes(), defaultGalleryImportMaxBytes)
+}
+
+// OpenGalleryArchive opens ZIP archive and checks its entries before any image is read
+// folders, hidden files, __MACOSX and files which are not images are skipped,
+// path with .. or absolute path rejects the whole archive
+func OpenGalleryArchive(name string) (*GalleryArchive, error) {
+	reader, err := zip.OpenReader(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrArchiveInvalid, err)
+	}
+
+	archive := &GalleryArchive{reader: reader, Captions: map[string]string{}}
+	if err = archive.scan(); err != nil {
+		reader.Close()
+		return nil, err
+	}
+	return archive, nil
+}
+
+func (a *GalleryArchive) scan() error {
+	maxFiles := GalleryImportMaxFiles()
+	maxBytes := GalleryImportMaxBytes()
+	maxImageBytes := uint64(ImageMaxBytes())
+
+	var total uint64
+	var captions *zip.File
+	for _, file := range a.reader.File {
+		if !isSafeArchivePath(file.Name) {
+			return fmt.Errorf("%w: %s", ErrArchiveUnsafePath, file.Name)
+		}
+		if file.FileInfo().IsDir() || isHiddenArchivePath(file.Name) {
+			continue
+		}
+
+		base := path.Base(file.Name)
+		if strings.EqualFold(base, archiveCaptionsFile) {
+			if captions == nil {
+				captions = file
+			}
+			continue
+		}
+		if !isArchiveImage(base) {
+			continue
+		}
+
+		// declared size is checked here, actual size is limited again when the image is read
+		if file.UncompressedSize64 > maxImageBytes {
+			return fmt.Errorf("%w: %s is %d bytes, max %d bytes", ErrImageTooLarge, file.Name, file.UncompressedSize64, maxImageBytes)
+		}
+		if file.CompressedSize64 > 0 && file.UncompressedSize64/file.CompressedSize64 > maxArchiveCompressionRatio {
+			return fmt.Errorf("%w: %s is compressed too much", ErrArchiveTooLarge, file.Name)
+		}
+		total += file.UncompressedSize64
+		if total > uint64(maxBytes) {
+			return fmt.Errorf("%w: images are more than %d bytes", ErrArchiveTooLarge, maxBytes)
+		}
+
+		a.Images = append(a.Images, &ArchiveImage{Name: base, file: file})
+		if len(a.Images) > maxFiles {
+			return fmt.Errorf("%w: max %d images", ErrArchiveTooManyFiles, maxFiles)
+		}
+	}
+
+	if len(a.Images) == 0 {
+		return ErrArchiveNoImages
+	}
+
+	if captions != nil {
+		return a.readCaptions(captions)
+	}
+	return nil
+}
+
+// readCaptions reads filename,caption rows of captions.csv, header row is optional
+// filename is matched by its base name case insensitively
+func (a *GalleryArchive) readCaptions(file *zip.File) error {
+	if file.UncompressedSize64 > maxArchiveCaptionsBytes {
+		return fmt.Errorf("%w: max %d bytes", ErrArchiveCaptions, maxArchiveCaptionsBytes)
+	}
+
+	entry, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrArchiveCaptions, err)
+	}
+	defer entry.Close()
+
+	reader := csv.NewReader(io.LimitReader(entry, maxArchiveCaptionsBytes))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrArchiveCaptions, err)
+	}
+
+	for i, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		name := strings.ToLower(path.Base(strings.TrimSpace(strings.TrimPrefix(row[0], "\ufeff"))))
+		if i == 0 && name == "filename" {
+			continue
+		}
+		a.Captions[name] = strings.TrimSpace(row[1])
+	}
+	return nil
+}
+
+// Caption gets caption of image from captions.csv, empty when it has no caption
+func (a *GalleryArchive) Caption(image *ArchiveImage) string {
+	return a.Captions[strings.ToLower(image.Name)]
+}
+
+// SortImages orders images by filename, or by EXIF date taken with exif order
+// images without EXIF date are put after dated images, ordered by filename
+func (a *GalleryArchive) SortImages(order string) {
+	if order == ArchiveOrderExif {
+		for _, image := range a.Images {
+			image.DateTaken = a.readDateTaken(image)
+		}
+	}
+
+	sort.SliceStable(a.Images, func(i, j int) bool {
+		left, right := a.Images[i], a.Images[j]
+		if order == ArchiveOrderExif && !left.DateTaken.Equal(right.DateTaken) {
+			if left.DateTaken.IsZero() || right.DateTaken.IsZero() {
+				return right.DateTaken.IsZero()
+			}
+			return left.DateTaken.Before(right.DateTaken)
+		}
+		return archiveNameLess(left.file.Name, right.file.Name)
+	})
+}
+
+// Open opens image entry to be uploaded, the reader is not limited so it is read with ReadImage
+func (a *GalleryArchive) Open(image *ArchiveImage) (io.ReadCloser, error) {
+	return image.file.Open()
+}
+
+// Close closes the archive file
+func (a *GalleryArchive) Close() error {
+	return a.reader.Close()
+}
+
+// readDateTaken reads EXIF date of image from its first bytes, zero when unknown
+func (a *GalleryArchive) readDateTaken(image *ArchiveImage) time.Time {
+	entry, err := image.file.Open()
+	if err != nil {
+		return time.Time{}
+	}
+	defer entry.Close()
+
+	head, _ := io.ReadAll(io.LimitReader(entry, archiveExifHeaderSize))
+	return ImageDateTaken(head)
+}
+
+// isSafeArchivePath rejects zip slip paths, ex: ../../etc/passwd, /etc/passwd or C:\file
+func isSafeArchivePath(name string) bool {
+	if name == "" || strings.Contains(name, "\\") || strings.Contains(name, "\x00") || path.IsAbs(name) {
+		return false
+	}
+	if len(name) > 1 && name[1] == ':' {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// isHiddenArchivePath is true for metadata added by archivers, ex: __MACOSX/._photo.jpg or .DS_Store
+func isHiddenArchivePath(name string) bool {
+	for _, part := range strings.Split(strings.TrimSuffix(name, "/"), "/") {
+		if strings.HasPrefix(part, ".") || part == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// isArchiveImage guesses image entry by its extension, content is checked on upload
+func isArchiveImage(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg", ".png", ".gif", ".webp", ".avif":
+		return true
+	}
+	return false
+}
+
+// archiveNameLess compares paths case insensitively with numbers compared by value,
+// so IMG_2.jpg is before IMG_10.jpg
+func archiveNameLess(left string, right string) bool {
+	left, right = strings.ToLower(left), strings.ToLower(right)
+	for left != "" && right != "" {
+		leftNumber, leftRest := leadingDigits(left)
+		rightNumber, rightRest := leadingDigits(right)
+		if leftNumber != "" && rightNumber != "" {
+			leftNumber = strings.TrimLeft(leftNumber, "0")
+			rightNumber = strings.TrimLeft(rightNumber, "0")
+			if len(leftNumber) != len(rightNumber) {
+				return len(leftNumber) < len(rightNumber)
+			}
+			if leftNumber != rightNumber {
+				return leftNumber < rightNumber
+			}
+			left, right = leftRest, rightRest
+			continue
+		}
+
+		if left[0] != right[0] {
+			return left[0] < right[0]
+		}
+		left, right = left[1:], right[1:]
+	}
+	return len(left) < len(right)
+}
+
+func leadingDigits(value string) (string, string) {
+	i := 0
+	for i < len(value) && value[i] >= '0' && value[i] <= '9' {
+		i++
+	}
+	return value[:i], value[i:]
+}
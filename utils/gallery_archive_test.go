@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestIsSafeArchivePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"photo.jpg", true},
+		{"summer/photo.jpg", true},
+		{"summer/", true},
+		{"./summer/photo.jpg", true},
+		{"photo..jpg", true},
+		{"..photo.jpg", true},
+		{"", false},
+		{"../photo.jpg", false},
+		{"summer/../../photo.jpg", false},
+		{"summer/..", false},
+		{"..", false},
+		{"/etc/passwd", false},
+		{"C:/photo.jpg", false},
+		{"c:photo.jpg", false},
+		{"C:\\photo.jpg", false},
+		{"summer\\..\\photo.jpg", false},
+		{"photo.jpg\x00.txt", false},
+	}
+
+	for _, test := range tests {
+		if got := isSafeArchivePath(test.name); got != test.want {
+			t.Errorf("isSafeArchivePath(%q) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestArchiveNameLess(t *testing.T) {
+	tests := []struct {
+		left, right string
+		want        bool
+	}{
+		{"IMG_2.jpg", "IMG_10.jpg", true},
+		{"IMG_10.jpg", "IMG_2.jpg", false},
+		{"img_1.jpg", "IMG_2.jpg", true},
+		{"IMG_002.jpg", "IMG_10.jpg", true},
+		{"IMG_010.jpg", "IMG_9.jpg", false},
+		{"IMG_02.jpg", "IMG_2.jpg", false},
+		{"IMG_2.jpg", "IMG_02.jpg", false},
+		{"IMG_0.jpg", "IMG_00.jpg", false},
+		{"a.jpg", "b.jpg", true},
+		{"photo.jpg", "photo 1.jpg", false},
+		{"photo", "photo.jpg", true},
+		{"day2/IMG_1.jpg", "day10/IMG_1.jpg", true},
+		{"IMG_1a.jpg", "IMG_1b.jpg", true},
+		{"99999999999999999999.jpg", "100000000000000000000.jpg", true},
+	}
+
+	for _, test := range tests {
+		if got := archiveNameLess(test.left, test.right); got != test.want {
+			t.Errorf("archiveNameLess(%q, %q) = %v, want %v", test.left, test.right, got, test.want)
+		}
+	}
+}
+
+// testZipFile zips content as single entry, then reads the entry back
+func testZipFile(t *testing.T, name string, content string) *zip.File {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	entry, err := writer.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = entry.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reader.File[0]
+}
+
+func TestReadCaptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr error
+	}{
+		{
+			name:    "with header",
+			content: "filename,caption\nIMG_1.jpg,Red carpet\nIMG_2.jpg,  Backstage  \n",
+			want:    map[string]string{"img_1.jpg": "Red carpet", "img_2.jpg": "Backstage"},
+		},
+		{
+			name:    "without header",
+			content: "IMG_1.jpg,Red carpet\n",
+			want:    map[string]string{"img_1.jpg": "Red carpet"},
+		},
+		{
+			name:    "BOM",
+			content: "\ufefffilename,caption\nIMG_1.jpg,Red carpet\n",
+			want:    map[string]string{"img_1.jpg": "Red carpet"},
+		},
+		{
+			name:    "BOM without header",
+			content: "\ufeffIMG_1.jpg,Red carpet\n",
+			want:    map[string]string{"img_1.jpg": "Red carpet"},
+		},
+		{
+			name:    "quoted caption",
+			content: "IMG_1.jpg,\"Lisa, Jennie and \"\"Rosé\"\"\"\nIMG_2.jpg,\"two\nlines\"\n",
+			want:    map[string]string{"img_1.jpg": "Lisa, Jennie and \"Rosé\"", "img_2.jpg": "two\nlines"},
+		},
+		{
+			name:    "folder and short rows",
+			content: "summer/IMG_1.jpg,Beach\nIMG_2.jpg\n\nIMG_3.jpg,Sunset,extra\n",
+			want:    map[string]string{"img_1.jpg": "Beach", "img_3.jpg": "Sunset"},
+		},
+		{
+			name:    "broken quote",
+			content: "IMG_1.jpg,\"Red carpet\nIMG_2.jpg,Backstage\n",
+			wantErr: ErrArchiveCaptions,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			archive := &GalleryArchive{Captions: map[string]string{}}
+			err := archive.readCaptions(testZipFile(t, archiveCaptionsFile, test.content))
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("readCaptions() error = %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr == nil && !reflect.DeepEqual(archive.Captions, test.want) {
+				t.Errorf("readCaptions() captions = %q, want %q", archive.Captions, test.want)
+			}
+		})
+	}
+}
+
+func TestReadCaptionsTooLarge(t *testing.T) {
+	file := testZipFile(t, archiveCaptionsFile, "IMG_1.jpg,Red carpet\n")
+	file.UncompressedSize64 = maxArchiveCaptionsBytes + 1
+
+	archive := &GalleryArchive{Captions: map[string]string{}}
+	if err := archive.readCaptions(file); !errors.Is(err, ErrArchiveCaptions) {
+		t.Errorf("readCaptions() error = %v, want %v", err, ErrArchiveCaptions)
+	}
+}
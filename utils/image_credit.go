@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"follooow-be/models"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxImageCreditLength    = 200
+	maxImageSourceUrlLength = 2048
+	// smallest timestamp in milliseconds (2001-09-09), timestamp in seconds is always below it
+	minLicenseExpiresOn = 1e12
+)
+
+var (
+	ErrImageLicense        = errors.New("license is not valid, allowed: own, editorial, rights-managed, royalty-free, creative-commons, press")
+	ErrImageCreditRequired = errors.New("credit is required for licensed image")
+	ErrImageSourceRequired = errors.New("source_url is required for editorial and rights-managed image")
+	ErrImageCredit         = errors.New("credit is too long")
+	ErrImageSourceUrl      = errors.New("source_url must be http or https url")
+	ErrImageLicenseExpiry  = errors.New("license_expires_on needs a license other than own")
+	ErrImageLicenseDate    = errors.New("license_expires_on must be timestamp in milliseconds")
+)
+
+var imageLicenses = map[string]bool{
+	models.ImageLicenseOwn:             true,
+	models.ImageLicenseEditorial:       true,
+	models.ImageLicenseRightsManaged:   true,
+	models.ImageLicenseRoyaltyFree:     true,
+	models.ImageLicenseCreativeCommons: true,
+	models.ImageLicensePress:           true,
+}
+
+// NormalizeImageCredit trims credit and source url, and lowercases license
+func NormalizeImageCredit(credit *models.ImageCreditModel) {
+	credit.Credit = strings.TrimSpace(credit.Credit)
+	credit.SourceUrl = strings.TrimSpace(credit.SourceUrl)
+	credit.License = strings.ToLower(strings.TrimSpace(credit.License))
+}
+
+// ValidateImageCredit checks license of image, image licensed from others needs credit
+// agency photos (editorial and rights-managed) need their source too
+func ValidateImageCredit(credit models.ImageCreditModel) error {
+	if utf8.RuneCountInString(credit.Credit) > maxImageCreditLength {
+		return fmt.Errorf("%w: max %d characters", ErrImageCredit, maxImageCreditLength)
+	}
+
+	if credit.SourceUrl != "" {
+		parsed, err := url.Parse(credit.SourceUrl)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || len(credit.SourceUrl) > maxImageSourceUrlLength {
+			return ErrImageSourceUrl
+		}
+	}
+
+	if credit.License == "" || credit.License == models.ImageLicenseOwn {
+		if credit.LicenseExpiresOn != 0 {
+			return ErrImageLicenseExpiry
+		}
+		return nil
+	}
+
+	if !imageLicenses[credit.License] {
+		return ErrImageLicense
+	}
+	if credit.Credit == "" {
+		return ErrImageCreditRequired
+	}
+	if credit.SourceUrl == "" && (credit.License == models.ImageLicenseEditorial || credit.License == models.ImageLicenseRightsManaged) {
+		return ErrImageSourceRequired
+	}
+	if credit.LicenseExpiresOn != 0 && credit.LicenseExpiresOn < minLicenseExpiresOn {
+		return ErrImageLicenseDate
+	}
+	return nil
+}
+
+// ValidateGalleryImageCredits normalizes then validates credit of every image, error has index of the invalid image
+func ValidateGalleryImageCredits(images []models.ImageModel) error {
+	for key := range images {
+		NormalizeImageCredit(&images[key].ImageCreditModel)
+		if err := ValidateImageCredit(images[key].ImageCreditModel); err != nil {
+			return fmt.Errorf("image %d: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// IsImageCreditError is true when err is returned by credit validation
+func IsImageCreditError(err error) bool {
+	for _, target := range []error{ErrImageLicense, ErrImageCreditRequired, ErrImageSourceRequired, ErrImageCredit, ErrImageSourceUrl, ErrImageLicenseExpiry, ErrImageLicenseDate} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetGalleryImageLicenses marks images which license is expired
+func SetGalleryImageLicenses(gallery *models.GalleryModel) {
+	for key := range gallery.Images {
+		SetImageLicense(&gallery.Images[key])
+	}
+}
+
+// SetImageLicense marks image when its license is expired
+func SetImageLicense(image *models.ImageModel) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	image.LicenseExpired = image.LicenseExpiresOn > 0 && image.LicenseExpiresOn <= now
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+	"follooow-be/models"
+	"strings"
+	"testing"
+)
+
+func TestValidateImageCredit(t *testing.T) {
+	tests := []struct {
+		name    string
+		credit  models.ImageCreditModel
+		wantErr error
+	}{
+		{"no credit", models.ImageCreditModel{}, nil},
+		{"own image", models.ImageCreditModel{License: models.ImageLicenseOwn}, nil},
+		{"royalty free", models.ImageCreditModel{Credit: "Jane Doe", License: models.ImageLicenseRoyaltyFree}, nil},
+		{"expiry in milliseconds", models.ImageCreditModel{Credit: "Jane Doe", License: models.ImageLicensePress, LicenseExpiresOn: 1767139200000}, nil},
+		{"expiry in seconds", models.ImageCreditModel{Credit: "Jane Doe", License: models.ImageLicensePress, LicenseExpiresOn: 1767139200}, ErrImageLicenseDate},
+		{"negative expiry", models.ImageCreditModel{Credit: "Jane Doe", License: models.ImageLicensePress, LicenseExpiresOn: -1}, ErrImageLicenseDate},
+		{"expiry of own image", models.ImageCreditModel{License: models.ImageLicenseOwn, LicenseExpiresOn: 1767139200000}, ErrImageLicenseExpiry},
+		{"credit of 200 multibyte characters", models.ImageCreditModel{Credit: strings.Repeat("é", 200), License: models.ImageLicenseRoyaltyFree}, nil},
+		{"credit of 201 characters", models.ImageCreditModel{Credit: strings.Repeat("a", 201), License: models.ImageLicenseRoyaltyFree}, ErrImageCredit},
+		{"unknown license", models.ImageCreditModel{Credit: "Jane Doe", License: "free"}, ErrImageLicense},
+		{"licensed without credit", models.ImageCreditModel{License: models.ImageLicenseRoyaltyFree}, ErrImageCreditRequired},
+		{"editorial without source", models.ImageCreditModel{Credit: "Getty Images", License: models.ImageLicenseEditorial}, ErrImageSourceRequired},
+		{"source is not http", models.ImageCreditModel{Credit: "Getty Images", SourceUrl: "ftp://getty.com/1", License: models.ImageLicenseEditorial}, ErrImageSourceUrl},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := ValidateImageCredit(test.credit); !errors.Is(err, test.wantErr) {
+				t.Errorf("ValidateImageCredit() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
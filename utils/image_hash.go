@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"math/bits"
+	"strconv"
+
+	"follooow-be/configs"
+	"follooow-be/models"
+
+	// decoder of webp uploads, avif has no go decoder so it is not hashed
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	DuplicateImageOff    = "off"
+	DuplicateImageWarn   = "warn"
+	DuplicateImageReject = "reject"
+
+	defaultDuplicateImageDistance = 6
+
+	// hash is split to 8 bands of 8 bits, near duplicate within 7 bits has at least one equal band
+	imageHashBands = 8
+)
+
+var ErrImageHashUnsupported = errors.New("image format can't be hashed")
+
+// ImageHash computes 64 bits difference hash (dHash) of image as 16 hex chars
+// ErrImageHashUnsupported when there is no decoder of the format, ex: avif
+func ImageHash(data []byte) (string, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err == image.ErrFormat {
+		return "", ErrImageHashUnsupported
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	// grayscale of 9x8 cells, each cell is average of sampled pixels
+	var cells [8][9]float64
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w < 1 || h < 1 {
+		return "", fmt.Errorf("failed to decode image: empty image")
+	}
+
+	for cy := 0; cy < 8; cy++ {
+		for cx := 0; cx < 9; cx++ {
+			x0, x1 := cx*w/9, (cx+1)*w/9
+			y0, y1 := cy*h/8, (cy+1)*h/8
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			if y1 <= y0 {
+				y1 = y0 + 1
+			}
+
+			// at most 8x8 samples per cell keep large images fast
+			stepX, stepY := (x1-x0+7)/8, (y1-y0+7)/8
+			total, count := 0.0, 0
+			for y := y0; y < y1 && y < h; y += stepY {
+				for x := x0; x < x1 && x < w; x += stepX {
+					r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+					total += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					count++
+				}
+			}
+			if count > 0 {
+				cells[cy][cx] = total / float64(count)
+			}
+		}
+	}
+
+	var hash uint64
+	for cy := 0; cy < 8; cy++ {
+		for cx := 0; cx < 8; cx++ {
+			hash <<= 1
+			if cells[cy][cx] > cells[cy][cx+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return fmt.Sprintf("%016x", hash), nil
+}
+
+// ImageHashBands splits hash to bands used to find candidates, ex: ["0:a1", "1:ff", ...]
+func ImageHashBands(hash string) []string {
+	if len(hash) != 16 {
+		return nil
+	}
+
+	bands := make([]string, 0, imageHashBands)
+	for i := 0; i < imageHashBands; i++ {
+		bands = append(bands, strconv.Itoa(i)+":"+hash[i*2:i*2+2])
+	}
+	return bands
+}
+
+// SetImageHashBands fills hash bands of images from their hash
+// bands are not sent on json, so images saved from json payload lose them without this
+func SetImageHashBands(images []models.ImageModel) {
+	for key := range images {
+		images[key].HashBands = ImageHashBands(images[key].Hash)
+	}
+}
+
+// ImageHashDistance counts different bits of two hashes, -1 when any hash is invalid
+func ImageHashDistance(a string, b string) int {
+	x, errA := strconv.ParseUint(a, 16, 64)
+	y, errB := strconv.ParseUint(b, 16, 64)
+	if errA != nil || errB != nil || len(a) != 16 || len(b) != 16 {
+		return -1
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+// DuplicateImageMode gets what to do with duplicate upload: off, warn (default) or reject
+func DuplicateImageMode() string {
+	switch mode := configs.EnvDuplicateImageMode(); mode {
+	case DuplicateImageOff, DuplicateImageReject:
+		return mode
+	default:
+		return DuplicateImageWarn
+	}
+}
+
+// DuplicateImageDistance gets max hash distance of near duplicate images, default 6
+func DuplicateImageDistance() int {
+	distance, err := strconv.Atoi(configs.EnvDuplicateImageDistance())
+	if err != nil || distance < 0 || distance >= imageHashBands {
+		return defaultDuplicateImageDistance
+	}
+	return distance
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"follooow-be/models"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"testing"
+)
+
+// testPNG encodes horizontal gradient, darker to the right
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewGray(image.Rect(0, 0, 90, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 90; x++ {
+			img.SetGray(x, y, color.Gray{Y: uint8(255 - x*2)})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageHash(t *testing.T) {
+	// 1x1 lossless and lossy webp
+	lossless, _ := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	lossy, _ := base64.StdEncoding.DecodeString("UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA")
+	avif := append([]byte{0, 0, 0, 0x1c}, []byte("ftypavif\x00\x00\x00\x00avifmif1miaf")...)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    string
+		wantErr error
+	}{
+		{"png gradient", testPNG(t), "ffffffffffffffff", nil},
+		{"webp lossless", lossless, "0000000000000000", nil},
+		{"webp lossy", lossy, "0000000000000000", nil},
+		{"avif", avif, "", ErrImageHashUnsupported},
+		{"not image", []byte("hello"), "", ErrImageHashUnsupported},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ImageHash(test.data)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("ImageHash() error = %v, want %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("ImageHash() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestImageHashDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"346d64e46c892ea4", "346d64e46c892ea4", 0},
+		{"346d64e46c892ea4", "346564e42c892ea4", 2},
+		{"0000000000000000", "ffffffffffffffff", 64},
+		{"346d64e46c892ea4", "", -1},
+		{"zz6d64e46c892ea4", "346d64e46c892ea4", -1},
+	}
+
+	for _, test := range tests {
+		if got := ImageHashDistance(test.a, test.b); got != test.want {
+			t.Errorf("ImageHashDistance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestSetImageHashBands(t *testing.T) {
+	images := []models.ImageModel{
+		{Hash: "346d64e46c892ea4", HashBands: []string{"0:ff"}},
+		{Hash: ""},
+		{Hash: "invalid", HashBands: []string{"0:ff"}},
+	}
+
+	SetImageHashBands(images)
+
+	want := []string{"0:34", "1:6d", "2:64", "3:e4", "4:6c", "5:89", "6:2e", "7:a4"}
+	if !reflect.DeepEqual(images[0].HashBands, want) {
+		t.Errorf("HashBands = %v, want %v", images[0].HashBands, want)
+	}
+	if images[1].HashBands != nil || images[2].HashBands != nil {
+		t.Errorf("HashBands of image without valid hash = %v, %v, want nil", images[1].HashBands, images[2].HashBands)
+	}
+}
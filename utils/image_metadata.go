@@ -0,0 +1,691 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+	"time"
+
+	"follooow-be/configs"
+)
+
+const defaultJPEGQuality = 90
+
+// options of stripImageMetadata, read from env by StripImageMetadata
+type stripOptions struct {
+	keepCopyright bool
+	jpegQuality   int
+}
+
+// EXIF tags read from image, only tags used by upload are kept
+type exifTags struct {
+	Orientation int
+	Artist      string
+	Copyright   string
+	DateTaken   time.Time
+}
+
+// StripImageMetadata removes EXIF, GPS and XMP metadata of image before it is stored
+// JPEG and PNG are auto-oriented by EXIF orientation then re-encoded, GIF extensions are removed without decoding,
+// WebP and AVIF metadata is removed from the container since they can't be decoded here.
+// artist and copyright are kept when IMAGE_KEEP_COPYRIGHT=true, except on AVIF
+func StripImageMetadata(data []byte, format string) ([]byte, error) {
+	quality, err := strconv.Atoi(configs.EnvImageJPEGQuality())
+	if err != nil || quality < 1 || quality > 100 {
+		quality = defaultJPEGQuality
+	}
+
+	return stripImageMetadata(data, format, stripOptions{
+		keepCopyright: configs.EnvImageKeepCopyright() == "true",
+		jpegQuality:   quality,
+	})
+}
+
+func stripImageMetadata(data []byte, format string, opts stripOptions) ([]byte, error) {
+	switch format {
+	case "jpeg":
+		return stripJPEG(data, opts.keepCopyright, opts.jpegQuality)
+	case "png":
+		return stripPNG(data, opts.keepCopyright)
+	case "gif":
+		return stripGIF(data)
+	case "webp":
+		return stripWebP(data, opts.keepCopyright)
+	case "avif":
+		return stripAVIF(data)
+	}
+
+	return nil, ErrImageType
+}
+
+// ImageDateTaken reads date the photo was taken from EXIF of JPEG or PNG, zero when it is unknown
+// only the header is needed, so data can be the first bytes of the file
+func ImageDateTaken(data []byte) time.Time {
+	switch SniffImageFormat(data) {
+	case "jpeg":
+		return parseExif(jpegExif(data)).DateTaken
+	case "png":
+		return parseExif(pngExif(data)).DateTaken
+	}
+	return time.Time{}
+}
+
+func stripJPEG(data []byte, keepCopyright bool, quality int) ([]byte, error) {
+	tags := parseExif(jpegExif(data))
+
+	img, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageUnreadable, err)
+	}
+
+	var out bytes.Buffer
+	if err = jpeg.Encode(&out, orientImage(img, tags.Orientation), &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	encoded := out.Bytes()
+	if exif := copyrightExif(tags, keepCopyright); exif != nil {
+		// APP1 segment right after SOI marker
+		segment := []byte{0xFF, 0xE1, 0, 0}
+		binary.BigEndian.PutUint16(segment[2:], uint16(2+len(exif)))
+		segment = append(segment, exif...)
+
+		encoded = append(encoded[:2:2], append(segment, encoded[2:]...)...)
+	}
+
+	return encoded, nil
+}
+
+func stripPNG(data []byte, keepCopyright bool) ([]byte, error) {
+	tags := parseExif(pngExif(data))
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImageUnreadable, err)
+	}
+
+	var out bytes.Buffer
+	if err = png.Encode(&out, orientImage(img, tags.Orientation)); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	encoded := out.Bytes()
+	if exif := copyrightExif(tags, keepCopyright); exif != nil {
+		// eXIf chunk of TIFF data without "Exif\0\0", right after IHDR chunk (8 signature + 25 IHDR)
+		chunk := pngChunk("eXIf", exif[6:])
+		encoded = append(encoded[:33:33], append(chunk, encoded[33:]...)...)
+	}
+
+	return encoded, nil
+}
+
+// stripGIF copies blocks of GIF without decoding its frames, so frames count and size can't inflate memory
+// comment and application extensions are dropped, except NETSCAPE2.0/ANIMEXTS1.0 which keep animation looping
+func stripGIF(data []byte) ([]byte, error) {
+	// header and logical screen descriptor
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return nil, ErrImageUnreadable
+	}
+	offset := 13 + gifColorTableSize(data[10])
+	if offset > len(data) {
+		return nil, ErrImageUnreadable
+	}
+	out := append([]byte{}, data[:offset]...)
+
+	for offset < len(data) {
+		switch data[offset] {
+		case 0x3B: // trailer, anything after it is dropped
+			return append(out, 0x3B), nil
+		case 0x2C: // image descriptor, local color table, LZW code size then image data
+			start := offset
+			if offset+10 > len(data) {
+				return nil, ErrImageUnreadable
+			}
+			offset += 10 + gifColorTableSize(data[offset+9]) + 1
+			end, ok := gifSubBlocksEnd(data, offset)
+			if !ok {
+				return nil, ErrImageUnreadable
+			}
+			out = append(out, data[start:end]...)
+			offset = end
+		case 0x21: // extension, label then sub blocks
+			if offset+2 > len(data) {
+				return nil, ErrImageUnreadable
+			}
+			end, ok := gifSubBlocksEnd(data, offset+2)
+			if !ok {
+				return nil, ErrImageUnreadable
+			}
+			if keepGIFExtension(data[offset+1], data[offset+2:end]) {
+				out = append(out, data[offset:end]...)
+			}
+			offset = end
+		default:
+			return nil, ErrImageUnreadable
+		}
+	}
+
+	// trailer is missing, browsers still show the image
+	return append(out, 0x3B), nil
+}
+
+// gifColorTableSize gets size in bytes of color table of packed field, 0 when the table flag is not set
+func gifColorTableSize(packed byte) int {
+	if packed&0x80 == 0 {
+		return 0
+	}
+	return 3 << (packed&0x07 + 1)
+}
+
+// gifSubBlocksEnd gets end of data sub blocks starting at offset, after its zero length terminator
+func gifSubBlocksEnd(data []byte, offset int) (int, bool) {
+	for offset < len(data) {
+		size := int(data[offset])
+		offset++
+		if size == 0 {
+			return offset, true
+		}
+		offset += size
+	}
+	return 0, false
+}
+
+// keepGIFExtension reports whether GIF extension is needed to show the image
+// graphic control (frame delay and transparency) and plain text are kept, looping application extension too
+func keepGIFExtension(label byte, blocks []byte) bool {
+	switch label {
+	case 0xF9, 0x01:
+		return true
+	case 0xFF:
+		// first sub block is 11 bytes application identifier and authentication code
+		if len(blocks) >= 12 && blocks[0] == 11 {
+			identifier := string(blocks[1:12])
+			return identifier == "NETSCAPE2.0" || identifier == "ANIMEXTS1.0"
+		}
+	}
+	return false
+}
+
+// stripWebP removes EXIF and XMP chunks, orientation of WebP is not applied by browsers
+func stripWebP(data []byte, keepCopyright bool) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, ErrImageUnreadable
+	}
+
+	var tags exifTags
+	out := append([]byte{}, data[:12]...)
+	vp8x := -1
+
+	for offset := 12; offset+8 <= len(data); {
+		fourCC := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		end := offset + 8 + size + size%2
+		if end > len(data) {
+			return nil, ErrImageUnreadable
+		}
+
+		switch fourCC {
+		case "EXIF":
+			tags = parseExif(data[offset+8 : offset+8+size])
+		case "XMP ":
+		default:
+			if fourCC == "VP8X" {
+				vp8x = len(out)
+			}
+			out = append(out, data[offset:end]...)
+		}
+		offset = end
+	}
+
+	if vp8x >= 0 {
+		// clear EXIF (0x08) and XMP (0x04) flags
+		out[vp8x+8] &^= 0x0C
+
+		if exif := copyrightExif(tags, keepCopyright); exif != nil {
+			chunk := []byte("EXIF\x00\x00\x00\x00")
+			binary.LittleEndian.PutUint32(chunk[4:], uint32(len(exif)-6))
+			chunk = append(chunk, exif[6:]...)
+			if len(chunk)%2 == 1 {
+				chunk = append(chunk, 0)
+			}
+			out = append(out, chunk...)
+			out[vp8x+8] |= 0x08
+		}
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+// stripAVIF zeroes Exif and XMP items in place, so box offsets stay valid
+// orientation of AVIF is stored on irot/imir properties, not on EXIF
+func stripAVIF(data []byte) ([]byte, error) {
+	out := append([]byte{}, data...)
+
+	meta := findBox(out, 0, len(out), "meta")
+	if meta == nil {
+		return out, nil
+	}
+	// meta is full box, children start after version and flags
+	start, end := meta[0]+4, meta[1]
+
+	metadataItems := map[uint32]bool{}
+	if iinf := findBox(out, start, end, "iinf"); iinf != nil {
+		for id, itemType := range avifItemTypes(out[iinf[0]:iinf[1]]) {
+			if itemType == "Exif" || itemType == "mime" {
+				metadataItems[id] = true
+			}
+		}
+	}
+
+	if iloc := findBox(out, start, end, "iloc"); iloc != nil && len(metadataItems) > 0 {
+		for _, extent := range avifItemExtents(out[iloc[0]:iloc[1]], metadataItems) {
+			if extent[0] >= 0 && extent[1] > 0 && extent[0]+extent[1] <= len(out) {
+				for i := extent[0]; i < extent[0]+extent[1]; i++ {
+					out[i] = 0
+				}
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// orientImage rotates and flips image by EXIF orientation, 1 (or unknown) is returned as is
+func orientImage(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	dstW, dstH := w, h
+	if orientation >= 5 {
+		dstW, dstH = h, w
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		for x := 0; x < dstW; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // flip horizontal
+				sx, sy = w-1-x, y
+			case 3: // rotate 180
+				sx, sy = w-1-x, h-1-y
+			case 4: // flip vertical
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // rotate 90 clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotate 90 counter clockwise
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, img.At(bounds.Min.X+sx, bounds.Min.Y+sy))
+		}
+	}
+
+	return dst
+}
+
+// jpegExif gets TIFF data of JPEG APP1 Exif segment
+func jpegExif(data []byte) []byte {
+	for offset := 2; offset+4 <= len(data) && data[offset] == 0xFF; {
+		marker := data[offset+1]
+		// start of scan, no more metadata
+		if marker == 0xDA {
+			break
+		}
+
+		size := int(binary.BigEndian.Uint16(data[offset+2 : offset+4]))
+		end := offset + 2 + size
+		if size < 2 || end > len(data) {
+			break
+		}
+
+		segment := data[offset+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		offset = end
+	}
+	return nil
+}
+
+// pngExif gets TIFF data of PNG eXIf chunk
+func pngExif(data []byte) []byte {
+	for offset := 8; offset+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		chunkType := string(data[offset+4 : offset+8])
+		end := offset + 12 + size
+		if size < 0 || end > len(data) {
+			break
+		}
+
+		if chunkType == "eXIf" {
+			return data[offset+8 : offset+8+size]
+		}
+		if chunkType == "IDAT" {
+			break
+		}
+		offset = end
+	}
+	return nil
+}
+
+// parseExif reads orientation, artist, copyright and date taken of IFD0 in TIFF data
+// date taken is DateTimeOriginal of EXIF IFD, or DateTime of IFD0 when it is missing
+func parseExif(tiff []byte) exifTags {
+	tags := exifTags{Orientation: 1}
+	if len(tiff) < 8 {
+		return tags
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[0:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return tags
+	}
+
+	var modified string
+	exifIFD := 0
+	readIFD(tiff, order, int(order.Uint32(tiff[4:8])), func(tag uint16, valueType uint16, entry int) {
+		switch tag {
+		case 0x0112: // orientation, SHORT
+			if valueType == 3 {
+				tags.Orientation = int(order.Uint16(tiff[entry+8 : entry+10]))
+			}
+		case 0x013B: // artist, ASCII
+			tags.Artist = exifASCII(tiff, order, valueType, entry)
+		case 0x8298: // copyright, ASCII
+			tags.Copyright = exifASCII(tiff, order, valueType, entry)
+		case 0x0132: // date time, ASCII
+			modified = exifASCII(tiff, order, valueType, entry)
+		case 0x8769: // EXIF IFD pointer, LONG
+			if valueType == 4 {
+				exifIFD = int(order.Uint32(tiff[entry+8 : entry+12]))
+			}
+		}
+	})
+
+	var original string
+	readIFD(tiff, order, exifIFD, func(tag uint16, valueType uint16, entry int) {
+		if tag == 0x9003 { // date time original, ASCII
+			original = exifASCII(tiff, order, valueType, entry)
+		}
+	})
+
+	tags.DateTaken = parseExifTime(original)
+	if tags.DateTaken.IsZero() {
+		tags.DateTaken = parseExifTime(modified)
+	}
+
+	return tags
+}
+
+// readIFD calls fn with offset of every 12 bytes entry of IFD, IFD outside of TIFF data is skipped
+func readIFD(tiff []byte, order binary.ByteOrder, ifd int, fn func(tag uint16, valueType uint16, entry int)) {
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return
+	}
+
+	count := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		fn(order.Uint16(tiff[entry:entry+2]), order.Uint16(tiff[entry+2:entry+4]), entry)
+	}
+}
+
+// exifASCII reads ASCII value of IFD entry, value longer than 4 bytes is stored at offset
+func exifASCII(tiff []byte, order binary.ByteOrder, valueType uint16, entry int) string {
+	if valueType != 2 {
+		return ""
+	}
+
+	valueCount := int(order.Uint32(tiff[entry+4 : entry+8]))
+	value := tiff[entry+8 : entry+12]
+	if valueCount > 4 {
+		offset := int(order.Uint32(value))
+		if offset < 0 || offset+valueCount > len(tiff) {
+			return ""
+		}
+		value = tiff[offset : offset+valueCount]
+	} else {
+		value = value[:valueCount]
+	}
+	return string(bytes.TrimRight(value, "\x00"))
+}
+
+// parseExifTime parses EXIF date "2006:01:02 15:04:05", zero when it is missing or invalid
+// EXIF date has no timezone, so it is read as UTC
+func parseExifTime(value string) time.Time {
+	date, err := time.Parse("2006:01:02 15:04:05", strings.TrimSpace(value))
+	if err != nil {
+		return time.Time{}
+	}
+	return date
+}
+
+// copyrightExif builds "Exif\0\0" + TIFF data with only artist and copyright
+// nil when copyright is not kept or image has neither tags
+func copyrightExif(tags exifTags, keepCopyright bool) []byte {
+	if !keepCopyright || (tags.Artist == "" && tags.Copyright == "") {
+		return nil
+	}
+
+	type entry struct {
+		tag   uint16
+		value string
+	}
+	var entries []entry
+	if tags.Artist != "" {
+		entries = append(entries, entry{0x013B, tags.Artist})
+	}
+	if tags.Copyright != "" {
+		entries = append(entries, entry{0x8298, tags.Copyright})
+	}
+
+	order := binary.LittleEndian
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+
+	ifd := make([]byte, 2+len(entries)*12+4)
+	order.PutUint16(ifd[0:2], uint16(len(entries)))
+
+	// values are stored after IFD
+	values := []byte{}
+	valuesOffset := len(tiff) + len(ifd)
+	for i, e := range entries {
+		value := append([]byte(e.value), 0)
+		field := ifd[2+i*12 : 2+(i+1)*12]
+		order.PutUint16(field[0:2], e.tag)
+		order.PutUint16(field[2:4], 2)
+		order.PutUint32(field[4:8], uint32(len(value)))
+		if len(value) <= 4 {
+			copy(field[8:12], value)
+			continue
+		}
+		order.PutUint32(field[8:12], uint32(valuesOffset+len(values)))
+		values = append(values, value...)
+	}
+
+	exif := append([]byte("Exif\x00\x00"), tiff...)
+	exif = append(exif, ifd...)
+	return append(exif, values...)
+}
+
+func pngChunk(chunkType string, data []byte) []byte {
+	chunk := make([]byte, 8, 12+len(data))
+	binary.BigEndian.PutUint32(chunk[0:4], uint32(len(data)))
+	copy(chunk[4:8], chunkType)
+	chunk = append(chunk, data...)
+
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.ChecksumIEEE(chunk[4:]))
+	return append(chunk, crc...)
+}
+
+// findBox finds ISOBMFF box between start and end, returns start and end of its content
+func findBox(data []byte, start int, end int, boxType string) []int {
+	for offset := start; offset+8 <= end; {
+		size := int(binary.BigEndian.Uint32(data[offset : offset+4]))
+		header := 8
+		switch size {
+		case 0:
+			size = end - offset
+		case 1:
+			if offset+16 > end {
+				return nil
+			}
+			size = int(binary.BigEndian.Uint64(data[offset+8 : offset+16]))
+			header = 16
+		}
+		if size < header || offset+size > end {
+			return nil
+		}
+
+		if string(data[offset+4:offset+8]) == boxType {
+			return []int{offset + header, offset + size}
+		}
+		offset += size
+	}
+	return nil
+}
+
+// avifItemTypes reads item type of every infe entry of iinf box content
+func avifItemTypes(iinf []byte) map[uint32]string {
+	items := map[uint32]string{}
+	if len(iinf) < 6 {
+		return items
+	}
+
+	// version, flags and entry count
+	start := 6
+	if iinf[0] != 0 {
+		start = 8
+	}
+
+	for offset := start; offset+8 <= len(iinf); {
+		size := int(binary.BigEndian.Uint32(iinf[offset : offset+4]))
+		if size < 8 || offset+size > len(iinf) {
+			break
+		}
+
+		infe := iinf[offset+8 : offset+size]
+		if string(iinf[offset+4:offset+8]) == "infe" && len(infe) >= 4 {
+			switch version := infe[0]; {
+			case version == 2 && len(infe) >= 12:
+				items[uint32(binary.BigEndian.Uint16(infe[4:6]))] = string(infe[8:12])
+			case version == 3 && len(infe) >= 14:
+				items[binary.BigEndian.Uint32(infe[4:8])] = string(infe[10:14])
+			}
+		}
+		offset += size
+	}
+
+	return items
+}
+
+// avifItemExtents reads file offset and length of items on iloc box content
+// items stored on idat box (construction method 1) are skipped
+func avifItemExtents(iloc []byte, items map[uint32]bool) [][2]int {
+	var extents [][2]int
+	if len(iloc) < 8 {
+		return extents
+	}
+
+	version := iloc[0]
+	offsetSize := int(iloc[4] >> 4)
+	lengthSize := int(iloc[4] & 0x0F)
+	baseOffsetSize := int(iloc[5] >> 4)
+	indexSize := 0
+	if version == 1 || version == 2 {
+		indexSize = int(iloc[5] & 0x0F)
+	}
+
+	position := 6
+	read := func(size int) (int, bool) {
+		if position+size > len(iloc) {
+			return 0, false
+		}
+		var value uint64
+		for i := 0; i < size; i++ {
+			value = value<<8 | uint64(iloc[position+i])
+		}
+		position += size
+		return int(value), true
+	}
+
+	itemCountSize := 2
+	if version == 2 {
+		itemCountSize = 4
+	}
+	itemCount, ok := read(itemCountSize)
+	if !ok {
+		return extents
+	}
+
+	for i := 0; i < itemCount; i++ {
+		itemId, ok := read(itemCountSize)
+		if !ok {
+			return extents
+		}
+
+		constructionMethod := 0
+		if version == 1 || version == 2 {
+			if constructionMethod, ok = read(2); !ok {
+				return extents
+			}
+			constructionMethod &= 0x0F
+		}
+
+		// data reference index
+		if _, ok = read(2); !ok {
+			return extents
+		}
+		baseOffset, ok := read(baseOffsetSize)
+		if !ok {
+			return extents
+		}
+		extentCount, ok := read(2)
+		if !ok {
+			return extents
+		}
+
+		for j := 0; j < extentCount; j++ {
+			if _, ok = read(indexSize); !ok {
+				return extents
+			}
+			offset, ok := read(offsetSize)
+			if !ok {
+				return extents
+			}
+			length, ok := read(lengthSize)
+			if !ok {
+				return extents
+			}
+
+			if items[uint32(itemId)] && constructionMethod == 0 {
+				extents = append(extents, [2]int{baseOffset + offset, length})
+			}
+		}
+	}
+
+	return extents
+}
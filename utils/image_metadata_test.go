@@ -0,0 +1,339 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testGIF encodes animated GIF of frames which loops forever, then adds comment and XMP extensions before the trailer
+func testGIF(t *testing.T, frames int) []byte {
+	t.Helper()
+
+	palette := color.Palette{color.Black, color.White}
+	animation := &gif.GIF{LoopCount: 0}
+	for i := 0; i < frames; i++ {
+		frame := image.NewPaletted(image.Rect(0, 0, 4, 4), palette)
+		frame.SetColorIndex(i%4, i%4, 1)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 10)
+	}
+
+	var out bytes.Buffer
+	if err := gif.EncodeAll(&out, animation); err != nil {
+		t.Fatal(err)
+	}
+
+	data := out.Bytes()
+	comment := append([]byte{0x21, 0xFE, 6}, "secret\x00"...)
+	xmp := append([]byte{0x21, 0xFF, 11}, "XMP DataXMP"...)
+	xmp = append(xmp, 9)
+	xmp = append(xmp, "GPS 12,34"...)
+	xmp = append(xmp, 0)
+
+	trailer := len(data) - 1
+	extensions := append(comment, xmp...)
+	return append(append(append([]byte{}, data[:trailer]...), extensions...), 0x3B)
+}
+
+func TestStripGIF(t *testing.T) {
+	data := testGIF(t, 3)
+
+	stripped, err := stripGIF(data)
+	if err != nil {
+		t.Fatalf("stripGIF() error = %v", err)
+	}
+	if bytes.Contains(stripped, []byte("secret")) || bytes.Contains(stripped, []byte("GPS 12,34")) {
+		t.Error("stripGIF() kept comment or XMP extension")
+	}
+
+	decoded, err := gif.DecodeAll(bytes.NewReader(stripped))
+	if err != nil {
+		t.Fatalf("stripped GIF can't be decoded: %v", err)
+	}
+	if len(decoded.Image) != 3 {
+		t.Errorf("stripped GIF has %d frames, want 3", len(decoded.Image))
+	}
+	if decoded.LoopCount != 0 {
+		t.Errorf("stripped GIF loop count = %d, want 0 (forever)", decoded.LoopCount)
+	}
+	if decoded.Delay[1] != 10 {
+		t.Errorf("stripped GIF delay = %d, want 10", decoded.Delay[1])
+	}
+}
+
+func TestStripGIFInvalid(t *testing.T) {
+	data := testGIF(t, 1)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"not GIF", []byte("\x89PNG\r\n\x1a\n0000000")},
+		{"truncated header", data[:10]},
+		{"truncated frame", data[:len(data)/2]},
+		{"unknown block", append(append([]byte{}, data[:len(data)-1]...), 0x99)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := stripGIF(test.data); err == nil {
+				t.Error("stripGIF() error = nil, want error")
+			}
+		})
+	}
+}
+
+// testExif builds "Exif\0\0" + TIFF data with orientation, artist and copyright, followed by GPS text
+func testExif(orientation int) []byte {
+	order := binary.LittleEndian
+	artist := append([]byte("Jane Doe"), 0)
+	copyright := append([]byte("(c) Follooow"), 0)
+
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	ifd := make([]byte, 2+3*12+4)
+	order.PutUint16(ifd[0:2], 3)
+	values := 8 + len(ifd)
+
+	order.PutUint16(ifd[2:4], 0x0112)
+	order.PutUint16(ifd[4:6], 3)
+	order.PutUint32(ifd[6:10], 1)
+	order.PutUint16(ifd[10:12], uint16(orientation))
+
+	order.PutUint16(ifd[14:16], 0x013B)
+	order.PutUint16(ifd[16:18], 2)
+	order.PutUint32(ifd[18:22], uint32(len(artist)))
+	order.PutUint32(ifd[22:26], uint32(values))
+
+	order.PutUint16(ifd[26:28], 0x8298)
+	order.PutUint16(ifd[28:30], 2)
+	order.PutUint32(ifd[30:34], uint32(len(copyright)))
+	order.PutUint32(ifd[34:38], uint32(values+len(artist)))
+
+	exif := append([]byte("Exif\x00\x00"), tiff...)
+	exif = append(exif, ifd...)
+	exif = append(exif, artist...)
+	exif = append(exif, copyright...)
+	return append(exif, "GPS 12,34"...)
+}
+
+// testImage is 4x2 image, left half black and right half white
+func testImage() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 2; x < 4; x++ {
+			img.SetGray(x, y, color.Gray{Y: 255})
+		}
+	}
+	return img
+}
+
+// testJPEG encodes testImage with EXIF APP1 segment right after SOI
+func testJPEG(t *testing.T, orientation int) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, testImage(), &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	exif := testExif(orientation)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(exif)))
+	segment = append(segment, exif...)
+
+	data := out.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+// testMetadataPNG encodes testImage with eXIf chunk right after IHDR
+func testMetadataPNG(t *testing.T, orientation int) []byte {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := png.Encode(&out, testImage()); err != nil {
+		t.Fatal(err)
+	}
+
+	data := out.Bytes()
+	chunk := pngChunk("eXIf", testExif(orientation)[6:])
+	return append(append(append([]byte{}, data[:33]...), chunk...), data[33:]...)
+}
+
+// testWebP builds extended WebP of 1x1 lossless image with EXIF and XMP chunks
+func testWebP(t *testing.T) []byte {
+	t.Helper()
+
+	lossless, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunk := func(fourCC string, data []byte) []byte {
+		out := append([]byte(fourCC), 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(out[4:], uint32(len(data)))
+		out = append(out, data...)
+		if len(data)%2 == 1 {
+			out = append(out, 0)
+		}
+		return out
+	}
+
+	// EXIF (0x08) and XMP (0x04) flags, canvas of 1x1 stored as size - 1
+	body := []byte("WEBP")
+	body = append(body, chunk("VP8X", []byte{0x0C, 0, 0, 0, 0, 0, 0, 0, 0, 0})...)
+	body = append(body, lossless[12:]...)
+	body = append(body, chunk("EXIF", testExif(1)[6:])...)
+	body = append(body, chunk("XMP ", []byte("<x:xmpmeta>GPS 56,78</x:xmpmeta>"))...)
+
+	out := append([]byte("RIFF"), 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(body)))
+	return append(out, body...)
+}
+
+func TestStripImageMetadata(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          []byte
+		format        string
+		keepCopyright bool
+		// size of decoded image, 0 when it is not decoded
+		width, height int
+		wantCopyright bool
+	}{
+		{"jpeg", testJPEG(t, 1), "jpeg", false, 4, 2, false},
+		{"jpeg rotated", testJPEG(t, 6), "jpeg", false, 2, 4, false},
+		{"jpeg keep copyright", testJPEG(t, 6), "jpeg", true, 2, 4, true},
+		{"png", testMetadataPNG(t, 1), "png", false, 4, 2, false},
+		{"png rotated keep copyright", testMetadataPNG(t, 8), "png", true, 2, 4, true},
+		{"webp", testWebP(t), "webp", false, 1, 1, false},
+		{"webp keep copyright", testWebP(t), "webp", true, 1, 1, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stripped, err := stripImageMetadata(test.data, test.format, stripOptions{keepCopyright: test.keepCopyright, jpegQuality: 90})
+			if err != nil {
+				t.Fatalf("stripImageMetadata() error = %v", err)
+			}
+			if bytes.Contains(stripped, []byte("GPS")) {
+				t.Error("stripImageMetadata() kept GPS")
+			}
+
+			var tiff []byte
+			switch test.format {
+			case "jpeg":
+				tiff = jpegExif(stripped)
+			case "png":
+				tiff = pngExif(stripped)
+			case "webp":
+				tiff = webpExif(stripped)
+			}
+			tags := parseExif(tiff)
+			if tags.Orientation != 1 {
+				t.Errorf("stripped image orientation = %d, want 1", tags.Orientation)
+			}
+			if got := tags.Artist == "Jane Doe" && tags.Copyright == "(c) Follooow"; got != test.wantCopyright {
+				t.Errorf("stripped image artist = %q copyright = %q, want kept %v", tags.Artist, tags.Copyright, test.wantCopyright)
+			}
+
+			config, _, err := image.DecodeConfig(bytes.NewReader(stripped))
+			if err != nil {
+				t.Fatalf("stripped image can't be decoded: %v", err)
+			}
+			if config.Width != test.width || config.Height != test.height {
+				t.Errorf("stripped image size = %dx%d, want %dx%d", config.Width, config.Height, test.width, test.height)
+			}
+		})
+	}
+}
+
+func TestStripImageMetadataWebPFlags(t *testing.T) {
+	for _, keepCopyright := range []bool{false, true} {
+		stripped, err := stripImageMetadata(testWebP(t), "webp", stripOptions{keepCopyright: keepCopyright})
+		if err != nil {
+			t.Fatalf("stripImageMetadata() error = %v", err)
+		}
+		if size := binary.LittleEndian.Uint32(stripped[4:8]); int(size) != len(stripped)-8 {
+			t.Errorf("RIFF size = %d, want %d", size, len(stripped)-8)
+		}
+
+		// VP8X is the first chunk, its flags are right after the chunk header
+		want := byte(0)
+		if keepCopyright {
+			want = 0x08
+		}
+		if flags := stripped[20]; flags != want {
+			t.Errorf("keepCopyright %v: VP8X flags = %#x, want %#x", keepCopyright, flags, want)
+		}
+	}
+}
+
+func TestStripJPEGQuality(t *testing.T) {
+	// noisy image, so quality changes the size
+	img := image.NewGray(image.Rect(0, 0, 64, 64))
+	for i := range img.Pix {
+		img.Pix[i] = uint8(i * 7919 % 251)
+	}
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	low, err := stripImageMetadata(out.Bytes(), "jpeg", stripOptions{jpegQuality: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	high, err := stripImageMetadata(out.Bytes(), "jpeg", stripOptions{jpegQuality: 95})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(low) >= len(high) {
+		t.Errorf("quality 10 is %d bytes, quality 95 is %d bytes, want smaller", len(low), len(high))
+	}
+}
+
+func TestStripImageMetadataInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		format  string
+		wantErr error
+	}{
+		{"unknown format", []byte("BM"), "bmp", ErrImageType},
+		{"broken jpeg", []byte("\xFF\xD8\xFF\xE0garbage"), "jpeg", ErrImageUnreadable},
+		{"broken png", []byte("\x89PNG\r\n\x1a\ngarbage"), "png", ErrImageUnreadable},
+		{"short webp", []byte("RIFF"), "webp", ErrImageUnreadable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := stripImageMetadata(test.data, test.format, stripOptions{jpegQuality: 90}); !errors.Is(err, test.wantErr) {
+				t.Errorf("stripImageMetadata() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+// webpExif gets TIFF data of WebP EXIF chunk
+func webpExif(data []byte) []byte {
+	for offset := 12; offset+8 <= len(data); {
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		if offset+8+size > len(data) {
+			break
+		}
+		if string(data[offset:offset+4]) == "EXIF" {
+			return data[offset+8 : offset+8+size]
+		}
+		offset += 8 + size + size%2
+	}
+	return nil
+}
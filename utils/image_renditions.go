@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"follooow-be/models"
+	"follooow-be/storage"
+)
+
+// ImageRenditions builds rendition urls of stored image, width and height are 0 when unknown
+// empty when image is not stored on the storage, ex: external url
+func ImageRenditions(url string, width int, height int) models.ImageRenditionsModel {
+	if url == "" {
+		return nil
+	}
+
+	store := storage.Default()
+	renditions := models.ImageRenditionsModel{}
+
+	for _, rendition := range storage.Renditions {
+		renditionURL := store.RenditionURL(url, rendition, "")
+		if renditionURL == "" {
+			return nil
+		}
+
+		w, h := rendition.Size(width, height)
+		renditions[rendition.Name] = models.ImageRenditionModel{
+			Url:    renditionURL,
+			Webp:   store.RenditionURL(url, rendition, "webp"),
+			Avif:   store.RenditionURL(url, rendition, "avif"),
+			Width:  w,
+			Height: h,
+		}
+	}
+
+	return renditions
+}
+
+// VideoRenditions builds rendition urls of stored video from its poster frame
+// empty when video has no poster, ex: FFmpeg is not installed on local storage
+func VideoRenditions(url string, poster string, width int, height int) models.ImageRenditionsModel {
+	if poster == "" {
+		return nil
+	}
+	return ImageRenditions(url, width, height)
+}
+
+// SetGalleryRenditions fills renditions of gallery images, playback urls of videos and influencer avatars
+// renditions of video are resized from its poster
+func SetGalleryRenditions(gallery *models.GalleryModel) {
+	for key := range gallery.Images {
+		image := &gallery.Images[key]
+		if image.Type == "" {
+			image.Type = models.MediaImage
+		}
+
+		if image.Type == models.MediaVideo {
+			image.Renditions = VideoRenditions(image.Url, image.Poster, image.Width, image.Height)
+			image.Playback = VideoPlayback(image.Url)
+			continue
+		}
+		image.Renditions = ImageRenditions(image.Url, image.Width, image.Height)
+	}
+	SetInfluencersRenditions(gallery.InfluencersData)
+}
+
+// VideoPlayback builds playback urls of stored video by format, ex: {"mp4": "...", "webm": "..."}
+// formats the storage can't serve are skipped
+func VideoPlayback(url string) map[string]string {
+	if url == "" {
+		return nil
+	}
+
+	store := storage.Default()
+	playback := map[string]string{}
+	for _, format := range storage.VideoFormats {
+		if videoURL := store.VideoURL(url, format); videoURL != "" {
+			playback[format] = videoURL
+		}
+	}
+
+	if len(playback) < 1 {
+		return nil
+	}
+	return playback
+}
+
+// SetNewsRenditions fills renditions of news thumbnail and influencer avatars
+func SetNewsRenditions(news *models.NewsModel) {
+	news.ThumbnailRenditions = ImageRenditions(news.Thumbnail, 0, 0)
+	SetInfluencersRenditions(news.InfluencersData)
+}
+
+// SetInfluencerRenditions fills renditions of influencer avatar and group member avatars
+func SetInfluencerRenditions(influencer *models.InfluencerModel) {
+	influencer.AvatarRenditions = ImageRenditions(influencer.Avatar, 0, 0)
+	SetInfluencersRenditions(influencer.Members)
+}
+
+// SetInfluencersRenditions fills renditions of influencers avatar
+func SetInfluencersRenditions(influencers []models.InfluencerSmallDataModel) {
+	for key := range influencers {
+		influencers[key].AvatarRenditions = ImageRenditions(influencers[key].Avatar, 0, 0)
+	}
+}
+
+// SetMediaRenditions fills renditions of media library item, renditions of video are resized from its poster
+func SetMediaRenditions(media *models.MediaModel) {
+	if media.Type == models.MediaVideo {
+		media.Renditions = VideoRenditions(media.Url, media.Poster, media.Width, media.Height)
+		media.Playback = VideoPlayback(media.Url)
+		return
+	}
+	media.Renditions = ImageRenditions(media.Url, media.Width, media.Height)
+}
+
+// SetCollectionRenditions fills renditions of collection cover
+func SetCollectionRenditions(collection *models.CollectionModel) {
+	collection.CoverRenditions = ImageRenditions(collection.Cover, 0, 0)
+}
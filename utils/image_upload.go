@@ -3,19 +3,20 @@ package utils
 import (
 	"bytes"
 	"context"
-	"encoding/base64"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"path"
+	"regexp"
 	"strings"
-	"time"
 
 	"follooow-be/configs"
-
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+	"follooow-be/storage"
 )
 
-// UploadImageFromForm uploads an image from form data to Cloudinary
-func UploadImageFromForm(ctx context.Context, fileHeader *multipart.FileHeader, folder string) (*uploader.UploadResult, error) {
+// UploadMediaFromForm uploads an image or a video from form data to the storage
+// type is detected from the file content, each type has its own limits
+func UploadMediaFromForm(ctx context.Context, fileHeader *multipart.FileHeader, folder string) (*storage.Asset, error) {
 	if fileHeader == nil {
 		return nil, fmt.Errorf("no file provided")
 	}
@@ -27,46 +28,83 @@ func UploadImageFromForm(ctx context.Context, fileHeader *multipart.FileHeader,
 	}
 	defer file.Close()
 
+	return UploadMediaFromFile(ctx, file, fileHeader.Size, fileHeader.Filename, folder)
+}
+
+// UploadMediaFromFile uploads an image or a video from opened file, ex: form file or assembled chunked upload
+func UploadMediaFromFile(ctx context.Context, file multipart.File, size int64, originalFilename string, folder string) (*storage.Asset, error) {
+	head := make([]byte, 64)
+	n, _ := io.ReadFull(file, head)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
 	// Generate unique filename
-	filename := generateUniqueFilename(fileHeader.Filename)
+	filename := generateUniqueFilename(originalFilename)
 
 	// Set folder if provided
 	if folder == "" {
 		folder = configs.EnvCloudinaryDir()
 	}
+	opts := storage.UploadOptions{Folder: folder, Filename: filename}
+
+	if SniffVideoFormat(head[:n]) != "" {
+		return uploadVideo(ctx, file, size, opts)
+	}
+	if SniffImageFormat(head[:n]) == "" {
+		return nil, ErrMediaType
+	}
+
+	// reject large file before reading it
+	if err := ValidateImageSize(size); err != nil {
+		return nil, err
+	}
 
-	uploadParams := uploader.UploadParams{
-		Folder:       folder,
-		PublicID:     filename,
-		ResourceType: "image",
+	data, info, err := ReadImage(file)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := configs.CloudinaryClient.Upload.Upload(ctx, file, uploadParams)
+	// remove EXIF and GPS before the image leaves the server
+	data, err = StripImageMetadata(data, info.Format)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload image: %w", err)
+		return nil, err
 	}
 
-	return result, nil
+	return uploadImage(ctx, data, opts)
 }
 
-// UploadImageFromBase64 uploads a base64 encoded image to Cloudinary
-func UploadImageFromBase64(ctx context.Context, base64Data string, folder string, filename string) (*uploader.UploadResult, error) {
-	if base64Data == "" {
-		return nil, fmt.Errorf("no base64 data provided")
+// UploadMediaFromBase64 uploads a base64 encoded image or video to the storage
+func UploadMediaFromBase64(ctx context.Context, base64Data string, folder string, filename string) (*storage.Asset, error) {
+	data, err := storage.DecodeBase64(base64Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if SniffVideoFormat(data) == "" {
+		return UploadImageFromBase64(ctx, base64Data, folder, filename)
 	}
 
-	// Remove data URL prefix if present
-	if strings.HasPrefix(base64Data, "data:") {
-		parts := strings.SplitN(base64Data, ",", 2)
-		if len(parts) == 2 {
-			base64Data = parts[1]
-		}
+	// Set folder if provided
+	if folder == "" {
+		folder = configs.EnvCloudinaryDir()
+	}
+
+	return uploadVideo(ctx, bytes.NewReader(data), int64(len(data)), storage.UploadOptions{Folder: folder, Filename: filename})
+}
+
+// UploadImageFromReader uploads an image read from reader which can't be seeked, ex: entry of ZIP archive
+// the image is read up to max size, so reader with wrong declared size is still limited
+func UploadImageFromReader(ctx context.Context, reader io.Reader, originalFilename string, folder string) (*storage.Asset, error) {
+	data, info, err := ReadImage(reader)
+	if err != nil {
+		return nil, err
 	}
 
-	// Decode base64
-	imageBytes, err := base64.StdEncoding.DecodeString(base64Data)
+	// remove EXIF and GPS before the image leaves the server
+	data, err = StripImageMetadata(data, info.Format)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode base64: %w", err)
+		return nil, err
 	}
 
 	// Set folder if provided
@@ -74,102 +112,134 @@ func UploadImageFromBase64(ctx context.Context, base64Data string, folder string
 		folder = configs.EnvCloudinaryDir()
 	}
 
-	// Create a reader from the byte slice for Cloudinary
-	reader := bytes.NewReader(imageBytes)
+	return uploadImage(ctx, data, storage.UploadOptions{Folder: folder, Filename: generateUniqueFilename(originalFilename)})
+}
 
-	uploadParams := uploader.UploadParams{
-		Folder:       folder,
-		PublicID:     filename,
-		ResourceType: "image",
+// UploadImageFromBase64 uploads a base64 encoded image to the storage
+func UploadImageFromBase64(ctx context.Context, base64Data string, folder string, filename string) (*storage.Asset, error) {
+	imageBytes, err := storage.DecodeBase64(base64Data)
+	if err != nil {
+		return nil, err
 	}
 
-	result, err := configs.CloudinaryClient.Upload.Upload(ctx, reader, uploadParams)
+	if err = ValidateImageSize(int64(len(imageBytes))); err != nil {
+		return nil, err
+	}
+	info, err := ValidateImage(imageBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload base64 image: %w", err)
+		return nil, err
 	}
 
-	return result, nil
-}
-
-// UploadImageFromURL uploads an image from URL to Cloudinary
-func UploadImageFromURL(ctx context.Context, imageURL string, filename string) (*uploader.UploadResult, error) {
-	if imageURL == "" {
-		return nil, fmt.Errorf("no image URL provided")
+	// remove EXIF and GPS before the image leaves the server
+	imageBytes, err = StripImageMetadata(imageBytes, info.Format)
+	if err != nil {
+		return nil, err
 	}
 
-	uploadParams := uploader.UploadParams{
-		Folder:       configs.EnvCloudinaryDir(),
-		PublicID:     filename,
-		ResourceType: "image",
+	// Set folder if provided
+	if folder == "" {
+		folder = configs.EnvCloudinaryDir()
 	}
 
-	result, err := configs.CloudinaryClient.Upload.Upload(ctx, imageURL, uploadParams)
+	return uploadImage(ctx, imageBytes, storage.UploadOptions{Folder: folder, Filename: filename})
+}
+
+// uploadImage uploads validated image and computes its perceptual hash
+func uploadImage(ctx context.Context, data []byte, opts storage.UploadOptions) (*storage.Asset, error) {
+	asset, err := storage.Default().Upload(ctx, bytes.NewReader(data), opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload image from URL: %w", err)
+		return nil, err
 	}
 
-	return result, nil
+	// image without hash is stored, it is only skipped by duplicate detection
+	asset.Hash, err = ImageHash(data)
+	if err != nil {
+		fmt.Printf("Duplicate detection skipped for %s: %v\n", asset.PublicID, err)
+	}
+	return asset, nil
 }
 
-// DeleteImageFromCloudinary deletes an image from Cloudinary
-func DeleteImageFromCloudinary(ctx context.Context, publicID string) (*uploader.DestroyResult, error) {
-	if publicID == "" {
-		return nil, fmt.Errorf("no public ID provided")
+// uploadVideo validates video container then streams the video to the storage
+// dimensions and duration of the container are used when the storage can't read them
+func uploadVideo(ctx context.Context, file io.ReadSeeker, size int64, opts storage.UploadOptions) (*storage.Asset, error) {
+	readerAt, ok := file.(io.ReaderAt)
+	if !ok {
+		return nil, fmt.Errorf("failed to read video: file is not seekable")
 	}
 
-	destroyParams := uploader.DestroyParams{
-		PublicID:     publicID,
-		ResourceType: "image",
+	info, err := ReadVideo(readerAt, size)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to read video: %w", err)
 	}
 
-	result, err := configs.CloudinaryClient.Upload.Destroy(ctx, destroyParams)
+	opts.ResourceType = storage.ResourceVideo
+	asset, err := storage.Default().Upload(ctx, file, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to delete image: %w", err)
+		return nil, err
 	}
 
-	return result, nil
+	asset.ResourceType = storage.ResourceVideo
+	if asset.Width == 0 || asset.Height == 0 {
+		asset.Width, asset.Height = info.Width, info.Height
+	}
+	if asset.Duration == 0 {
+		asset.Duration = info.Duration
+	}
+	if asset.Format == "" {
+		asset.Format = info.Format
+	}
+
+	return asset, nil
 }
 
-// generateUniqueFilename generates a unique filename for upload
-func generateUniqueFilename(originalFilename string) string {
-	// Get file extension
-	parts := strings.Split(originalFilename, ".")
-	extension := ""
-	if len(parts) > 1 {
-		extension = parts[len(parts)-1]
-	}
+// DeleteImage deletes an image from the storage
+func DeleteImage(ctx context.Context, publicID string) error {
+	return storage.Default().Delete(ctx, publicID, storage.ResourceImage)
+}
 
-	// Generate timestamp for uniqueness
-	timestamp := time.Now().Unix()
+// DeleteMedia deletes an image or a video from the storage
+func DeleteMedia(ctx context.Context, publicID string, resourceType string) error {
+	return storage.Default().Delete(ctx, publicID, resourceType)
+}
 
-	// Remove special characters from original filename
-	baseName := strings.ReplaceAll(originalFilename, ".", "_")
-	baseName = strings.ReplaceAll(baseName, " ", "_")
+// GetImageDetail gets detail of stored image, including its dominant colour
+func GetImageDetail(ctx context.Context, publicID string) (*storage.Asset, error) {
+	return storage.Default().Detail(ctx, publicID)
+}
+
+// generateUniqueFilename generates a unique filename for upload, ex: My Photo.JPG -> my-photo_<uuid>.jpg
+// original name is kept readable but only a-z, 0-9, - and _ are kept, so it never adds folders
+func generateUniqueFilename(originalFilename string) string {
+	// Get file extension, folders of client path are dropped
+	name := path.Base(strings.ReplaceAll(originalFilename, "\\", "/"))
+	extension := strings.ToLower(path.Ext(name))
+	baseName := strings.TrimSuffix(name, path.Ext(name))
 
-	// Create unique filename
-	if extension != "" {
-		return fmt.Sprintf("%s_%d.%s", baseName, timestamp, extension)
+	// Remove special characters from original filename
+	baseName = strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(baseName), "-"), "-")
+	if len(baseName) > 40 {
+		baseName = strings.Trim(baseName[:40], "-")
+	}
+	if baseName == "" {
+		baseName = "file"
+	}
+	if !safeExtension.MatchString(extension) {
+		extension = ""
 	}
-	return fmt.Sprintf("%s_%d", baseName, timestamp)
+
+	return UniqueFilename(baseName) + extension
 }
 
-// GetPublicIDFromURL extracts public ID from Cloudinary URL
+var (
+	unsafeFilenameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+	safeExtension       = regexp.MustCompile(`^\.[a-z0-9]{1,5}$`)
+)
+
+// GetPublicIDFromURL extracts public ID from URL of the storage
+// ex: https://res.cloudinary.com/cloud_name/image/upload/v1234567890/folder/image_name.jpg -> folder/image_name
 func GetPublicIDFromURL(imageURL string) string {
-	// Example URL: https://res.cloudinary.com/cloud_name/image/upload/v1234567890/folder/image_name.jpg
-	parts := strings.Split(imageURL, "/")
-
-	// Find folder and image parts
-	for i, part := range parts {
-		if part == "upload" && i+2 < len(parts) {
-			// Skip version part (v1234567890)
-			publicID := strings.Join(parts[i+2:], "/")
-			// Remove file extension for deletion
-			if dotIndex := strings.LastIndex(publicID, "."); dotIndex != -1 {
-				publicID = publicID[:dotIndex]
-			}
-			return publicID
-		}
-	}
-
-	return ""
+	return storage.Default().PublicIDFromURL(imageURL)
 }
@@ -0,0 +1,216 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strconv"
+
+	"follooow-be/configs"
+)
+
+const (
+	defaultImageMaxBytes     = 10 << 20
+	defaultImageMaxDimension = 8192
+	defaultImageMaxPixels    = 40000000
+)
+
+// AVIF dimensions are searched on the first 64KB only
+const avifHeaderSize = 64 << 10
+
+var (
+	ErrImageTooLarge   = errors.New("image is too large")
+	ErrImageDimensions = errors.New("image dimensions are too large")
+	ErrImageType       = errors.New("image type is not allowed, allowed types: jpeg, png, gif, webp, avif")
+	ErrImageUnreadable = errors.New("image header is not readable")
+)
+
+// ImageInfo is image format and dimensions read from its header
+type ImageInfo struct {
+	Format string
+	Width  int
+	Height int
+}
+
+// ImageMaxBytes gets max size of uploaded image, default 10MB
+func ImageMaxBytes() int64 {
+	return envInt64(configs.EnvImageMaxBytes(), defaultImageMaxBytes)
+}
+
+// ValidateImageSize checks size before reading the image
+func ValidateImageSize(size int64) error {
+	if max := ImageMaxBytes(); size > max {
+		return fmt.Errorf("%w: %d bytes, max %d bytes", ErrImageTooLarge, size, max)
+	}
+	return nil
+}
+
+// ReadImage reads image up to max size then validates its type and dimensions
+// dimensions are read from the header, so decompression bomb is rejected before decoded
+func ReadImage(reader io.Reader) ([]byte, ImageInfo, error) {
+	max := ImageMaxBytes()
+
+	data, err := io.ReadAll(io.LimitReader(reader, max+1))
+	if err != nil {
+		return nil, ImageInfo{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	if err = ValidateImageSize(int64(len(data))); err != nil {
+		return nil, ImageInfo{}, err
+	}
+
+	info, err := ValidateImage(data)
+	if err != nil {
+		return nil, info, err
+	}
+
+	return data, info, nil
+}
+
+// ValidateImage checks image type against allowlist and its dimensions against limits
+func ValidateImage(data []byte) (ImageInfo, error) {
+	info := ImageInfo{Format: SniffImageFormat(data)}
+	if info.Format == "" {
+		return info, ErrImageType
+	}
+
+	width, height, err := imageDimensions(info.Format, data)
+	if err != nil {
+		return info, err
+	}
+	info.Width, info.Height = width, height
+
+	maxDimension := int(envInt64(configs.EnvImageMaxDimension(), defaultImageMaxDimension))
+	maxPixels := envInt64(configs.EnvImageMaxPixels(), defaultImageMaxPixels)
+	if width > maxDimension || height > maxDimension || int64(width)*int64(height) > maxPixels {
+		return info, fmt.Errorf("%w: %dx%d, max %dpx per side and %d pixels", ErrImageDimensions, width, height, maxDimension, maxPixels)
+	}
+
+	return info, nil
+}
+
+// SniffImageFormat detects allowed image format from magic bytes, empty when not allowed
+func SniffImageFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif"
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	case isAVIF(data):
+		return "avif"
+	}
+	return ""
+}
+
+// isAVIF checks ftyp box has avif brand, ex: ....ftypavif....mif1miaf
+func isAVIF(data []byte) bool {
+	if len(data) < 16 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+
+	size := int(binary.BigEndian.Uint32(data[0:4]))
+	if size < 16 || size > len(data) {
+		size = len(data)
+	}
+
+	// major brand at 8, minor version at 12, compatible brands from 16
+	for offset := 8; offset+4 <= size; offset += 4 {
+		if offset == 12 {
+			continue
+		}
+		if brand := string(data[offset : offset+4]); brand == "avif" || brand == "avis" {
+			return true
+		}
+	}
+	return false
+}
+
+func imageDimensions(format string, data []byte) (int, int, error) {
+	switch format {
+	case "webp":
+		return webpDimensions(data)
+	case "avif":
+		return avifDimensions(data)
+	default:
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return 0, 0, fmt.Errorf("%w: %v", ErrImageUnreadable, err)
+		}
+		return config.Width, config.Height, nil
+	}
+}
+
+// webpDimensions reads dimensions of lossy (VP8), lossless (VP8L) and extended (VP8X) WebP
+func webpDimensions(data []byte) (int, int, error) {
+	if len(data) < 30 {
+		return 0, 0, ErrImageUnreadable
+	}
+
+	switch string(data[12:16]) {
+	case "VP8 ":
+		// frame tag (3) and start code (3) before 14 bits width and height
+		width := int(binary.LittleEndian.Uint16(data[26:28]) & 0x3FFF)
+		height := int(binary.LittleEndian.Uint16(data[28:30]) & 0x3FFF)
+		return width, height, nil
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		width := int(bits&0x3FFF) + 1
+		height := int((bits>>14)&0x3FFF) + 1
+		return width, height, nil
+	case "VP8X":
+		width := int(uint32(data[24])|uint32(data[25])<<8|uint32(data[26])<<16) + 1
+		height := int(uint32(data[27])|uint32(data[28])<<8|uint32(data[29])<<16) + 1
+		return width, height, nil
+	}
+
+	return 0, 0, ErrImageUnreadable
+}
+
+// avifDimensions reads the largest image spatial extent (ispe) property of AVIF
+func avifDimensions(data []byte) (int, int, error) {
+	header := data
+	if len(header) > avifHeaderSize {
+		header = header[:avifHeaderSize]
+	}
+
+	var width, height uint32
+	for offset := 0; ; {
+		index := bytes.Index(header[offset:], []byte("ispe"))
+		if index < 0 {
+			break
+		}
+		start := offset + index + 4
+		// version and flags (4) before 32 bits width and height
+		if start+12 > len(header) {
+			break
+		}
+		w := binary.BigEndian.Uint32(header[start+4 : start+8])
+		h := binary.BigEndian.Uint32(header[start+8 : start+12])
+		if uint64(w)*uint64(h) > uint64(width)*uint64(height) {
+			width, height = w, h
+		}
+		offset = start
+	}
+
+	if width == 0 || height == 0 {
+		return 0, 0, ErrImageUnreadable
+	}
+	return int(width), int(height), nil
+}
+
+func envInt64(value string, fallback int64) int64 {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed < 1 {
+		return fallback
+	}
+	return parsed
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"time"
+
+	"follooow-be/configs"
+)
+
+const defaultOrphanGracePeriod = 30 * 24 * time.Hour
+
+// OrphanGracePeriod gets age an orphaned file must reach before it is deleted, default 30 days
+func OrphanGracePeriod() time.Duration {
+	return parseUploadDuration(configs.EnvOrphanGCGracePeriod(), defaultOrphanGracePeriod)
+}
+
+// OrphanDryRun reports whether scheduled sweep only reports orphans, true unless ORPHAN_GC_DRY_RUN=false
+func OrphanDryRun() bool {
+	return configs.EnvOrphanGCDryRun() != "false"
+}
+
+// OrphanFolders gets storage folders swept for orphaned files, default upload folder and galleries folder
+func OrphanFolders() []string {
+	value := configs.EnvOrphanGCFolders()
+	if value == "" {
+		value = configs.EnvCloudinaryDir() + ",galleries"
+	}
+	return SplitOrphanFolders(value)
+}
+
+// SplitOrphanFolders splits comma separated folders, ex: "/follooow, galleries" -> ["/follooow", "galleries"]
+// root folder is skipped, so files of other apps on the same storage are never swept
+func SplitOrphanFolders(value string) []string {
+	var folders []string
+	for _, folder := range strings.Split(value, ",") {
+		if folder = strings.TrimSpace(folder); strings.Trim(folder, "/") != "" {
+			folders = append(folders, folder)
+		}
+	}
+	return folders
+}
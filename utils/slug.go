@@ -0,0 +1,14 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// characters not allowed on slug
+var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slugify lowercases text and joins its words with -, ex: "Paris Fashion Week 2024" -> paris-fashion-week-2024
+func Slugify(text string) string {
+	return strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(text), "-"), "-")
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"follooow-be/configs"
+	"follooow-be/models"
+	"net/url"
+	"os"
+	"strings"
+)
+
+var ErrSocialNotFound = errors.New("social account not found")
+
+// stats of social account read from its platform
+type SocialStats struct {
+	Handle     string `json:"handle,omitempty"`
+	Followers  int64  `json:"followers"`
+	IsVerified bool   `json:"is_verified,omitempty"`
+}
+
+// SocialFetcher reads current stats of influencer social account
+// implement this interface to add new platform source
+type SocialFetcher interface {
+	Fetch(ctx context.Context, social models.InfluencerSocial) (*SocialStats, error)
+}
+
+// FileSocialFetcher reads stats from json file, used on local development and tests
+// file format: {"instagram:handle": {"followers": 1200, "is_verified": true}}
+type FileSocialFetcher struct {
+	Path string
+}
+
+func (f FileSocialFetcher) Fetch(ctx context.Context, social models.InfluencerSocial) (*SocialStats, error) {
+	// read file on every fetch, so file can be changed without restart
+	content, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read social file: %w", err)
+	}
+
+	var accounts map[string]SocialStats
+	if err = json.Unmarshal(content, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to parse social file: %w", err)
+	}
+
+	handle := SocialHandle(social)
+	stats, ok := accounts[strings.ToLower(social.Type)+":"+handle]
+	if !ok {
+		return nil, ErrSocialNotFound
+	}
+	if stats.Handle == "" {
+		stats.Handle = handle
+	}
+
+	return &stats, nil
+}
+
+// NewSocialFetcher returns fetcher selected by SOCIAL_FETCHER env
+// return nil when fetcher is not configured
+func NewSocialFetcher() (SocialFetcher, error) {
+	switch configs.EnvSocialFetcher() {
+	case "":
+		return nil, nil
+	case "file":
+		if configs.EnvSocialFetcherFile() == "" {
+			return nil, errors.New("SOCIAL_FETCHER_FILE is required for file fetcher")
+		}
+		return FileSocialFetcher{Path: configs.EnvSocialFetcherFile()}, nil
+	default:
+		return nil, fmt.Errorf("unknown social fetcher %q", configs.EnvSocialFetcher())
+	}
+}
+
+// SocialHandle returns handle of social account
+// fallback to last path of social link, ex: https://instagram.com/lalalalisa_m -> lalalalisa_m
+func SocialHandle(social models.InfluencerSocial) string {
+	if social.Handle != "" {
+		return strings.ToLower(strings.TrimPrefix(social.Handle, "@"))
+	}
+
+	parsed, err := url.Parse(social.Link)
+	if err != nil {
+		return ""
+	}
+
+	parts := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	return strings.ToLower(strings.TrimPrefix(parts[len(parts)-1], "@"))
+}
+
+// KeepSocialStats copies followers stats of existing socials to updated socials of the same type and link
+// stats are only written by the social snapshots job, so stats sent by client are ignored
+func KeepSocialStats(existing []models.InfluencerSocial, socials []models.InfluencerSocial) []models.InfluencerSocial {
+	for key := range socials {
+		social := &socials[key]
+		social.Followers, social.IsVerified, social.FollowersUpdatedOn = 0, false, 0
+		for _, current := range existing {
+			if current.Type != social.Type || current.Link != social.Link {
+				continue
+			}
+			if social.Handle == "" {
+				social.Handle = current.Handle
+			}
+			social.Followers = current.Followers
+			social.IsVerified = current.IsVerified
+			social.FollowersUpdatedOn = current.FollowersUpdatedOn
+			break
+		}
+	}
+	return socials
+}
+
+// SocialGrowths groups snapshots sorted by created_on into growth curve of every social type
+// types keep order of first appearance, growth rate is 0 when first snapshot has no followers
+func SocialGrowths(snapshots []models.SocialSnapshotModel) []models.SocialGrowthModel {
+	var growths []models.SocialGrowthModel
+
+	indexes := map[string]int{}
+	for _, snapshot := range snapshots {
+		index, ok := indexes[snapshot.Type]
+		if !ok {
+			index = len(growths)
+			indexes[snapshot.Type] = index
+			growths = append(growths, models.SocialGrowthModel{Type: snapshot.Type})
+		}
+
+		growths[index].Handle = snapshot.Handle
+		growths[index].Points = append(growths[index].Points, models.SocialGrowthPointModel{
+			CreatedOn: snapshot.CreatedOn,
+			Followers: snapshot.Followers,
+		})
+	}
+
+	for key := range growths {
+		points := growths[key].Points
+		first := points[0].Followers
+		last := points[len(points)-1].Followers
+
+		growths[key].Gained = last - first
+		if first > 0 {
+			growths[key].GrowthRate = float64(last-first) / float64(first)
+		}
+	}
+
+	return growths
+}
@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"follooow-be/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFileSocialFetcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "socials.json")
+	content := `{
+		"instagram:lalalalisa_m": {"followers": 1200, "is_verified": true},
+		"tiktok:lisa": {"handle": "Lisa", "followers": 300}
+	}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		social  models.InfluencerSocial
+		want    *SocialStats
+		wantErr error
+	}{
+		{"handle from link", models.InfluencerSocial{Type: "Instagram", Link: "https://instagram.com/lalalalisa_m/"}, &SocialStats{Handle: "lalalalisa_m", Followers: 1200, IsVerified: true}, nil},
+		{"handle with at", models.InfluencerSocial{Type: "instagram", Handle: "@LALALALISA_M"}, &SocialStats{Handle: "lalalalisa_m", Followers: 1200, IsVerified: true}, nil},
+		{"handle from file", models.InfluencerSocial{Type: "tiktok", Link: "https://tiktok.com/@lisa"}, &SocialStats{Handle: "Lisa", Followers: 300}, nil},
+		{"not found", models.InfluencerSocial{Type: "website", Link: "https://lisa.com"}, nil, ErrSocialNotFound},
+	}
+
+	fetcher := FileSocialFetcher{Path: path}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := fetcher.Fetch(context.Background(), test.social)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Fetch() error = %v, want %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Fetch() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFileSocialFetcherMissingFile(t *testing.T) {
+	fetcher := FileSocialFetcher{Path: filepath.Join(t.TempDir(), "missing.json")}
+	_, err := fetcher.Fetch(context.Background(), models.InfluencerSocial{Type: "instagram", Handle: "lisa"})
+	if err == nil || errors.Is(err, ErrSocialNotFound) {
+		t.Errorf("Fetch() error = %v, want read error", err)
+	}
+}
+
+func TestSocialGrowths(t *testing.T) {
+	snapshots := []models.SocialSnapshotModel{
+		{Type: "instagram", Handle: "lisa", Followers: 1000, CreatedOn: 1},
+		{Type: "tiktok", Handle: "lisa", Followers: 0, CreatedOn: 1},
+		{Type: "instagram", Handle: "lalalalisa_m", Followers: 1500, CreatedOn: 2},
+		{Type: "tiktok", Handle: "lisa", Followers: 40, CreatedOn: 2},
+		{Type: "instagram", Handle: "lalalalisa_m", Followers: 1250, CreatedOn: 3},
+	}
+
+	want := []models.SocialGrowthModel{
+		{
+			Type:       "instagram",
+			Handle:     "lalalalisa_m",
+			Points:     []models.SocialGrowthPointModel{{CreatedOn: 1, Followers: 1000}, {CreatedOn: 2, Followers: 1500}, {CreatedOn: 3, Followers: 1250}},
+			Gained:     250,
+			GrowthRate: 0.25,
+		},
+		{
+			Type:   "tiktok",
+			Handle: "lisa",
+			Points: []models.SocialGrowthPointModel{{CreatedOn: 1, Followers: 0}, {CreatedOn: 2, Followers: 40}},
+			Gained: 40,
+		},
+	}
+
+	if got := SocialGrowths(snapshots); !reflect.DeepEqual(got, want) {
+		t.Errorf("SocialGrowths() = %+v, want %+v", got, want)
+	}
+	if got := SocialGrowths(nil); got != nil {
+		t.Errorf("SocialGrowths(nil) = %+v, want nil", got)
+	}
+}
+
+func TestKeepSocialStats(t *testing.T) {
+	existing := []models.InfluencerSocial{
+		{Type: "instagram", Link: "https://instagram.com/lisa", Handle: "lisa", Followers: 1200, IsVerified: true, FollowersUpdatedOn: 100},
+		{Type: "tiktok", Link: "https://tiktok.com/@lisa", Handle: "lisa", Followers: 300, FollowersUpdatedOn: 100},
+	}
+	socials := []models.InfluencerSocial{
+		// same account, stats sent by client are ignored
+		{Type: "instagram", Link: "https://instagram.com/lisa", Followers: 99999999, IsVerified: false},
+		// link changed, so it is a new account without stats
+		{Type: "tiktok", Link: "https://tiktok.com/@lalisa", Handle: "lalisa", Followers: 5},
+		{Type: "x", Link: "https://x.com/lisa", IsVerified: true, FollowersUpdatedOn: 1},
+	}
+
+	want := []models.InfluencerSocial{
+		{Type: "instagram", Link: "https://instagram.com/lisa", Handle: "lisa", Followers: 1200, IsVerified: true, FollowersUpdatedOn: 100},
+		{Type: "tiktok", Link: "https://tiktok.com/@lalisa", Handle: "lalisa"},
+		{Type: "x", Link: "https://x.com/lisa"},
+	}
+
+	if got := KeepSocialStats(existing, socials); !reflect.DeepEqual(got, want) {
+		t.Errorf("KeepSocialStats() = %+v, want %+v", got, want)
+	}
+}
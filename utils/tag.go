@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// separators ignored when tags are compared, "K-Pop", "kpop" and "k pop" are the same tag
+const tagSeparators = `[\s\-_.]`
+
+var tagSeparatorRegex = regexp.MustCompile(tagSeparators + `+`)
+
+// TagKey gets comparable form of tag, lowercased without separators
+func TagKey(tag string) string {
+	return tagSeparatorRegex.ReplaceAllString(strings.ToLower(strings.TrimSpace(tag)), "")
+}
+
+// TagKeyRegex matches every tag with the key, case insensitive and separators anywhere
+// ex: key kpop matches "K-Pop", "kpop" and "k pop"
+func TagKeyRegex(key string) primitive.Regex {
+	var chars []string
+	for _, char := range key {
+		chars = append(chars, regexp.QuoteMeta(string(char)))
+	}
+
+	separators := tagSeparators + `*`
+	return primitive.Regex{Pattern: "^" + separators + strings.Join(chars, separators) + separators + "$", Options: "i"}
+}
+
+// Hashtag gets telegram hashtag of tag, only letters and digits are kept, ex: "K-Pop" -> #KPop
+func Hashtag(tag string) string {
+	hashtag := strings.Map(func(char rune) rune {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			return char
+		}
+		return -1
+	}, tag)
+
+	if hashtag == "" {
+		return ""
+	}
+	return "#" + hashtag
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"follooow-be/configs"
+)
+
+// token kinds
+const (
+	TokenReader = "reader"
+	TokenUser   = "user"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// GenerateToken generates signed token of subject, ex: reader id
+// kind is used to prevent token of one account type used as another
+func GenerateToken(kind string, subject string, ttl time.Duration) (string, error) {
+	secret := configs.EnvAuthSecret()
+	if secret == "" {
+		return "", fmt.Errorf("AUTH_SECRET is not configured")
+	}
+
+	expiredAt := time.Now().Add(ttl).Unix()
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s|%s|%d", kind, subject, expiredAt)))
+
+	return payload + "." + sign(secret, payload), nil
+}
+
+// ParseToken validates token generated by GenerateToken() and returns its subject
+func ParseToken(kind string, token string) (string, error) {
+	secret := configs.EnvAuthSecret()
+	if secret == "" {
+		return "", fmt.Errorf("AUTH_SECRET is not configured")
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 || !hmac.Equal([]byte(sign(secret, parts[0])), []byte(parts[1])) {
+		return "", ErrInvalidToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+
+	fields := strings.Split(string(raw), "|")
+	if len(fields) != 3 || fields[0] != kind {
+		return "", ErrInvalidToken
+	}
+
+	expiredAt, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil || time.Now().Unix() > expiredAt {
+		return "", ErrInvalidToken
+	}
+
+	return fields[1], nil
+}
+
+func sign(secret string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"follooow-be/configs"
+	"follooow-be/models"
+
+	"github.com/google/uuid"
+)
+
+// namespaces of UploadMedia and chunked upload when UPLOAD_NAMESPACES is empty
+const defaultUploadNamespaces = "news:editor|contributor,galleries:editor,influencers:editor"
+
+// max folders of upload directory including its namespace, ex: news/2024/05
+const maxUploadDirectoryDepth = 4
+
+var (
+	ErrUploadDirectory = errors.New("invalid upload directory")
+	ErrUploadForbidden = errors.New("upload to this directory is not allowed")
+)
+
+var uploadDirectorySegment = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// UploadNamespaces gets roles allowed to upload on each namespace
+// configured as UPLOAD_NAMESPACES=news:editor|contributor,galleries:editor, admin is allowed on every namespace
+func UploadNamespaces() map[string][]string {
+	return parseUploadNamespaces(configs.EnvUploadNamespaces())
+}
+
+func parseUploadNamespaces(value string) map[string][]string {
+	if strings.TrimSpace(value) == "" {
+		value = defaultUploadNamespaces
+	}
+
+	namespaces := map[string][]string{}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 2)
+		name := strings.ToLower(strings.TrimSpace(parts[0]))
+		if !uploadDirectorySegment.MatchString(name) {
+			continue
+		}
+
+		roles := []string{models.UserRoleAdmin}
+		if len(parts) == 2 {
+			for _, role := range strings.Split(parts[1], "|") {
+				if role = strings.ToLower(strings.TrimSpace(role)); role != "" && role != models.UserRoleAdmin {
+					roles = append(roles, role)
+				}
+			}
+		}
+		namespaces[name] = roles
+	}
+
+	return namespaces
+}
+
+// NormalizeUploadDirectory lowercases directory and trims its slashes, ex: /News/2024/ -> news/2024
+// traversal, empty folder and characters other than a-z, 0-9, - and _ are rejected instead of removed
+func NormalizeUploadDirectory(directory string) (string, error) {
+	directory = strings.ToLower(strings.Trim(strings.TrimSpace(directory), "/"))
+	if directory == "" {
+		return "", ErrUploadDirectory
+	}
+
+	segments := strings.Split(directory, "/")
+	if len(segments) > maxUploadDirectoryDepth {
+		return "", fmt.Errorf("%w: max %d folders", ErrUploadDirectory, maxUploadDirectoryDepth)
+	}
+	for _, segment := range segments {
+		if !uploadDirectorySegment.MatchString(segment) {
+			return "", fmt.Errorf("%w: %q", ErrUploadDirectory, segment)
+		}
+	}
+
+	// never differs after segments are checked, kept as the last guard
+	if path.Clean(directory) != directory {
+		return "", ErrUploadDirectory
+	}
+	return directory, nil
+}
+
+// AuthorizeUploadDirectory normalizes directory and checks role is allowed on its namespace
+// namespace is the first folder, ex: news of news/2024
+func AuthorizeUploadDirectory(role string, directory string) (string, error) {
+	return authorizeUploadDirectory(UploadNamespaces(), role, directory)
+}
+
+func authorizeUploadDirectory(namespaces map[string][]string, role string, directory string) (string, error) {
+	directory, err := NormalizeUploadDirectory(directory)
+	if err != nil {
+		return "", err
+	}
+
+	namespace := strings.Split(directory, "/")[0]
+	roles, ok := namespaces[namespace]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown namespace %q", ErrUploadForbidden, namespace)
+	}
+	for _, allowed := range roles {
+		if allowed == role {
+			return directory, nil
+		}
+	}
+	return "", fmt.Errorf("%w: role %s on namespace %q", ErrUploadForbidden, role, namespace)
+}
+
+// UniqueFilename generates filename which never collides, ex: media -> media_9b2c...
+func UniqueFilename(prefix string) string {
+	return prefix + "_" + uuid.NewString()
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"errors"
+	"follooow-be/models"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeUploadDirectory(t *testing.T) {
+	tests := []struct {
+		name      string
+		directory string
+		want      string
+		wantErr   error
+	}{
+		{"namespace", "news", "news", nil},
+		{"slashes and case", " /News/2024/05/ ", "news/2024/05", nil},
+		{"max depth", "news/2024/05/a", "news/2024/05/a", nil},
+		{"too deep", "news/2024/05/a/b", "", ErrUploadDirectory},
+		{"empty", " / ", "", ErrUploadDirectory},
+		{"parent folder", "news/../influencers", "", ErrUploadDirectory},
+		{"leading parent folder", "../news", "", ErrUploadDirectory},
+		{"current folder", "news/./2024", "", ErrUploadDirectory},
+		{"encoded parent folder", "news/%2e%2e/influencers", "", ErrUploadDirectory},
+		{"upper case encoded dot", "%2E%2E", "", ErrUploadDirectory},
+		{"backslash", `news\..\influencers`, "", ErrUploadDirectory},
+		{"empty folder", "news//2024", "", ErrUploadDirectory},
+		{"hidden folder", "news/.cache", "", ErrUploadDirectory},
+		{"space in folder", "news/my folder", "", ErrUploadDirectory},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NormalizeUploadDirectory(test.directory)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("NormalizeUploadDirectory(%q) error = %v, want %v", test.directory, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("NormalizeUploadDirectory(%q) = %q, want %q", test.directory, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseUploadNamespaces(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  map[string][]string
+	}{
+		{"default", " ", map[string][]string{
+			"news":        {models.UserRoleAdmin, models.UserRoleEditor, models.UserRoleContributor},
+			"galleries":   {models.UserRoleAdmin, models.UserRoleEditor},
+			"influencers": {models.UserRoleAdmin, models.UserRoleEditor},
+		}},
+		{"admin only namespace", "Reports", map[string][]string{"reports": {models.UserRoleAdmin}}},
+		{"admin is not repeated", "news:admin|Editor", map[string][]string{"news": {models.UserRoleAdmin, models.UserRoleEditor}}},
+		{"invalid namespace is skipped", "../news:editor,news:contributor", map[string][]string{"news": {models.UserRoleAdmin, models.UserRoleContributor}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := parseUploadNamespaces(test.value); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseUploadNamespaces(%q) = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeUploadDirectory(t *testing.T) {
+	namespaces := parseUploadNamespaces("news:editor|contributor,galleries:editor")
+
+	tests := []struct {
+		name      string
+		role      string
+		directory string
+		want      string
+		wantErr   error
+	}{
+		{"editor on news", models.UserRoleEditor, "News/2024", "news/2024", nil},
+		{"contributor on news", models.UserRoleContributor, "news", "news", nil},
+		{"admin on every namespace", models.UserRoleAdmin, "galleries/lisa", "galleries/lisa", nil},
+		{"wrong role", models.UserRoleContributor, "galleries", "", ErrUploadForbidden},
+		{"no role", "", "news", "", ErrUploadForbidden},
+		{"unknown namespace", models.UserRoleAdmin, "reports/2024", "", ErrUploadForbidden},
+		{"traversal to other namespace", models.UserRoleContributor, "news/../galleries", "", ErrUploadDirectory},
+		{"encoded traversal", models.UserRoleEditor, "news/%2e%2e", "", ErrUploadDirectory},
+		{"backslash", models.UserRoleEditor, `galleries\lisa`, "", ErrUploadDirectory},
+		{"too deep", models.UserRoleEditor, "news/a/b/c/d", "", ErrUploadDirectory},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := authorizeUploadDirectory(namespaces, test.role, test.directory)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("authorizeUploadDirectory(%q, %q) error = %v, want %v", test.role, test.directory, err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("authorizeUploadDirectory(%q, %q) = %q, want %q", test.role, test.directory, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"follooow-be/configs"
+	"follooow-be/storage"
+)
+
+const (
+	defaultUploadConcurrency    = 4
+	defaultUploadBudgetBase     = 10 * time.Second
+	defaultUploadBudgetPerFile  = 15 * time.Second
+	defaultUploadBudgetPerVideo = 60 * time.Second
+)
+
+// UploadFailure is error of single file on batch upload
+type UploadFailure struct {
+	Index    int    `json:"index"`
+	Filename string `json:"filename"`
+	Error    string `json:"error"`
+	err      error
+}
+
+// UploadBatchError is returned when at least one file of the batch failed
+// uploaded files of the batch are already deleted when this error returned
+type UploadBatchError struct {
+	Failures []UploadFailure
+}
+
+func (e *UploadBatchError) Error() string {
+	var messages []string
+	for _, failure := range e.Failures {
+		messages = append(messages, failure.Filename+": "+failure.Error)
+	}
+	return fmt.Sprintf("failed to upload %d files: %s", len(e.Failures), strings.Join(messages, "; "))
+}
+
+// Is reports whether any failed file has target error, ex: errors.Is(err, ErrImageTooLarge)
+func (e *UploadBatchError) Is(target error) bool {
+	for _, failure := range e.Failures {
+		if errors.Is(failure.err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// UploadConcurrency gets max concurrent uploads of a request, default 4
+func UploadConcurrency() int {
+	concurrency, err := strconv.Atoi(configs.EnvUploadConcurrency())
+	if err != nil || concurrency < 1 {
+		return defaultUploadConcurrency
+	}
+	return concurrency
+}
+
+// UploadBudget gets time budget to upload files, videos get extra time on top of per file time
+// ex: 20 images, concurrency 4, base 10s, per file 15s -> 10s + 5 * 15s = 85s
+// ex: 2 images and 1 video, per video 60s -> 10s + 1 * 15s + 1 * 60s = 85s
+func UploadBudget(files []*multipart.FileHeader) time.Duration {
+	videos := 0
+	for _, file := range files {
+		if isVideoFile(file) {
+			videos++
+		}
+	}
+
+	return UploadBudgetOf(len(files), videos)
+}
+
+// UploadBudgetOf gets time budget to upload total files, videos is number of videos among them
+func UploadBudgetOf(total int, videos int) time.Duration {
+	base := parseUploadDuration(configs.EnvUploadBudgetBase(), defaultUploadBudgetBase)
+	perFile := parseUploadDuration(configs.EnvUploadBudgetPerFile(), defaultUploadBudgetPerFile)
+	perVideo := parseUploadDuration(configs.EnvUploadBudgetPerVideo(), defaultUploadBudgetPerVideo)
+
+	concurrency := UploadConcurrency()
+	rounds := (total + concurrency - 1) / concurrency
+	videoRounds := (videos + concurrency - 1) / concurrency
+
+	return base + time.Duration(rounds)*perFile + time.Duration(videoRounds)*perVideo
+}
+
+// UploadMediaFilesFromForm uploads images and videos concurrently, results have the same order as files
+// when any file failed, remaining uploads are cancelled and uploaded files are deleted
+func UploadMediaFilesFromForm(ctx context.Context, files []*multipart.FileHeader, folder string) ([]*storage.Asset, error) {
+	results := make([]*storage.Asset, len(files))
+	errs := make([]error, len(files))
+
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sem := make(chan struct{}, UploadConcurrency())
+	var wg sync.WaitGroup
+
+	for i := range files {
+		sem <- struct{}{}
+
+		// skip remaining files when other file already failed
+		if batchCtx.Err() != nil {
+			<-sem
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := UploadMediaFromForm(batchCtx, files[i], folder)
+			if err != nil {
+				errs[i] = err
+				cancel()
+				return
+			}
+			results[i] = result
+		}(i)
+	}
+
+	wg.Wait()
+
+	// budget exceeded, every pending upload fails with the same error
+	if ctx.Err() != nil {
+		DeleteUploadedMedia(results)
+		return nil, fmt.Errorf("upload budget exceeded: %w", ctx.Err())
+	}
+
+	var failures []UploadFailure
+	for i, err := range errs {
+		// uploads cancelled because of other failure are not reported
+		if err == nil || isCancelled(err) {
+			continue
+		}
+		failures = append(failures, UploadFailure{Index: i, Filename: files[i].Filename, Error: err.Error(), err: err})
+	}
+
+	if batchCtx.Err() == nil {
+		return results, nil
+	}
+
+	DeleteUploadedMedia(results)
+	return nil, &UploadBatchError{Failures: failures}
+}
+
+// DeleteUploadedMedia deletes uploaded images and videos on rollback
+// it has its own timeout since the upload context may already be expired
+func DeleteUploadedMedia(results []*storage.Asset) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, result := range results {
+		if result == nil || result.PublicID == "" {
+			continue
+		}
+
+		wg.Add(1)
+		go func(publicID string, resourceType string) {
+			defer wg.Done()
+			if err := DeleteMedia(ctx, publicID, resourceType); err != nil {
+				fmt.Printf("Failed to rollback upload %s: %v\n", publicID, err)
+			}
+		}(result.PublicID, result.ResourceType)
+	}
+	wg.Wait()
+}
+
+// isVideoFile guesses type of form file by its content type or extension, content is checked on upload
+func isVideoFile(file *multipart.FileHeader) bool {
+	if strings.HasPrefix(file.Header.Get("Content-Type"), "video/") {
+		return true
+	}
+
+	switch strings.ToLower(path.Ext(file.Filename)) {
+	case ".mp4", ".m4v", ".mov", ".webm":
+		return true
+	}
+	return false
+}
+
+func isCancelled(err error) bool {
+	return errors.Is(err, context.Canceled) || strings.Contains(err.Error(), context.Canceled.Error())
+}
+
+func parseUploadDuration(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		return fallback
+	}
+	return parsed
+}
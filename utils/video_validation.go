@@ -0,0 +1,422 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"follooow-be/configs"
+)
+
+const (
+	defaultVideoMaxBytes    = 100 << 20
+	defaultVideoMaxDuration = 180
+)
+
+// WebM header elements are searched on the first 1MB only
+const webmHeaderSize = 1 << 20
+
+// max boxes read on one level of MP4, a valid file has a few of them
+const mp4MaxBoxes = 1024
+
+var (
+	ErrVideoTooLarge   = errors.New("video is too large")
+	ErrVideoDuration   = errors.New("video is too long")
+	ErrVideoUnreadable = errors.New("video header is not readable")
+	ErrMediaType       = errors.New("file type is not allowed, allowed types: jpeg, png, gif, webp, avif, mp4, mov, webm")
+)
+
+// VideoInfo is video format, dimensions and duration in seconds read from its container
+type VideoInfo struct {
+	Format   string
+	Width    int
+	Height   int
+	Duration float64
+}
+
+// VideoMaxBytes gets max size of uploaded video, default 100MB
+func VideoMaxBytes() int64 {
+	return envInt64(configs.EnvVideoMaxBytes(), defaultVideoMaxBytes)
+}
+
+// VideoMaxDuration gets max duration of uploaded video in seconds, default 3 minutes
+func VideoMaxDuration() int64 {
+	return envInt64(configs.EnvVideoMaxDuration(), defaultVideoMaxDuration)
+}
+
+// ValidateVideoSize checks size before reading the video
+func ValidateVideoSize(size int64) error {
+	if max := VideoMaxBytes(); size > max {
+		return fmt.Errorf("%w: %d bytes, max %d bytes", ErrVideoTooLarge, size, max)
+	}
+	return nil
+}
+
+// ReadVideo validates type, size and duration of video, only its container headers are read
+func ReadVideo(file io.ReaderAt, size int64) (VideoInfo, error) {
+	if err := ValidateVideoSize(size); err != nil {
+		return VideoInfo{}, err
+	}
+
+	head := make([]byte, 64)
+	n, _ := file.ReadAt(head, 0)
+
+	info := VideoInfo{Format: SniffVideoFormat(head[:n])}
+	var err error
+	switch info.Format {
+	case "mp4", "mov":
+		err = mp4Info(file, size, &info)
+	case "webm":
+		err = webmInfo(file, size, &info)
+	default:
+		return info, ErrMediaType
+	}
+	if err != nil {
+		return info, err
+	}
+
+	if max := VideoMaxDuration(); info.Duration > float64(max) {
+		return info, fmt.Errorf("%w: %.1f seconds, max %d seconds", ErrVideoDuration, info.Duration, max)
+	}
+
+	return info, nil
+}
+
+// SniffVideoFormat detects allowed video format from magic bytes, empty when not allowed
+func SniffVideoFormat(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		// EBML header, matroska files other than webm are not allowed
+		if bytes.Contains(data, []byte("webm")) {
+			return "webm"
+		}
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && !isAVIF(data):
+		if string(data[8:12]) == "qt  " {
+			return "mov"
+		}
+
+		size := int(binary.BigEndian.Uint32(data[0:4]))
+		if size < 16 || size > len(data) {
+			size = len(data)
+		}
+		for offset := 8; offset+4 <= size; offset += 4 {
+			switch string(data[offset : offset+4]) {
+			case "isom", "iso2", "iso4", "iso5", "iso6", "mp41", "mp42", "avc1", "M4V ", "dash":
+				return "mp4"
+			}
+		}
+	}
+	return ""
+}
+
+// mp4Box is box of ISO base media file, offset and size are of its content
+type mp4Box struct {
+	Type   string
+	Offset int64
+	Size   int64
+}
+
+// mp4Info reads duration of movie header and dimensions of the first video track
+// ex: moov > mvhd, moov > trak > tkhd
+func mp4Info(file io.ReaderAt, size int64, info *VideoInfo) error {
+	boxes, err := readMP4Boxes(file, 0, size)
+	if err != nil {
+		return err
+	}
+	moov, ok := findMP4Box(boxes, "moov")
+	if !ok {
+		return ErrVideoUnreadable
+	}
+
+	boxes, err = readMP4Boxes(file, moov.Offset, moov.Offset+moov.Size)
+	if err != nil {
+		return err
+	}
+
+	mvhd, ok := findMP4Box(boxes, "mvhd")
+	if !ok {
+		return ErrVideoUnreadable
+	}
+	header := make([]byte, 32)
+	n, _ := file.ReadAt(header, mvhd.Offset)
+
+	// version 1 has 64 bit times
+	var timescale, duration uint64
+	if n < 20 || header[0] == 1 && n < 32 {
+		return ErrVideoUnreadable
+	}
+	if header[0] == 1 {
+		timescale = uint64(binary.BigEndian.Uint32(header[20:24]))
+		duration = binary.BigEndian.Uint64(header[24:32])
+	} else {
+		timescale = uint64(binary.BigEndian.Uint32(header[12:16]))
+		duration = uint64(binary.BigEndian.Uint32(header[16:20]))
+	}
+	if timescale == 0 {
+		return ErrVideoUnreadable
+	}
+	info.Duration = float64(duration) / float64(timescale)
+
+	for _, trak := range boxes {
+		if trak.Type != "trak" {
+			continue
+		}
+
+		children, err := readMP4Boxes(file, trak.Offset, trak.Offset+trak.Size)
+		if err != nil {
+			return err
+		}
+		tkhd, ok := findMP4Box(children, "tkhd")
+		if !ok {
+			continue
+		}
+
+		width, height, err := mp4TrackDimensions(file, tkhd)
+		if err != nil {
+			return err
+		}
+		// audio tracks have no dimensions
+		if width > 0 && height > 0 {
+			info.Width, info.Height = width, height
+			break
+		}
+	}
+
+	return nil
+}
+
+// mp4TrackDimensions reads display size of track header, rotated track has swapped size
+func mp4TrackDimensions(file io.ReaderAt, tkhd mp4Box) (int, int, error) {
+	header := make([]byte, 96)
+	n, _ := file.ReadAt(header, tkhd.Offset)
+
+	// version 1 has 64 bit times, matrix is followed by fixed 16.16 width and height
+	matrix := 40
+	if header[0] == 1 {
+		matrix = 52
+	}
+	if n < matrix+44 {
+		return 0, 0, ErrVideoUnreadable
+	}
+
+	width := int(binary.BigEndian.Uint32(header[matrix+36:matrix+40]) >> 16)
+	height := int(binary.BigEndian.Uint32(header[matrix+40:matrix+44]) >> 16)
+
+	// matrix a is 0 when the video is rotated by 90 or 270 degrees, ex: portrait video of phones
+	if binary.BigEndian.Uint32(header[matrix:matrix+4]) == 0 {
+		width, height = height, width
+	}
+
+	return width, height, nil
+}
+
+func readMP4Boxes(file io.ReaderAt, offset int64, end int64) ([]mp4Box, error) {
+	var boxes []mp4Box
+	header := make([]byte, 16)
+
+	for offset+8 <= end && len(boxes) < mp4MaxBoxes {
+		if _, err := file.ReadAt(header[:8], offset); err != nil {
+			return boxes, ErrVideoUnreadable
+		}
+
+		size := int64(binary.BigEndian.Uint32(header[0:4]))
+		headerSize := int64(8)
+		switch size {
+		case 0:
+			// box continues to the end of file
+			size = end - offset
+		case 1:
+			// 64 bit size follows the type
+			if _, err := file.ReadAt(header[8:16], offset+8); err != nil {
+				return boxes, ErrVideoUnreadable
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:16]))
+			headerSize = 16
+		}
+		if size < headerSize || size > end-offset {
+			return boxes, ErrVideoUnreadable
+		}
+
+		boxes = append(boxes, mp4Box{Type: string(header[4:8]), Offset: offset + headerSize, Size: size - headerSize})
+		offset += size
+	}
+
+	return boxes, nil
+}
+
+func findMP4Box(boxes []mp4Box, boxType string) (mp4Box, bool) {
+	for _, box := range boxes {
+		if box.Type == boxType {
+			return box, true
+		}
+	}
+	return mp4Box{}, false
+}
+
+// EBML ids of WebM elements
+const (
+	ebmlSegment       = 0x18538067
+	ebmlInfo          = 0x1549A966
+	ebmlTimecodeScale = 0x2AD7B1
+	ebmlDuration      = 0x4489
+	ebmlTracks        = 0x1654AE6B
+	ebmlTrackEntry    = 0xAE
+	ebmlVideo         = 0xE0
+	ebmlPixelWidth    = 0xB0
+	ebmlPixelHeight   = 0xBA
+)
+
+// ebmlElement is element of WebM, data is cut at the end of read header
+type ebmlElement struct {
+	ID   uint64
+	Data []byte
+}
+
+// webmInfo reads duration of segment info and dimensions of the first video track
+// ex: Segment > Info > Duration, Segment > Tracks > TrackEntry > Video > PixelWidth
+func webmInfo(file io.ReaderAt, size int64, info *VideoInfo) error {
+	if size > webmHeaderSize {
+		size = webmHeaderSize
+	}
+	data := make([]byte, size)
+	n, err := file.ReadAt(data, 0)
+	if err != nil && err != io.EOF {
+		return ErrVideoUnreadable
+	}
+
+	segment, ok := findEBMLElement(readEBMLElements(data[:n]), ebmlSegment)
+	if !ok {
+		return ErrVideoUnreadable
+	}
+	children := readEBMLElements(segment.Data)
+
+	segmentInfo, ok := findEBMLElement(children, ebmlInfo)
+	if !ok {
+		return ErrVideoUnreadable
+	}
+
+	// duration is float on timecode scale, default scale is 1ms
+	scale := uint64(1000000)
+	var duration float64
+	for _, element := range readEBMLElements(segmentInfo.Data) {
+		switch element.ID {
+		case ebmlTimecodeScale:
+			scale = ebmlUint(element.Data)
+		case ebmlDuration:
+			duration = ebmlFloat(element.Data)
+		}
+	}
+	info.Duration = duration * float64(scale) / 1e9
+
+	tracks, ok := findEBMLElement(children, ebmlTracks)
+	if !ok {
+		return nil
+	}
+	for _, entry := range readEBMLElements(tracks.Data) {
+		if entry.ID != ebmlTrackEntry {
+			continue
+		}
+
+		video, ok := findEBMLElement(readEBMLElements(entry.Data), ebmlVideo)
+		if !ok {
+			continue
+		}
+		for _, element := range readEBMLElements(video.Data) {
+			switch element.ID {
+			case ebmlPixelWidth:
+				info.Width = int(ebmlUint(element.Data))
+			case ebmlPixelHeight:
+				info.Height = int(ebmlUint(element.Data))
+			}
+		}
+		break
+	}
+
+	return nil
+}
+
+// readEBMLElements reads sibling elements of data, element of unknown size continues to the end of data
+func readEBMLElements(data []byte) []ebmlElement {
+	var elements []ebmlElement
+
+	for offset := 0; offset < len(data); {
+		id, idLength, ok := readEBMLVint(data[offset:], true)
+		if !ok {
+			break
+		}
+		size, sizeLength, ok := readEBMLVint(data[offset+idLength:], false)
+		if !ok {
+			break
+		}
+
+		start := offset + idLength + sizeLength
+		end := len(data)
+		if size < uint64(end-start) {
+			end = start + int(size)
+		}
+
+		elements = append(elements, ebmlElement{ID: id, Data: data[start:end]})
+		offset = end
+	}
+
+	return elements
+}
+
+// readEBMLVint reads variable size integer, length is number of leading zero bits + 1
+// ids keep the length marker bit, sizes don't, size with all bits set is unknown size
+func readEBMLVint(data []byte, keepMarker bool) (uint64, int, bool) {
+	if len(data) < 1 || data[0] == 0 {
+		return 0, 0, false
+	}
+
+	length := 1
+	for mask := byte(0x80); data[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if len(data) < length {
+		return 0, 0, false
+	}
+
+	value := uint64(data[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[i])
+	}
+	if !keepMarker && value == 1<<(7*uint(length))-1 {
+		return math.MaxUint64, length, true
+	}
+
+	return value, length, true
+}
+
+func findEBMLElement(elements []ebmlElement, id uint64) (ebmlElement, bool) {
+	for _, element := range elements {
+		if element.ID == id {
+			return element, true
+		}
+	}
+	return ebmlElement{}, false
+}
+
+func ebmlUint(data []byte) uint64 {
+	var value uint64
+	for _, b := range data {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+func ebmlFloat(data []byte) float64 {
+	switch len(data) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(data)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	}
+	return 0
+}